@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"db_forum/app/repositories/cache"
+	"db_forum/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	metrics *cache.Metrics
+}
+
+func MakeCacheHandler(metrics_ *cache.Metrics) *CacheHandler {
+	return &CacheHandler{metrics: metrics_}
+}
+
+// GetStats reports the hits and misses of the forum, thread and user cache.
+func (cacheHandler *CacheHandler) GetStats(c *gin.Context) {
+	statsJSON, err := cacheHandler.metrics.Stats().MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", statsJSON)
+}
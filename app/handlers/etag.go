@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"db_forum/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified sets the ETag of the response and reports whether the client
+// already holds this representation, in which case 304 is written.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header(pkg.ETagHeader, etag)
+	if pkg.MatchesIfNoneMatch(c.GetHeader(pkg.IfNoneMatchHeader), etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -4,8 +4,10 @@ import (
 	"db_forum/app/models"
 	"db_forum/app/usecases"
 	"db_forum/pkg"
+	"db_forum/pkg/validation"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/mailru/easyjson"
 
@@ -28,6 +30,12 @@ func (forumHandler *ForumHandler) CreateForum(c *gin.Context) {
 		return
 	}
 
+	err = validation.Struct(&forum)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
 	err = forumHandler.forumUsecase.CreateForum(&forum)
 	if err != nil && pkg.ConvertErrorToCode(err) != http.StatusConflict {
 		c.Data(pkg.CreateErrorResponse(err))
@@ -61,6 +69,9 @@ func (forumHandler *ForumHandler) GetForum(c *gin.Context) {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	if notModified(c, pkg.CreateETag(forum.Version)) {
+		return
+	}
 
 	forumJSON, err := forum.MarshalJSON()
 	if err != nil {
@@ -82,6 +93,12 @@ func (forumHandler *ForumHandler) CreateThread(c *gin.Context) {
 	}
 	thread.Forum = slug
 
+	err = validation.Struct(&thread)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
 	err = forumHandler.forumUsecase.CreateForumsThread(&thread)
 	if err != nil && pkg.ConvertErrorToCode(err) != http.StatusConflict {
 		c.Data(pkg.CreateErrorResponse(err))
@@ -121,6 +138,10 @@ func (forumHandler *ForumHandler) GetForumUsers(c *gin.Context) {
 			return
 		}
 	}
+	if err := validation.Var("limit", defaultLimit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
 	rawDecs := c.Query("desc")
 	defaultDesc := false
 
@@ -133,7 +154,17 @@ func (forumHandler *ForumHandler) GetForumUsers(c *gin.Context) {
 		}
 	}
 
-	users, err := forumHandler.forumUsecase.GetForumUsers(slug, defaultLimit, since, defaultDesc)
+	sort := c.Query("sort")
+	switch sort {
+	case "":
+		sort = "nickname"
+	case "nickname", "activity", "posts", "joined":
+	default:
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	users, err := forumHandler.forumUsecase.GetForumUsers(slug, sort, defaultLimit, since, defaultDesc)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
@@ -148,6 +179,78 @@ func (forumHandler *ForumHandler) GetForumUsers(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json; charset=utf-8", usersJSON)
 }
 
+func (forumHandler *ForumHandler) GetForumLeaderboard(c *gin.Context) {
+	slug := c.Param("slug")
+
+	limit := 100
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	leaderboard, err := forumHandler.forumUsecase.GetForumLeaderboard(slug, limit)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	leaderboardJSON, err := leaderboard.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", leaderboardJSON)
+}
+
+func (forumHandler *ForumHandler) GetForumStats(c *gin.Context) {
+	slug := c.Param("slug")
+
+	var from, to time.Time
+	if rawFrom := c.Query("from"); rawFrom != "" {
+		var err error
+		from, err = time.Parse(time.RFC3339Nano, rawFrom)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if rawTo := c.Query("to"); rawTo != "" {
+		var err error
+		to, err = time.Parse(time.RFC3339Nano, rawTo)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	bucket := c.Query("bucket")
+	if bucket == "" {
+		bucket = "day"
+	}
+
+	stats, err := forumHandler.forumUsecase.GetForumStats(slug, bucket, from, to)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	statsJSON, err := stats.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", statsJSON)
+}
+
 func (forumHandler *ForumHandler) GetForumThreads(c *gin.Context) {
 	slug := c.Param("slug")
 
@@ -161,6 +264,10 @@ func (forumHandler *ForumHandler) GetForumThreads(c *gin.Context) {
 			return
 		}
 	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
 	since := c.Query("since")
 	descStr := c.Query("desc")
 	desc := false
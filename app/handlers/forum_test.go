@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/usecases"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeForumUsecase records the order the users were asked in.
+type fakeForumUsecase struct {
+	usecases.ForumUsecase
+	sort string
+}
+
+func (forumUsecase *fakeForumUsecase) GetForumUsers(slug, sort string, limit int, since string, desc bool) (*models.ForumUsers, error) {
+	forumUsecase.sort = sort
+	return &models.ForumUsers{}, nil
+}
+
+func TestForumUsersSort(t *testing.T) {
+	tests := []struct {
+		query  string
+		status int
+		sort   string
+	}{
+		{"", http.StatusOK, "nickname"},
+		{"?sort=nickname", http.StatusOK, "nickname"},
+		{"?sort=activity", http.StatusOK, "activity"},
+		{"?sort=posts", http.StatusOK, "posts"},
+		{"?sort=joined", http.StatusOK, "joined"},
+		{"?sort=threads", http.StatusBadRequest, ""},
+		{"?sort=Nickname", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		forumUsecase := &fakeForumUsecase{}
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/forum/:slug/users", MakeForumHandler(forumUsecase).GetForumUsers)
+
+		response := serve(router, http.MethodGet, "/forum/forum/users"+test.query, "", nil)
+		if response.Code != test.status {
+			t.Errorf("%q: status = %d, want %d: %s", test.query, response.Code, test.status, response.Body)
+		}
+		if forumUsecase.sort != test.sort {
+			t.Errorf("%q: sort = %q, want %q", test.query, forumUsecase.sort, test.sort)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"db_forum/app/models"
 	"db_forum/app/usecases"
 	"db_forum/pkg"
+	"db_forum/pkg/validation"
 	"github.com/mailru/easyjson"
 	"net/http"
 	"strconv"
@@ -31,6 +32,20 @@ func (postHandler *PostHandler) GetPost(c *gin.Context) {
 		return
 	}
 
+	versions := []int64{postFull.Post.Version}
+	if postFull.Author != nil {
+		versions = append(versions, postFull.Author.Version)
+	}
+	if postFull.Thread != nil {
+		versions = append(versions, postFull.Thread.Version)
+	}
+	if postFull.Forum != nil {
+		versions = append(versions, postFull.Forum.Version)
+	}
+	if notModified(c, pkg.CreateETag(versions...)) {
+		return
+	}
+
 	postFullJSON, err := postFull.MarshalJSON()
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
@@ -55,12 +70,55 @@ func (postHandler *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	post := &models.Post{Id: int64(id), Message: postUpdate.Message}
+	version, err := pkg.ParseIfMatch(c.GetHeader(pkg.IfMatchHeader))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	post := &models.Post{Id: int64(id), Message: postUpdate.Message, Version: version}
 	err = postHandler.postUsecase.UpdatePost(post)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(post.Version))
+
+	postJSON, err := post.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", postJSON)
+}
+
+func (postHandler *PostHandler) Vote(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	var vote models.Vote
+	err = easyjson.UnmarshalFromReader(c.Request.Body, &vote)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	err = validation.Struct(&vote)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	post, err := postHandler.postUsecase.VoteForPost(id, &vote)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(post.Version))
 
 	postJSON, err := post.MarshalJSON()
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"db_forum/app/usecases"
 	"db_forum/pkg"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -41,3 +42,27 @@ func (serviceHandler *ServiceHandler) GetStatus(c *gin.Context) {
 
 	c.Data(http.StatusOK, "application/json; charset=utf-8", statusJSON)
 }
+
+// Reconcile reports the counters that disagree with the rows they count and
+// repairs them with ?repair=true.
+func (serviceHandler *ServiceHandler) Reconcile(c *gin.Context) {
+	repair, err := strconv.ParseBool(c.DefaultQuery("repair", "false"))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	reconciliation, err := serviceHandler.serviceUsecase.Reconcile(repair)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	reconciliationJSON, err := reconciliation.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", reconciliationJSON)
+}
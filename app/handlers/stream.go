@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/stream"
+	"db_forum/app/usecases"
+	"db_forum/pkg"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	LastEventIdHeader = "Last-Event-ID"
+
+	backfillPageSize  = 100
+	heartbeatInterval = 15 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+type StreamHandler struct {
+	threadUsecase usecases.ThreadUsecase
+	hub           *stream.Hub
+	upgrader      websocket.Upgrader
+}
+
+func MakeStreamHandler(threadUsecase_ usecases.ThreadUsecase, hub_ *stream.Hub) *StreamHandler {
+	return &StreamHandler{threadUsecase: threadUsecase_, hub: hub_}
+}
+
+// ThreadEvents streams the events of a thread as Server-Sent Events.
+func (streamHandler *StreamHandler) ThreadEvents(c *gin.Context) {
+	thread, since, err := streamHandler.prepare(c)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	send := func(message stream.Message) error {
+		if message.Id != 0 {
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\n", message.Id); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", message.Event, message.Data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+	ping := func() error {
+		if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	streamHandler.serve(thread, since, send, ping, c.Request.Context().Done())
+}
+
+// ThreadWebSocket streams the same events as ThreadEvents over a WebSocket,
+// browsers can't set headers there so lastEventId is also read from the query.
+func (streamHandler *StreamHandler) ThreadWebSocket(c *gin.Context) {
+	thread, since, err := streamHandler.prepare(c)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	conn, err := streamHandler.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(message stream.Message) error {
+		messageJSON, err := models.StreamMessage{Event: message.Event, Id: message.Id, Data: message.Data}.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteMessage(websocket.TextMessage, messageJSON)
+	}
+	ping := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	}
+
+	streamHandler.serve(thread, since, send, ping, closed)
+}
+
+func (streamHandler *StreamHandler) prepare(c *gin.Context) (*models.Thread, int, error) {
+	thread, err := streamHandler.threadUsecase.GetInfoAboutThread(c.Param("slug_or_id"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	since := -1
+	rawSince := c.GetHeader(LastEventIdHeader)
+	if rawSince == "" {
+		rawSince = c.Query("lastEventId")
+	}
+	if rawSince != "" {
+		since, err = strconv.Atoi(rawSince)
+		if err != nil || since < 0 {
+			return nil, 0, pkg.ErrBadRequest
+		}
+	}
+	return thread, since, nil
+}
+
+// serve subscribes before replaying the posts missed since Last-Event-ID, so
+// nothing created in between is lost; live duplicates of replayed posts are skipped.
+func (streamHandler *StreamHandler) serve(thread *models.Thread, since int, send func(stream.Message) error, ping func() error, done <-chan struct{}) {
+	subscription := streamHandler.hub.Subscribe(thread.Id)
+	defer streamHandler.hub.Unsubscribe(subscription)
+
+	replayedId := int64(since)
+	if since >= 0 {
+		for {
+			posts, err := streamHandler.threadUsecase.GetThreadPosts(strconv.FormatInt(thread.Id, 10), backfillPageSize, int(replayedId), "flat", false)
+			if err != nil {
+				return
+			}
+			for i := range *posts {
+				message, err := stream.MakePostMessage(stream.EventPost, &(*posts)[i])
+				if err != nil {
+					return
+				}
+				if err = send(message); err != nil {
+					return
+				}
+				replayedId = message.Id
+			}
+			if len(*posts) < backfillPageSize {
+				break
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-subscription.Messages:
+			if !ok {
+				return
+			}
+			if message.Id != 0 && message.Id <= replayedId {
+				continue
+			}
+			if err := send(message); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := ping(); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"db_forum/app/models"
 	"db_forum/app/usecases"
 	"db_forum/pkg"
+	"db_forum/pkg/validation"
 	"net/http"
 	"strconv"
 
@@ -29,6 +30,12 @@ func (threadHandler *ThreadHandler) CreatePosts(c *gin.Context) {
 		return
 	}
 
+	err = validation.Slice(posts)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
 	err = threadHandler.threadUsecase.CreateNewPosts(rawId, &posts)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
@@ -52,6 +59,9 @@ func (threadHandler *ThreadHandler) GetThread(c *gin.Context) {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	if notModified(c, pkg.CreateETag(thread.Version)) {
+		return
+	}
 
 	threadJSON, err := thread.MarshalJSON()
 	if err != nil {
@@ -72,12 +82,19 @@ func (threadHandler *ThreadHandler) UpdateThread(c *gin.Context) {
 		return
 	}
 
-	thread := &models.Thread{Title: threadUpdate.Title, Message: threadUpdate.Message}
+	version, err := pkg.ParseIfMatch(c.GetHeader(pkg.IfMatchHeader))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	thread := &models.Thread{Title: threadUpdate.Title, Message: threadUpdate.Message, Version: version}
 	err = threadHandler.threadUsecase.UpdateThread(rawId, thread)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(thread.Version))
 
 	threadJSON, err := thread.MarshalJSON()
 	if err != nil {
@@ -113,6 +130,10 @@ func (threadHandler *ThreadHandler) GetThreadPosts(c *gin.Context) {
 			return
 		}
 	}
+	if err := validation.Var("limit", defaultLimit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
 
 	rawDecs := c.Query("desc")
 	defaultDesc := false
@@ -156,11 +177,18 @@ func (threadHandler *ThreadHandler) Vote(c *gin.Context) {
 		return
 	}
 
+	err = validation.Struct(&vote)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
 	thread, err := threadHandler.threadUsecase.VoteForThread(rawId, &vote)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(thread.Version))
 
 	threadJSON, err := thread.MarshalJSON()
 	if err != nil {
@@ -170,3 +198,76 @@ func (threadHandler *ThreadHandler) Vote(c *gin.Context) {
 
 	c.Data(http.StatusOK, "application/json; charset=utf-8", threadJSON)
 }
+
+// RetractVote removes the vote of ?nickname= and returns the thread with its
+// updated votes.
+func (threadHandler *ThreadHandler) RetractVote(c *gin.Context) {
+	rawId := c.Param("slug_or_id")
+
+	nickname := c.Query("nickname")
+	if nickname == "" {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	thread, err := threadHandler.threadUsecase.RetractVote(rawId, nickname)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	threadJSON, err := thread.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", threadJSON)
+}
+
+func (threadHandler *ThreadHandler) GetThreadVoters(c *gin.Context) {
+	rawId := c.Param("slug_or_id")
+
+	since := c.Query("since")
+
+	rawLimit := c.Query("limit")
+	defaultLimit := 100
+
+	if rawLimit != "" {
+		var err error
+		defaultLimit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", defaultLimit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	rawDecs := c.Query("desc")
+	defaultDesc := false
+
+	if rawDecs != "" {
+		var err error
+		defaultDesc, err = strconv.ParseBool(rawDecs)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	voters, err := threadHandler.threadUsecase.GetThreadVoters(rawId, defaultLimit, since, defaultDesc)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	votersJSON, err := voters.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", votersJSON)
+}
@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
 	"db_forum/app/models"
 	"db_forum/app/usecases"
 	"db_forum/pkg"
+	"db_forum/pkg/validation"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/mailru/easyjson"
 
@@ -29,6 +36,13 @@ func (userHandler *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 	user.Nickname = nickname
+
+	err = validation.Struct(&user)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
 	users, err := userHandler.userUsecase.CreateNewUser(&user)
 	if err != nil && pkg.ConvertErrorToCode(err) != http.StatusConflict {
 		c.Data(pkg.CreateErrorResponse(err))
@@ -59,9 +73,18 @@ func (userHandler *UserHandler) GetUser(c *gin.Context) {
 
 	user, err := userHandler.userUsecase.GetInfoAboutUser(nickname)
 	if err != nil {
+		// the redirect of a renamed user lasts only for a while, so it is not
+		// a permanent one
+		if target, redirectErr := userHandler.userUsecase.GetRenamedUser(nickname); redirectErr == nil {
+			c.Redirect(http.StatusTemporaryRedirect, pkg.RootRoute+pkg.UserRoute+"/"+url.PathEscape(target)+"/profile")
+			return
+		}
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	if notModified(c, pkg.CreateETag(user.Version)) {
+		return
+	}
 
 	userJSON, err := user.MarshalJSON()
 	if err != nil {
@@ -94,13 +117,26 @@ func (userHandler *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user := &models.User{Nickname: nickname, Fullname: userUpdate.Fullname, About: userUpdate.About, Email: userUpdate.Email}
+	err = validation.Struct(userUpdate)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	version, err := pkg.ParseIfMatch(c.GetHeader(pkg.IfMatchHeader))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	user := &models.User{Nickname: nickname, Fullname: userUpdate.Fullname, About: userUpdate.About, Email: userUpdate.Email, Version: version}
 
 	err = userHandler.userUsecase.UpdateUser(user)
 	if err != nil {
 		c.Data(pkg.CreateErrorResponse(err))
 		return
 	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(user.Version))
 
 	userJSON, err := user.MarshalJSON()
 	if err != nil {
@@ -110,3 +146,227 @@ func (userHandler *UserHandler) UpdateUser(c *gin.Context) {
 
 	c.Data(http.StatusOK, "application/json; charset=utf-8", userJSON)
 }
+
+func (userHandler *UserHandler) RenameUser(c *gin.Context) {
+	nickname := c.Param("nickname")
+
+	rename := new(models.UserRename)
+	err := easyjson.UnmarshalFromReader(c.Request.Body, rename)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	err = validation.Struct(rename)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	version, err := pkg.ParseIfMatch(c.GetHeader(pkg.IfMatchHeader))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	user := &models.User{Nickname: nickname, Version: version}
+	err = userHandler.userUsecase.RenameUser(user, rename.Nickname)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	c.Header(pkg.ETagHeader, pkg.CreateETag(user.Version))
+
+	userJSON, err := user.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", userJSON)
+}
+
+func (userHandler *UserHandler) GetUserPosts(c *gin.Context) {
+	nickname := c.Param("nickname")
+
+	since := -1
+	if rawSince := c.Query("since"); rawSince != "" {
+		var err error
+		since, err = strconv.Atoi(rawSince)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	limit := 100
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	desc := false
+	if rawDesc := c.Query("desc"); rawDesc != "" {
+		var err error
+		desc, err = strconv.ParseBool(rawDesc)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	posts, err := userHandler.userUsecase.GetUserPosts(nickname, limit, since, desc)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	postsJSON, err := posts.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", postsJSON)
+}
+
+func (userHandler *UserHandler) GetUserThreads(c *gin.Context) {
+	nickname := c.Param("nickname")
+
+	limit := 100
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	desc := false
+	if rawDesc := c.Query("desc"); rawDesc != "" {
+		var err error
+		desc, err = strconv.ParseBool(rawDesc)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	threads, err := userHandler.userUsecase.GetUserThreads(nickname, limit, c.Query("since"), desc)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	threadsJSON, err := threads.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", threadsJSON)
+}
+
+func (userHandler *UserHandler) GetUserForums(c *gin.Context) {
+	nickname := c.Param("nickname")
+
+	limit := 100
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+	desc := false
+	if rawDesc := c.Query("desc"); rawDesc != "" {
+		var err error
+		desc, err = strconv.ParseBool(rawDesc)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	forums, err := userHandler.userUsecase.GetUserForums(nickname, limit, c.Query("since"), desc)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	forumsJSON, err := forums.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", forumsJSON)
+}
+
+// ExportUser sends a zip archive with the profile, threads, posts and votes of
+// the user, one JSON file each.
+func (userHandler *UserHandler) ExportUser(c *gin.Context) {
+	nickname := c.Param("nickname")
+
+	export, err := userHandler.userUsecase.ExportUser(nickname)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	archive := new(bytes.Buffer)
+	writer := zip.NewWriter(archive)
+	files := []struct {
+		name  string
+		value easyjson.Marshaler
+	}{
+		{"user.json", export.User},
+		{"threads.json", export.Threads},
+		{"posts.json", export.Posts},
+		{"votes.json", export.Votes},
+	}
+	for _, file := range files {
+		var fileWriter io.Writer
+		fileWriter, err = writer.Create(file.name)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(err))
+			return
+		}
+		if _, err = easyjson.MarshalToWriter(file.value, fileWriter); err != nil {
+			c.Data(pkg.CreateErrorResponse(err))
+			return
+		}
+	}
+	if err = writer.Close(); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.User.Nickname+".zip"))
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+func (userHandler *UserHandler) DeleteUser(c *gin.Context) {
+	err := userHandler.userUsecase.DeleteUser(c.Param("nickname"))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
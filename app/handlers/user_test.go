@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/app/usecases"
+	"db_forum/pkg"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUserUsecase counts the calls that got past the handler.
+type fakeUserUsecase struct {
+	usecases.UserUsecase
+	calls int
+}
+
+func (userUsecase *fakeUserUsecase) CreateNewUser(user *models.User) (*models.Users, error) {
+	userUsecase.calls++
+	return nil, nil
+}
+
+func (userUsecase *fakeUserUsecase) UpdateUser(user *models.User) error {
+	userUsecase.calls++
+	return nil
+}
+
+// fakeUserRepository holds one user and, like the update query, writes only
+// when the version matches. A rename fails with renameErr.
+type fakeUserRepository struct {
+	repositories.UserRepository
+	user      models.User
+	renameErr error
+}
+
+func (userRepository *fakeUserRepository) GetInfoAboutUser(nickname string) (*models.User, error) {
+	user := userRepository.user
+	return &user, nil
+}
+
+func (userRepository *fakeUserRepository) UpdateUser(user *models.User) error {
+	if user.Version != 0 && user.Version != userRepository.user.Version {
+		return pkg.ErrPreconditionFailed
+	}
+	userRepository.user.Fullname, userRepository.user.About, userRepository.user.Email = user.Fullname, user.About, user.Email
+	userRepository.user.Version++
+	*user = userRepository.user
+	return nil
+}
+
+func (userRepository *fakeUserRepository) RenameUser(user *models.User, nickname string, ttl time.Duration) error {
+	if userRepository.renameErr != nil {
+		return userRepository.renameErr
+	}
+	userRepository.user.Nickname = nickname
+	*user = userRepository.user
+	return nil
+}
+
+func makeUserRouter(userUsecase usecases.UserUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := MakeUserHandler(userUsecase)
+	router.POST("/user/:nickname/create", handler.CreateUser)
+	router.GET("/user/:nickname/profile", handler.GetUser)
+	router.POST("/user/:nickname/profile", handler.UpdateUser)
+	router.POST("/user/:nickname/rename", handler.RenameUser)
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
+
+func TestUserValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		errors string
+	}{
+		{"valid user", "/user/alice/create", `{"fullname":"Alice","email":"alice@example.com"}`, http.StatusCreated, ""},
+		{"user without email", "/user/alice/create", `{"fullname":"Alice"}`, http.StatusBadRequest,
+			`"fields":[{"field":"email","rule":"required"}]`},
+		{"email without @", "/user/alice/create", `{"fullname":"Alice","email":"alice"}`, http.StatusBadRequest,
+			`"fields":[{"field":"email","rule":"contains","param":"@"}]`},
+		{"partial update", "/user/alice/profile", `{"about":"Hi"}`, http.StatusOK, ""},
+		{"update with an email without @", "/user/alice/profile", `{"email":"alice"}`, http.StatusBadRequest,
+			`"fields":[{"field":"email","rule":"contains","param":"@"}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userUsecase := &fakeUserUsecase{}
+			response := serve(makeUserRouter(userUsecase), http.MethodPost, test.path, test.body, nil)
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", response.Code, test.status, response.Body)
+			}
+			if test.errors == "" {
+				if userUsecase.calls != 1 {
+					t.Errorf("the use case was called %d times, want once", userUsecase.calls)
+				}
+				return
+			}
+			if !strings.Contains(response.Body.String(), test.errors) {
+				t.Errorf("body = %s, want it to list %s", response.Body, test.errors)
+			}
+			if userUsecase.calls != 0 {
+				t.Errorf("the use case ran for an invalid request")
+			}
+		})
+	}
+}
+
+func TestUserIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		etag    string
+		about   string
+	}{
+		{"unconditional", "", http.StatusOK, `"3"`, "Updated"},
+		{"current version", `"2"`, http.StatusOK, `"3"`, "Updated"},
+		{"weak current version", `W/"2"`, http.StatusOK, `"3"`, "Updated"},
+		{"any version", "*", http.StatusOK, `"3"`, "Updated"},
+		{"stale version", `"1"`, http.StatusPreconditionFailed, "", "Hi"},
+		{"future version", `"5"`, http.StatusPreconditionFailed, "", "Hi"},
+		{"malformed", `"abc"`, http.StatusPreconditionFailed, "", "Hi"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeUserRepository{user: models.User{Nickname: "alice", Fullname: "Alice", About: "Hi", Email: "alice@example.com", Version: 2}}
+			router := makeUserRouter(usecases.MakeUserUseCase(repository))
+
+			response := serve(router, http.MethodPost, "/user/alice/profile", `{"about":"Updated"}`, map[string]string{pkg.IfMatchHeader: test.ifMatch})
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", response.Code, test.status, response.Body)
+			}
+			if etag := response.Header().Get(pkg.ETagHeader); etag != test.etag {
+				t.Errorf("ETag = %s, want %s", etag, test.etag)
+			}
+			if repository.user.About != test.about {
+				t.Errorf("about = %q, want %q", repository.user.About, test.about)
+			}
+		})
+	}
+}
+
+func TestUserIfNoneMatch(t *testing.T) {
+	repository := &fakeUserRepository{user: models.User{Nickname: "alice", Email: "alice@example.com", Version: 2}}
+	router := makeUserRouter(usecases.MakeUserUseCase(repository))
+
+	tests := []struct {
+		ifNoneMatch string
+		status      int
+	}{
+		{"", http.StatusOK},
+		{`"2"`, http.StatusNotModified},
+		{`"1", "2"`, http.StatusNotModified},
+		{`"1"`, http.StatusOK},
+	}
+	for _, test := range tests {
+		response := serve(router, http.MethodGet, "/user/alice/profile", "", map[string]string{pkg.IfNoneMatchHeader: test.ifNoneMatch})
+		if response.Code != test.status {
+			t.Errorf("If-None-Match %s: status = %d, want %d", test.ifNoneMatch, response.Code, test.status)
+		}
+		if etag := response.Header().Get(pkg.ETagHeader); etag != `"2"` {
+			t.Errorf("If-None-Match %s: ETag = %s", test.ifNoneMatch, etag)
+		}
+	}
+}
+
+func TestUserRenameErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"renamed", nil, http.StatusOK},
+		{"nickname taken meanwhile", pkg.ErrUserAlreadyExist, http.StatusConflict},
+		{"stale version", pkg.ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{"database failure", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeUserRepository{user: models.User{Nickname: "alice", Email: "alice@example.com", Version: 1}, renameErr: test.err}
+			router := makeUserRouter(usecases.MakeUserUseCase(repository))
+
+			response := serve(router, http.MethodPost, "/user/alice/rename", `{"nickname":"carol"}`, nil)
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", response.Code, test.status, response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/usecases"
+	"db_forum/pkg"
+	"db_forum/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/mailru/easyjson"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookUsecase usecases.WebhookUsecase
+}
+
+func MakeWebhookHandler(webhookUsecase_ usecases.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase: webhookUsecase_}
+}
+
+func (webhookHandler *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var webhook models.Webhook
+	err := easyjson.UnmarshalFromReader(c.Request.Body, &webhook)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+	webhook.Forum = c.Param("slug")
+
+	err = validation.Struct(&webhook)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	err = webhookHandler.webhookUsecase.CreateWebhook(&webhook)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	webhookJSON, err := webhook.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", webhookJSON)
+}
+
+func (webhookHandler *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := webhookHandler.webhookUsecase.GetForumWebhooks(c.Param("slug"))
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	webhooksJSON, err := webhooks.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", webhooksJSON)
+}
+
+func (webhookHandler *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	err = webhookHandler.webhookUsecase.DeleteWebhook(c.Param("slug"), id)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (webhookHandler *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+		return
+	}
+
+	since := int64(-1)
+	rawSince := c.Query("since")
+	if rawSince != "" {
+		since, err = strconv.ParseInt(rawSince, 10, 64)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	limit := 100
+	rawLimit := c.Query("limit")
+	if rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+	if err := validation.Var("limit", limit, "min=0"); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	desc := false
+	rawDesc := c.Query("desc")
+	if rawDesc != "" {
+		desc, err = strconv.ParseBool(rawDesc)
+		if err != nil {
+			c.Data(pkg.CreateErrorResponse(pkg.ErrBadRequest))
+			return
+		}
+	}
+
+	deliveries, err := webhookHandler.webhookUsecase.GetWebhookDeliveries(c.Param("slug"), id, limit, since, desc)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	deliveriesJSON, err := deliveries.MarshalJSON()
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", deliveriesJSON)
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"db_forum/pkg/validation"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyValidations = "max=255"
+)
+
+type IdempotencyMiddleware struct {
+	repoIdempotency repositories.IdempotencyRepository
+	ttl             time.Duration
+	lease           time.Duration
+	maxBody         int64
+}
+
+// MakeIdempotencyMiddleware keeps successful responses for ttl; a key whose
+// request has not finished within lease may be claimed again. Bodies of
+// requests with a key are read into memory to be hashed, so they are limited
+// to maxBody bytes.
+func MakeIdempotencyMiddleware(idempotency repositories.IdempotencyRepository, ttl, lease time.Duration, maxBody int64) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{repoIdempotency: idempotency, ttl: ttl, lease: lease, maxBody: maxBody}
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *responseRecorder) WriteString(data string) (int, error) {
+	recorder.body.WriteString(data)
+	return recorder.ResponseWriter.WriteString(data)
+}
+
+// Handle replays the stored response for a repeated Idempotency-Key and
+// records the response of the first request carrying it. Only 2xx responses
+// are recorded, other ones release the key so the request can be retried.
+// Both leave the key alone once another request claimed it past the lease.
+func (idempotencyMiddleware *IdempotencyMiddleware) Handle(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if err := validation.Var(IdempotencyKeyHeader, key, idempotencyKeyValidations); err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		c.Abort()
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, idempotencyMiddleware.maxBody))
+	if err != nil {
+		// MaxBytesReader stops with an error after exactly maxBody bytes
+		if int64(len(body)) == idempotencyMiddleware.maxBody {
+			err = pkg.ErrRequestTooLarge
+		} else {
+			err = pkg.ErrBadRequest
+		}
+		c.Data(pkg.CreateErrorResponse(err))
+		c.Abort()
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	requestHash := hashRequest(c.Request.Method, c.Request.URL.Path, body)
+
+	stored, err := idempotencyMiddleware.repoIdempotency.GetResponse(key, idempotencyMiddleware.ttl)
+	if err != nil && err != pgx.ErrNoRows && err != sql.ErrNoRows {
+		c.Data(pkg.CreateErrorResponse(err))
+		c.Abort()
+		return
+	}
+	if err == nil && (stored.Status != 0 || stored.RequestHash != requestHash) {
+		idempotencyMiddleware.replay(c, stored, requestHash)
+		return
+	}
+
+	reserved, err := idempotencyMiddleware.repoIdempotency.ReserveKey(key, requestHash, idempotencyMiddleware.ttl, idempotencyMiddleware.lease)
+	if err != nil {
+		c.Data(pkg.CreateErrorResponse(err))
+		c.Abort()
+		return
+	}
+	if !reserved {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrIdempotencyKeyInProgress))
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	status := recorder.Status()
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		_ = idempotencyMiddleware.repoIdempotency.ReleaseKey(key, requestHash)
+		return
+	}
+	_ = idempotencyMiddleware.repoIdempotency.SaveResponse(&models.IdempotentResponse{
+		Key:         key,
+		RequestHash: requestHash,
+		Status:      status,
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+}
+
+func (idempotencyMiddleware *IdempotencyMiddleware) replay(c *gin.Context, stored *models.IdempotentResponse, requestHash string) {
+	defer c.Abort()
+	if stored.RequestHash != requestHash {
+		c.Data(pkg.CreateErrorResponse(pkg.ErrIdempotencyKeyReused))
+		return
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+}
+
+// DeleteExpired periodically drops keys whose responses can no longer be replayed.
+func (idempotencyMiddleware *IdempotencyMiddleware) DeleteExpired(interval time.Duration) {
+	for range time.Tick(interval) {
+		_ = idempotencyMiddleware.repoIdempotency.DeleteExpired(idempotencyMiddleware.ttl)
+	}
+}
+
+func hashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte(path))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"db_forum/app/repositories/memory"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// makeIdempotentRouter serves POST /things, answering with the statuses in turn.
+func makeIdempotentRouter(middleware *IdempotencyMiddleware, statuses ...int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	router := gin.New()
+	router.POST("/things", middleware.Handle, func(c *gin.Context) {
+		status := statuses[calls]
+		calls++
+		c.Data(status, "application/json", []byte(`{"call":`+strconv.Itoa(calls)+`}`))
+	})
+	return router, &calls
+}
+
+func post(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	request.Header.Set(IdempotencyKeyHeader, key)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	middleware := MakeIdempotencyMiddleware(memory.MakeIdempotencyRepository(memory.MakeStore()), time.Hour, time.Minute, 1<<20)
+	router, calls := makeIdempotentRouter(middleware, http.StatusInternalServerError, http.StatusCreated)
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		response string
+		replayed bool
+		calls    int
+	}{
+		{"failure is not stored", `{"a":1}`, http.StatusInternalServerError, `{"call":1}`, false, 1},
+		{"retry after the failure", `{"a":1}`, http.StatusCreated, `{"call":2}`, false, 2},
+		{"replay of the success", `{"a":1}`, http.StatusCreated, `{"call":2}`, true, 2},
+		{"key reused with another body", `{"a":2}`, http.StatusConflict, "", false, 2},
+	}
+	for _, test := range tests {
+		response := post(router, "key", test.body)
+		if response.Code != test.status {
+			t.Fatalf("%s: status = %d, want %d", test.name, response.Code, test.status)
+		}
+		if test.response != "" && response.Body.String() != test.response {
+			t.Errorf("%s: body = %s, want %s", test.name, response.Body, test.response)
+		}
+		if replayed := response.Header().Get(IdempotentReplayedHeader) == "true"; replayed != test.replayed {
+			t.Errorf("%s: replayed = %t, want %t", test.name, replayed, test.replayed)
+		}
+		if *calls != test.calls {
+			t.Errorf("%s: handler called %d times, want %d", test.name, *calls, test.calls)
+		}
+	}
+}
+
+func TestIdempotencyMiddlewareLease(t *testing.T) {
+	tests := []struct {
+		name   string
+		lease  time.Duration
+		status int
+	}{
+		{"claim within the lease", time.Minute, http.StatusConflict},
+		{"claim past the lease", 0, http.StatusCreated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := memory.MakeIdempotencyRepository(memory.MakeStore())
+			requestHash := hashRequest(http.MethodPost, "/things", []byte(`{}`))
+			if _, err := repository.ReserveKey("key", requestHash, time.Hour, test.lease); err != nil {
+				t.Fatal(err)
+			}
+
+			router, _ := makeIdempotentRouter(MakeIdempotencyMiddleware(repository, time.Hour, test.lease, 1<<20), http.StatusCreated)
+			if response := post(router, "key", `{}`); response.Code != test.status {
+				t.Fatalf("status = %d, want %d", response.Code, test.status)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareBodyLimit(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+		calls  int
+	}{
+		{`{"a":1}`, http.StatusCreated, 1},
+		{`{"a":12}`, http.StatusCreated, 1},
+		{`{"a":123}`, http.StatusRequestEntityTooLarge, 0},
+	}
+	for _, test := range tests {
+		middleware := MakeIdempotencyMiddleware(memory.MakeIdempotencyRepository(memory.MakeStore()), time.Hour, time.Minute, 8)
+		router, calls := makeIdempotentRouter(middleware, http.StatusCreated)
+		if response := post(router, "key", test.body); response.Code != test.status {
+			t.Errorf("%d bytes: status = %d, want %d", len(test.body), response.Code, test.status)
+		}
+		if *calls != test.calls {
+			t.Errorf("%d bytes: handler called %d times, want %d", len(test.body), *calls, test.calls)
+		}
+	}
+}
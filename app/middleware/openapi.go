@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"db_forum/app/models"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIValidator checks requests and responses against the OpenAPI
+// document. It is meant for development: a request breaking the document is
+// rejected with 400 and a response breaking it is replaced by a 500 naming
+// the mismatch.
+type OpenAPIValidator struct {
+	router routers.Router
+}
+
+func MakeOpenAPIValidator(spec []byte) (*OpenAPIValidator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(loader.Context); err != nil {
+		return nil, err
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	// the user export is a zip archive, checked like any other binary string
+	openapi3filter.RegisterBodyDecoder("application/zip", openapi3filter.FileBodyDecoder)
+	return &OpenAPIValidator{router: router}, nil
+}
+
+// bufferedWriter holds the response back until it is validated.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (writer *bufferedWriter) WriteHeader(status int) {
+	writer.status = status
+}
+
+func (writer *bufferedWriter) WriteHeaderNow() {}
+
+func (writer *bufferedWriter) Write(data []byte) (int, error) {
+	return writer.body.Write(data)
+}
+
+func (writer *bufferedWriter) WriteString(data string) (int, error) {
+	return writer.body.WriteString(data)
+}
+
+func (writer *bufferedWriter) Status() int {
+	return writer.status
+}
+
+func (writer *bufferedWriter) Size() int {
+	return writer.body.Len()
+}
+
+func (writer *bufferedWriter) Written() bool {
+	return false
+}
+
+func (openAPIValidator *OpenAPIValidator) Handle(c *gin.Context) {
+	route, pathParams, err := openAPIValidator.router.FindRoute(c.Request)
+	if err != nil {
+		// routes missing from the document, like the document itself
+		c.Next()
+		return
+	}
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    c.Request,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{MultiError: true},
+	}
+	err = openapi3filter.ValidateRequest(c.Request.Context(), requestInput)
+	if err != nil {
+		writeSpecError(c, http.StatusBadRequest, fmt.Sprintf("request does not match the API specification: %s", err))
+		c.Abort()
+		return
+	}
+
+	if streaming(route) {
+		c.Next()
+		return
+	}
+
+	writer := c.Writer
+	buffered := &bufferedWriter{ResponseWriter: writer, status: http.StatusOK}
+	c.Writer = buffered
+	c.Next()
+	c.Writer = writer
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 buffered.status,
+		Header:                 writer.Header(),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+	}
+	responseInput.SetBodyBytes(buffered.body.Bytes())
+	err = openapi3filter.ValidateResponse(c.Request.Context(), responseInput)
+	if err != nil {
+		writeSpecError(c, http.StatusInternalServerError, fmt.Sprintf("response %d does not match the API specification: %s", buffered.status, err))
+		return
+	}
+
+	writer.WriteHeader(buffered.status)
+	_, _ = writer.Write(buffered.body.Bytes())
+}
+
+// streaming tells the event stream routes apart, their responses never end.
+func streaming(route *routers.Route) bool {
+	if route.Operation.Responses.Get(http.StatusSwitchingProtocols) != nil {
+		return true
+	}
+	ok := route.Operation.Responses.Get(http.StatusOK)
+	return ok != nil && ok.Value != nil && ok.Value.Content.Get("text/event-stream") != nil
+}
+
+func writeSpecError(c *gin.Context, status int, message string) {
+	errorJSON, _ := models.Error{Message: message}.MarshalJSON()
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Writer.WriteHeader(status)
+	_, _ = c.Writer.Write(errorJSON)
+}
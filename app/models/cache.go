@@ -0,0 +1,15 @@
+package models
+
+// CacheCounters are the lookups of one kind of entity since the start.
+type CacheCounters struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+//easyjson:json
+type CacheStats struct {
+	Backend string        `json:"backend"`
+	Forum   CacheCounters `json:"forum"`
+	Thread  CacheCounters `json:"thread"`
+	User    CacheCounters `json:"user"`
+}
@@ -0,0 +1,179 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonA591d1bcDecodeDbForumAppModels(in *jlexer.Lexer, out *CacheStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "backend":
+			out.Backend = string(in.String())
+		case "forum":
+			(out.Forum).UnmarshalEasyJSON(in)
+		case "thread":
+			(out.Thread).UnmarshalEasyJSON(in)
+		case "user":
+			(out.User).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonA591d1bcEncodeDbForumAppModels(out *jwriter.Writer, in CacheStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"backend\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Backend))
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		(in.Forum).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		(in.Thread).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		(in.User).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CacheStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonA591d1bcEncodeDbForumAppModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CacheStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonA591d1bcEncodeDbForumAppModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CacheStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonA591d1bcDecodeDbForumAppModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CacheStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonA591d1bcDecodeDbForumAppModels(l, v)
+}
+func easyjsonA591d1bcDecodeDbForumAppModels1(in *jlexer.Lexer, out *CacheCounters) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "hits":
+			out.Hits = int64(in.Int64())
+		case "misses":
+			out.Misses = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonA591d1bcEncodeDbForumAppModels1(out *jwriter.Writer, in CacheCounters) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"hits\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Hits))
+	}
+	{
+		const prefix string = ",\"misses\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Misses))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CacheCounters) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonA591d1bcEncodeDbForumAppModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CacheCounters) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonA591d1bcEncodeDbForumAppModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CacheCounters) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonA591d1bcDecodeDbForumAppModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CacheCounters) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonA591d1bcDecodeDbForumAppModels1(l, v)
+}
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/mailru/easyjson"
+
+//easyjson:json
+type ThreadEvent struct {
+	Type   string `json:"type"`
+	Thread int64  `json:"thread"`
+	Id     int64  `json:"id,omitempty"`
+	Votes  int32  `json:"votes"`
+}
+
+//easyjson:json
+type StreamMessage struct {
+	Event string              `json:"event"`
+	Id    int64               `json:"id,omitempty"`
+	Data  easyjson.RawMessage `json:"data"`
+}
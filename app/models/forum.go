@@ -1,9 +1,26 @@
 package models
 
+import "time"
+
 type Forum struct {
-	Title   string `json:"title"`
-	User    string `json:"user"`
-	Slug    string `json:"slug"`
+	Title   string `json:"title" validate:"required"`
+	User    string `json:"user" validate:"required"`
+	Slug    string `json:"slug" validate:"required"`
 	Posts   int64  `json:"posts"`
 	Threads int32  `json:"threads"`
+	Version int64  `json:"-"`
+}
+
+//easyjson:json
+type ForumStats []ForumStatsBucket
+
+// ForumStatsBucket is the activity of a forum during the hour or the day from
+// Start: the threads, posts and votes created then and the number of users
+// who created them.
+type ForumStatsBucket struct {
+	Start   time.Time `json:"start"`
+	Threads int32     `json:"threads"`
+	Posts   int32     `json:"posts"`
+	Users   int32     `json:"users"`
+	Votes   int32     `json:"votes"`
 }
@@ -0,0 +1,9 @@
+package models
+
+type IdempotentResponse struct {
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+}
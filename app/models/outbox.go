@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+//easyjson:json
+type OutboxEvent struct {
+	Id       int64               `json:"id"`
+	Type     string              `json:"type"`
+	Forum    string              `json:"forum,omitempty"`
+	Payload  easyjson.RawMessage `json:"payload"`
+	Attempts int32               `json:"attempts"`
+	Created  time.Time           `json:"created"`
+}
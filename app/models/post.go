@@ -7,12 +7,14 @@ type Posts []Post
 type Post struct {
 	Id       int64  `json:"id"`
 	Parent   int64  `json:"parent"`
-	Author   string `json:"author"`
-	Message  string `json:"message"`
+	Author   string `json:"author" validate:"required"`
+	Message  string `json:"message" validate:"required"`
 	IsEdited bool   `json:"isEdited"`
 	Forum    string `json:"forum"`
 	Thread   int64  `json:"thread"`
 	Created  string `json:"created"`
+	Score    int32  `json:"score"`
+	Version  int64  `json:"-"`
 }
 
 //easyjson:json
@@ -186,7 +186,7 @@ func easyjson5a72dc82DecodeDbForumAppModels2(in *jlexer.Lexer, out *PostFull) {
 				if out.Author == nil {
 					out.Author = new(User)
 				}
-				easyjson5a72dc82DecodeDbForumAppModels3(in, out.Author)
+				(*out.Author).UnmarshalEasyJSON(in)
 			}
 		case "thread":
 			if in.IsNull() {
@@ -196,7 +196,7 @@ func easyjson5a72dc82DecodeDbForumAppModels2(in *jlexer.Lexer, out *PostFull) {
 				if out.Thread == nil {
 					out.Thread = new(Thread)
 				}
-				easyjson5a72dc82DecodeDbForumAppModels4(in, out.Thread)
+				(*out.Thread).UnmarshalEasyJSON(in)
 			}
 		case "forum":
 			if in.IsNull() {
@@ -234,12 +234,12 @@ func easyjson5a72dc82EncodeDbForumAppModels2(out *jwriter.Writer, in PostFull) {
 	if in.Author != nil {
 		const prefix string = ",\"author\":"
 		out.RawString(prefix)
-		easyjson5a72dc82EncodeDbForumAppModels3(out, *in.Author)
+		(*in.Author).MarshalEasyJSON(out)
 	}
 	if in.Thread != nil {
 		const prefix string = ",\"thread\":"
 		out.RawString(prefix)
-		easyjson5a72dc82EncodeDbForumAppModels4(out, *in.Thread)
+		(*in.Thread).MarshalEasyJSON(out)
 	}
 	if in.Forum != nil {
 		const prefix string = ",\"forum\":"
@@ -272,163 +272,7 @@ func (v *PostFull) UnmarshalJSON(data []byte) error {
 func (v *PostFull) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson5a72dc82DecodeDbForumAppModels2(l, v)
 }
-func easyjson5a72dc82DecodeDbForumAppModels4(in *jlexer.Lexer, out *Thread) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
-		}
-		in.Skip()
-		return
-	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeFieldName(false)
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
-		}
-		switch key {
-		case "id":
-			out.Id = int64(in.Int64())
-		case "title":
-			out.Title = string(in.String())
-		case "author":
-			out.Author = string(in.String())
-		case "forum":
-			out.Forum = string(in.String())
-		case "message":
-			out.Message = string(in.String())
-		case "votes":
-			out.Votes = int32(in.Int32())
-		case "slug":
-			out.Slug = string(in.String())
-		case "created":
-			if data := in.Raw(); in.Ok() {
-				in.AddError((out.Created).UnmarshalJSON(data))
-			}
-		default:
-			in.SkipRecursive()
-		}
-		in.WantComma()
-	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
-	}
-}
-func easyjson5a72dc82EncodeDbForumAppModels4(out *jwriter.Writer, in Thread) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	{
-		const prefix string = ",\"id\":"
-		out.RawString(prefix[1:])
-		out.Int64(int64(in.Id))
-	}
-	{
-		const prefix string = ",\"title\":"
-		out.RawString(prefix)
-		out.String(string(in.Title))
-	}
-	{
-		const prefix string = ",\"author\":"
-		out.RawString(prefix)
-		out.String(string(in.Author))
-	}
-	{
-		const prefix string = ",\"forum\":"
-		out.RawString(prefix)
-		out.String(string(in.Forum))
-	}
-	{
-		const prefix string = ",\"message\":"
-		out.RawString(prefix)
-		out.String(string(in.Message))
-	}
-	{
-		const prefix string = ",\"votes\":"
-		out.RawString(prefix)
-		out.Int32(int32(in.Votes))
-	}
-	{
-		const prefix string = ",\"slug\":"
-		out.RawString(prefix)
-		out.String(string(in.Slug))
-	}
-	{
-		const prefix string = ",\"created\":"
-		out.RawString(prefix)
-		out.Raw((in.Created).MarshalJSON())
-	}
-	out.RawByte('}')
-}
-func easyjson5a72dc82DecodeDbForumAppModels3(in *jlexer.Lexer, out *User) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
-		}
-		in.Skip()
-		return
-	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeFieldName(false)
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
-		}
-		switch key {
-		case "nickname":
-			out.Nickname = string(in.String())
-		case "fullname":
-			out.Fullname = string(in.String())
-		case "about":
-			out.About = string(in.String())
-		case "email":
-			out.Email = string(in.String())
-		default:
-			in.SkipRecursive()
-		}
-		in.WantComma()
-	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
-	}
-}
-func easyjson5a72dc82EncodeDbForumAppModels3(out *jwriter.Writer, in User) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	{
-		const prefix string = ",\"nickname\":"
-		out.RawString(prefix[1:])
-		out.String(string(in.Nickname))
-	}
-	{
-		const prefix string = ",\"fullname\":"
-		out.RawString(prefix)
-		out.String(string(in.Fullname))
-	}
-	{
-		const prefix string = ",\"about\":"
-		out.RawString(prefix)
-		out.String(string(in.About))
-	}
-	{
-		const prefix string = ",\"email\":"
-		out.RawString(prefix)
-		out.String(string(in.Email))
-	}
-	out.RawByte('}')
-}
-func easyjson5a72dc82DecodeDbForumAppModels5(in *jlexer.Lexer, out *Post) {
+func easyjson5a72dc82DecodeDbForumAppModels3(in *jlexer.Lexer, out *Post) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -463,6 +307,8 @@ func easyjson5a72dc82DecodeDbForumAppModels5(in *jlexer.Lexer, out *Post) {
 			out.Thread = int64(in.Int64())
 		case "created":
 			out.Created = string(in.String())
+		case "score":
+			out.Score = int32(in.Int32())
 		default:
 			in.SkipRecursive()
 		}
@@ -473,7 +319,7 @@ func easyjson5a72dc82DecodeDbForumAppModels5(in *jlexer.Lexer, out *Post) {
 		in.Consumed()
 	}
 }
-func easyjson5a72dc82EncodeDbForumAppModels5(out *jwriter.Writer, in Post) {
+func easyjson5a72dc82EncodeDbForumAppModels3(out *jwriter.Writer, in Post) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -517,29 +363,34 @@ func easyjson5a72dc82EncodeDbForumAppModels5(out *jwriter.Writer, in Post) {
 		out.RawString(prefix)
 		out.String(string(in.Created))
 	}
+	{
+		const prefix string = ",\"score\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Score))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Post) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson5a72dc82EncodeDbForumAppModels5(&w, v)
+	easyjson5a72dc82EncodeDbForumAppModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson5a72dc82EncodeDbForumAppModels5(w, v)
+	easyjson5a72dc82EncodeDbForumAppModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Post) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson5a72dc82DecodeDbForumAppModels5(&r, v)
+	easyjson5a72dc82DecodeDbForumAppModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson5a72dc82DecodeDbForumAppModels5(l, v)
+	easyjson5a72dc82DecodeDbForumAppModels3(l, v)
 }
@@ -0,0 +1,34 @@
+package models
+
+// Counters checked by the reconciliation.
+const (
+	CounterForumPosts   = "forums.posts"
+	CounterForumThreads = "forums.threads"
+	CounterThreadVotes  = "threads.votes"
+	CounterForumUsers   = "user_forum"
+
+	CounterForumUserThreads    = "user_forum.threads"
+	CounterForumUserPosts      = "user_forum.posts"
+	CounterForumUserJoined     = "user_forum.joined"
+	CounterForumUserLastActive = "user_forum.last_active"
+)
+
+// CounterDrift is a counter that disagrees with the rows it counts. For
+// user_forum Stored and Actual are the numbers of rows and of participants of
+// the forum, they can be equal when the sets still differ. The activity of a
+// participant is checked per Nickname, joined and last_active are compared as
+// microseconds since the epoch.
+type CounterDrift struct {
+	Counter  string `json:"counter"`
+	Forum    string `json:"forum,omitempty"`
+	Thread   int64  `json:"thread,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	Stored   int64  `json:"stored"`
+	Actual   int64  `json:"actual"`
+}
+
+//easyjson:json
+type Reconciliation struct {
+	Drifts   []CounterDrift `json:"drifts"`
+	Repaired bool           `json:"repaired"`
+}
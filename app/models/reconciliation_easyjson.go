@@ -0,0 +1,225 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonEae9a35fDecodeDbForumAppModels(in *jlexer.Lexer, out *Reconciliation) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "drifts":
+			if in.IsNull() {
+				in.Skip()
+				out.Drifts = nil
+			} else {
+				in.Delim('[')
+				if out.Drifts == nil {
+					if !in.IsDelim(']') {
+						out.Drifts = make([]CounterDrift, 0, 0)
+					} else {
+						out.Drifts = []CounterDrift{}
+					}
+				} else {
+					out.Drifts = (out.Drifts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 CounterDrift
+					(v1).UnmarshalEasyJSON(in)
+					out.Drifts = append(out.Drifts, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "repaired":
+			out.Repaired = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonEae9a35fEncodeDbForumAppModels(out *jwriter.Writer, in Reconciliation) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"drifts\":"
+		out.RawString(prefix[1:])
+		if in.Drifts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Drifts {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"repaired\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Repaired))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Reconciliation) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonEae9a35fEncodeDbForumAppModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Reconciliation) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonEae9a35fEncodeDbForumAppModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Reconciliation) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonEae9a35fDecodeDbForumAppModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Reconciliation) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonEae9a35fDecodeDbForumAppModels(l, v)
+}
+func easyjsonEae9a35fDecodeDbForumAppModels1(in *jlexer.Lexer, out *CounterDrift) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "counter":
+			out.Counter = string(in.String())
+		case "forum":
+			out.Forum = string(in.String())
+		case "thread":
+			out.Thread = int64(in.Int64())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "stored":
+			out.Stored = int64(in.Int64())
+		case "actual":
+			out.Actual = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonEae9a35fEncodeDbForumAppModels1(out *jwriter.Writer, in CounterDrift) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"counter\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Counter))
+	}
+	if in.Forum != "" {
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	if in.Thread != 0 {
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Thread))
+	}
+	if in.Nickname != "" {
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"stored\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Stored))
+	}
+	{
+		const prefix string = ",\"actual\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Actual))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CounterDrift) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonEae9a35fEncodeDbForumAppModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CounterDrift) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonEae9a35fEncodeDbForumAppModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CounterDrift) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonEae9a35fDecodeDbForumAppModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CounterDrift) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonEae9a35fDecodeDbForumAppModels1(l, v)
+}
@@ -8,13 +8,14 @@ type Threads []Thread
 //easyjson:json
 type Thread struct {
 	Id      int64     `json:"id"`
-	Title   string    `json:"title"`
-	Author  string    `json:"author"`
+	Title   string    `json:"title" validate:"required"`
+	Author  string    `json:"author" validate:"required"`
 	Forum   string    `json:"forum"`
-	Message string    `json:"message"`
+	Message string    `json:"message" validate:"required"`
 	Votes   int32     `json:"votes"`
 	Slug    string    `json:"slug"`
 	Created time.Time `json:"created"`
+	Version int64     `json:"-"`
 }
 
 //easyjson:json
@@ -0,0 +1,24 @@
+package models
+
+// TransferRecord is one line of an export: Type names the entity that is set.
+//
+//easyjson:json
+type TransferRecord struct {
+	Type   string        `json:"type"`
+	User   *User         `json:"user,omitempty"`
+	Forum  *Forum        `json:"forum,omitempty"`
+	Thread *Thread       `json:"thread,omitempty"`
+	Post   *Post         `json:"post,omitempty"`
+	Vote   *TransferVote `json:"vote,omitempty"`
+}
+
+//easyjson:json
+type TransferVotes []TransferVote
+
+// TransferVote is a vote for a thread, or for a post of it if Post is set.
+type TransferVote struct {
+	Nickname string `json:"nickname"`
+	Thread   int64  `json:"thread"`
+	Post     int64  `json:"post,omitempty"`
+	Voice    int32  `json:"voice"`
+}
@@ -1,19 +1,115 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
+// DeletedUser is the tombstone account that takes over the forums, threads
+// and posts of deleted users.
+var DeletedUser = User{Nickname: "deleted", Fullname: "Deleted user", Email: "deleted@localhost"}
+
+// ClaimsTombstone reports whether nickname or email is the one of DeletedUser,
+// which are reserved for it.
+func ClaimsTombstone(nickname, email string) bool {
+	return strings.EqualFold(nickname, DeletedUser.Nickname) || strings.EqualFold(email, DeletedUser.Email)
+}
+
 //easyjson:json
 type Users []User
 
 //easyjson:json
 type User struct {
-	Nickname string `json:"nickname"`
-	Fullname string `json:"fullname"`
-	About    string `json:"about"`
-	Email    string `json:"email"`
+	Nickname   string `json:"nickname" validate:"required"`
+	Fullname   string `json:"fullname"`
+	About      string `json:"about"`
+	Email      string `json:"email" validate:"required,contains=@"`
+	Reputation int32  `json:"reputation"`
+	Version    int64  `json:"-"`
+}
+
+//easyjson:json
+type Leaderboard []Leader
+
+// Leader is a participant of a forum with the reputation they earned in it.
+//
+//easyjson:json
+type Leader struct {
+	Nickname   string `json:"nickname"`
+	Fullname   string `json:"fullname"`
+	Reputation int32  `json:"reputation"`
+}
+
+//easyjson:json
+type ForumUsers []ForumUser
+
+// ForumUser is a participant of a forum with their activity in it: the
+// threads and posts they wrote there, when the first and the latest of them
+// were created.
+//
+//easyjson:json
+type ForumUser struct {
+	Nickname   string    `json:"nickname"`
+	Fullname   string    `json:"fullname"`
+	About      string    `json:"about"`
+	Email      string    `json:"email"`
+	Reputation int32     `json:"reputation"`
+	Threads    int32     `json:"threads"`
+	Posts      int32     `json:"posts"`
+	Joined     time.Time `json:"joined"`
+	LastActive time.Time `json:"lastActive"`
+}
+
+//easyjson:json
+type UserForums []UserForum
+
+// UserForum is a forum the user took part in, Posts counts their posts in it.
+//
+//easyjson:json
+type UserForum struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	User  string `json:"user"`
+	Posts int32  `json:"posts"`
 }
 
 //easyjson:json
 type UserUpdate struct {
 	Fullname string `json:"fullname"`
 	About    string `json:"about"`
-	Email    string `json:"email"`
+	Email    string `json:"email" validate:"omitempty,contains=@"`
+}
+
+//easyjson:json
+type UserRename struct {
+	Nickname string `json:"nickname" validate:"required"`
+}
+
+// UserExport is everything a user authored, for the export archive.
+//
+//easyjson:json
+type UserExport struct {
+	User    *User         `json:"user"`
+	Threads Threads       `json:"threads"`
+	Posts   Posts         `json:"posts"`
+	Votes   TransferVotes `json:"votes"`
+}
+
+// UserMerge counts the rows that merging Source into Target moves, and the
+// votes of Source it drops because Target voted for the same thread or post.
+// ForumUsers counts the forums Target joins.
+//
+//easyjson:json
+type UserMerge struct {
+	Source           string `json:"source"`
+	Target           string `json:"target"`
+	Forums           int64  `json:"forums"`
+	Threads          int64  `json:"threads"`
+	Posts            int64  `json:"posts"`
+	Votes            int64  `json:"votes"`
+	VotesDropped     int64  `json:"votesDropped"`
+	PostVotes        int64  `json:"postVotes"`
+	PostVotesDropped int64  `json:"postVotesDropped"`
+	ForumUsers       int64  `json:"forumUsers"`
+	Merged           bool   `json:"merged"`
 }
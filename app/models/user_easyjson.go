@@ -26,7 +26,7 @@ func easyjson9e1087fdDecodeDbForumAppModels(in *jlexer.Lexer, out *Users) {
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(Users, 0, 1)
+				*out = make(Users, 0, 0)
 			} else {
 				*out = Users{}
 			}
@@ -163,7 +163,767 @@ func (v *UserUpdate) UnmarshalJSON(data []byte) error {
 func (v *UserUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson9e1087fdDecodeDbForumAppModels1(l, v)
 }
-func easyjson9e1087fdDecodeDbForumAppModels2(in *jlexer.Lexer, out *User) {
+func easyjson9e1087fdDecodeDbForumAppModels2(in *jlexer.Lexer, out *UserRename) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels2(out *jwriter.Writer, in UserRename) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserRename) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserRename) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserRename) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserRename) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels2(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels3(in *jlexer.Lexer, out *UserMerge) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "source":
+			out.Source = string(in.String())
+		case "target":
+			out.Target = string(in.String())
+		case "forums":
+			out.Forums = int64(in.Int64())
+		case "threads":
+			out.Threads = int64(in.Int64())
+		case "posts":
+			out.Posts = int64(in.Int64())
+		case "votes":
+			out.Votes = int64(in.Int64())
+		case "votesDropped":
+			out.VotesDropped = int64(in.Int64())
+		case "postVotes":
+			out.PostVotes = int64(in.Int64())
+		case "postVotesDropped":
+			out.PostVotesDropped = int64(in.Int64())
+		case "forumUsers":
+			out.ForumUsers = int64(in.Int64())
+		case "merged":
+			out.Merged = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels3(out *jwriter.Writer, in UserMerge) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"source\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Source))
+	}
+	{
+		const prefix string = ",\"target\":"
+		out.RawString(prefix)
+		out.String(string(in.Target))
+	}
+	{
+		const prefix string = ",\"forums\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Forums))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Posts))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Votes))
+	}
+	{
+		const prefix string = ",\"votesDropped\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.VotesDropped))
+	}
+	{
+		const prefix string = ",\"postVotes\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.PostVotes))
+	}
+	{
+		const prefix string = ",\"postVotesDropped\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.PostVotesDropped))
+	}
+	{
+		const prefix string = ",\"forumUsers\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ForumUsers))
+	}
+	{
+		const prefix string = ",\"merged\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Merged))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserMerge) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserMerge) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserMerge) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserMerge) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels3(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels4(in *jlexer.Lexer, out *UserForums) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(UserForums, 0, 1)
+			} else {
+				*out = UserForums{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 UserForum
+			(v4).UnmarshalEasyJSON(in)
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels4(out *jwriter.Writer, in UserForums) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			(v6).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserForums) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserForums) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserForums) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserForums) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels4(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels5(in *jlexer.Lexer, out *UserForum) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "user":
+			out.User = string(in.String())
+		case "posts":
+			out.Posts = int32(in.Int32())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels5(out *jwriter.Writer, in UserForum) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		out.String(string(in.User))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Posts))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserForum) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserForum) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserForum) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserForum) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels5(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels6(in *jlexer.Lexer, out *UserExport) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "user":
+			if in.IsNull() {
+				in.Skip()
+				out.User = nil
+			} else {
+				if out.User == nil {
+					out.User = new(User)
+				}
+				(*out.User).UnmarshalEasyJSON(in)
+			}
+		case "threads":
+			(out.Threads).UnmarshalEasyJSON(in)
+		case "posts":
+			(out.Posts).UnmarshalEasyJSON(in)
+		case "votes":
+			(out.Votes).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels6(out *jwriter.Writer, in UserExport) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix[1:])
+		if in.User == nil {
+			out.RawString("null")
+		} else {
+			(*in.User).MarshalEasyJSON(out)
+		}
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		(in.Threads).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		(in.Posts).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		(in.Votes).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserExport) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserExport) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserExport) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserExport) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels6(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels7(in *jlexer.Lexer, out *User) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			out.Fullname = string(in.String())
+		case "about":
+			out.About = string(in.String())
+		case "email":
+			out.Email = string(in.String())
+		case "reputation":
+			out.Reputation = int32(in.Int32())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels7(out *jwriter.Writer, in User) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix)
+		out.String(string(in.Fullname))
+	}
+	{
+		const prefix string = ",\"about\":"
+		out.RawString(prefix)
+		out.String(string(in.About))
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		out.String(string(in.Email))
+	}
+	{
+		const prefix string = ",\"reputation\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Reputation))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v User) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v User) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *User) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels7(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels8(in *jlexer.Lexer, out *Leaderboard) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Leaderboard, 0, 1)
+			} else {
+				*out = Leaderboard{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v7 Leader
+			(v7).UnmarshalEasyJSON(in)
+			*out = append(*out, v7)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels8(out *jwriter.Writer, in Leaderboard) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v8, v9 := range in {
+			if v8 > 0 {
+				out.RawByte(',')
+			}
+			(v9).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Leaderboard) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Leaderboard) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Leaderboard) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Leaderboard) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels8(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels9(in *jlexer.Lexer, out *Leader) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			out.Fullname = string(in.String())
+		case "reputation":
+			out.Reputation = int32(in.Int32())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels9(out *jwriter.Writer, in Leader) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix)
+		out.String(string(in.Fullname))
+	}
+	{
+		const prefix string = ",\"reputation\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Reputation))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Leader) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Leader) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Leader) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Leader) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels9(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels10(in *jlexer.Lexer, out *ForumUsers) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ForumUsers, 0, 0)
+			} else {
+				*out = ForumUsers{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v10 ForumUser
+			(v10).UnmarshalEasyJSON(in)
+			*out = append(*out, v10)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeDbForumAppModels10(out *jwriter.Writer, in ForumUsers) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v11, v12 := range in {
+			if v11 > 0 {
+				out.RawByte(',')
+			}
+			(v12).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumUsers) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeDbForumAppModels10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumUsers) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumUsers) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeDbForumAppModels10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumUsers) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels10(l, v)
+}
+func easyjson9e1087fdDecodeDbForumAppModels11(in *jlexer.Lexer, out *ForumUser) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -190,6 +950,20 @@ func easyjson9e1087fdDecodeDbForumAppModels2(in *jlexer.Lexer, out *User) {
 			out.About = string(in.String())
 		case "email":
 			out.Email = string(in.String())
+		case "reputation":
+			out.Reputation = int32(in.Int32())
+		case "threads":
+			out.Threads = int32(in.Int32())
+		case "posts":
+			out.Posts = int32(in.Int32())
+		case "joined":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Joined).UnmarshalJSON(data))
+			}
+		case "lastActive":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.LastActive).UnmarshalJSON(data))
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -200,7 +974,7 @@ func easyjson9e1087fdDecodeDbForumAppModels2(in *jlexer.Lexer, out *User) {
 		in.Consumed()
 	}
 }
-func easyjson9e1087fdEncodeDbForumAppModels2(out *jwriter.Writer, in User) {
+func easyjson9e1087fdEncodeDbForumAppModels11(out *jwriter.Writer, in ForumUser) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -224,29 +998,54 @@ func easyjson9e1087fdEncodeDbForumAppModels2(out *jwriter.Writer, in User) {
 		out.RawString(prefix)
 		out.String(string(in.Email))
 	}
+	{
+		const prefix string = ",\"reputation\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Reputation))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Posts))
+	}
+	{
+		const prefix string = ",\"joined\":"
+		out.RawString(prefix)
+		out.Raw((in.Joined).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"lastActive\":"
+		out.RawString(prefix)
+		out.Raw((in.LastActive).MarshalJSON())
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v User) MarshalJSON() ([]byte, error) {
+func (v ForumUser) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9e1087fdEncodeDbForumAppModels2(&w, v)
+	easyjson9e1087fdEncodeDbForumAppModels11(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v User) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9e1087fdEncodeDbForumAppModels2(w, v)
+func (v ForumUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeDbForumAppModels11(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *User) UnmarshalJSON(data []byte) error {
+func (v *ForumUser) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9e1087fdDecodeDbForumAppModels2(&r, v)
+	easyjson9e1087fdDecodeDbForumAppModels11(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9e1087fdDecodeDbForumAppModels2(l, v)
+func (v *ForumUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeDbForumAppModels11(l, v)
 }
@@ -1,6 +1,22 @@
 package models
 
 type Vote struct {
+	Nickname string `json:"nickname" validate:"required"`
+	Voice    int32  `json:"voice" validate:"oneof=-1 1"`
+}
+
+type Voter struct {
 	Nickname string `json:"nickname"`
 	Voice    int32  `json:"voice"`
 }
+
+// ThreadVoters is a page of the voters of a thread. The counts are over all
+// of them: Votes is the sum, Upvotes and Downvotes split it.
+//
+//easyjson:json
+type ThreadVoters struct {
+	Votes     int32   `json:"votes"`
+	Upvotes   int32   `json:"upvotes"`
+	Downvotes int32   `json:"downvotes"`
+	Voters    []Voter `json:"voters"`
+}
@@ -17,7 +17,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonE3ecfa40DecodeDbForumAppModels(in *jlexer.Lexer, out *Vote) {
+func easyjsonE3ecfa40DecodeDbForumAppModels(in *jlexer.Lexer, out *Voter) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -50,7 +50,7 @@ func easyjsonE3ecfa40DecodeDbForumAppModels(in *jlexer.Lexer, out *Vote) {
 		in.Consumed()
 	}
 }
-func easyjsonE3ecfa40EncodeDbForumAppModels(out *jwriter.Writer, in Vote) {
+func easyjsonE3ecfa40EncodeDbForumAppModels(out *jwriter.Writer, in Voter) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -68,25 +68,217 @@ func easyjsonE3ecfa40EncodeDbForumAppModels(out *jwriter.Writer, in Vote) {
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Vote) MarshalJSON() ([]byte, error) {
+func (v Voter) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonE3ecfa40EncodeDbForumAppModels(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Vote) MarshalEasyJSON(w *jwriter.Writer) {
+func (v Voter) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonE3ecfa40EncodeDbForumAppModels(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Vote) UnmarshalJSON(data []byte) error {
+func (v *Voter) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonE3ecfa40DecodeDbForumAppModels(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *Voter) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonE3ecfa40DecodeDbForumAppModels(l, v)
 }
+func easyjsonE3ecfa40DecodeDbForumAppModels1(in *jlexer.Lexer, out *Vote) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "voice":
+			out.Voice = int32(in.Int32())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ecfa40EncodeDbForumAppModels1(out *jwriter.Writer, in Vote) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Voice))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Vote) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ecfa40EncodeDbForumAppModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Vote) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ecfa40EncodeDbForumAppModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Vote) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ecfa40DecodeDbForumAppModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ecfa40DecodeDbForumAppModels1(l, v)
+}
+func easyjsonE3ecfa40DecodeDbForumAppModels2(in *jlexer.Lexer, out *ThreadVoters) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "votes":
+			out.Votes = int32(in.Int32())
+		case "upvotes":
+			out.Upvotes = int32(in.Int32())
+		case "downvotes":
+			out.Downvotes = int32(in.Int32())
+		case "voters":
+			if in.IsNull() {
+				in.Skip()
+				out.Voters = nil
+			} else {
+				in.Delim('[')
+				if out.Voters == nil {
+					if !in.IsDelim(']') {
+						out.Voters = make([]Voter, 0, 2)
+					} else {
+						out.Voters = []Voter{}
+					}
+				} else {
+					out.Voters = (out.Voters)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 Voter
+					(v1).UnmarshalEasyJSON(in)
+					out.Voters = append(out.Voters, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ecfa40EncodeDbForumAppModels2(out *jwriter.Writer, in ThreadVoters) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix[1:])
+		out.Int32(int32(in.Votes))
+	}
+	{
+		const prefix string = ",\"upvotes\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Upvotes))
+	}
+	{
+		const prefix string = ",\"downvotes\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Downvotes))
+	}
+	{
+		const prefix string = ",\"voters\":"
+		out.RawString(prefix)
+		if in.Voters == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Voters {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadVoters) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ecfa40EncodeDbForumAppModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadVoters) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ecfa40EncodeDbForumAppModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadVoters) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ecfa40DecodeDbForumAppModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadVoters) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ecfa40DecodeDbForumAppModels2(l, v)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+//easyjson:json
+type Webhooks []Webhook
+
+//easyjson:json
+type Webhook struct {
+	Id      int64     `json:"id"`
+	Forum   string    `json:"forum"`
+	Url     string    `json:"url" validate:"required,url"`
+	Secret  string    `json:"secret,omitempty"`
+	Events  []string  `json:"events" validate:"required,min=1,dive,oneof=thread.created post.created vote.changed"`
+	Created time.Time `json:"created"`
+}
+
+//easyjson:json
+type WebhookDeliveries []WebhookDelivery
+
+//easyjson:json
+type WebhookDelivery struct {
+	Id           int64               `json:"id"`
+	Webhook      int64               `json:"webhook"`
+	Event        string              `json:"event"`
+	Payload      easyjson.RawMessage `json:"payload"`
+	Status       string              `json:"status"`
+	Attempts     int32               `json:"attempts"`
+	ResponseCode int32               `json:"responseCode,omitempty"`
+	LastError    string              `json:"lastError,omitempty"`
+	Created      time.Time           `json:"created"`
+	NextAttempt  time.Time           `json:"nextAttempt"`
+	Delivered    *time.Time          `json:"delivered,omitempty"`
+	Url          string              `json:"-"`
+	Secret       string              `json:"-"`
+}
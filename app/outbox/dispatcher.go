@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"fmt"
+	"time"
+)
+
+const (
+	batchSize   = 100
+	lease       = time.Minute
+	baseBackoff = time.Second
+	maxBackoff  = 10 * time.Minute
+	retention   = 7 * 24 * time.Hour
+	cleanupTick = time.Hour
+)
+
+// Sink receives every outbox event at least once, so it has to tolerate
+// duplicates, e.g. by remembering the event id.
+type Sink interface {
+	Name() string
+	Publish(event *models.OutboxEvent) error
+}
+
+// Dispatcher publishes the events written to the outbox table by the
+// mutation triggers. An event is retried on every sink until all succeed.
+type Dispatcher struct {
+	repoOutbox repositories.OutboxRepository
+	sinks      []Sink
+}
+
+func MakeDispatcher(outbox repositories.OutboxRepository, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{repoOutbox: outbox, sinks: sinks}
+}
+
+func (dispatcher *Dispatcher) Run(interval time.Duration) {
+	cleanup := time.NewTicker(cleanupTick)
+	defer cleanup.Stop()
+	for {
+		claimed, err := dispatcher.PublishDue()
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		select {
+		case <-cleanup.C:
+			if err = dispatcher.repoOutbox.DeletePublished(retention); err != nil {
+				fmt.Println(err)
+			}
+		default:
+		}
+
+		if claimed < batchSize {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// PublishDue publishes one batch of due events and returns how many were claimed.
+func (dispatcher *Dispatcher) PublishDue() (int, error) {
+	events, err := dispatcher.repoOutbox.ClaimEvents(batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *events {
+		event := &(*events)[i]
+		err = dispatcher.publish(event)
+		if err != nil {
+			err = dispatcher.repoOutbox.MarkFailed(event.Id, err.Error(), Backoff(int(event.Attempts)))
+		} else {
+			err = dispatcher.repoOutbox.MarkPublished(event.Id)
+		}
+		if err != nil {
+			return len(*events), err
+		}
+	}
+	return len(*events), nil
+}
+
+func (dispatcher *Dispatcher) publish(event *models.OutboxEvent) error {
+	for _, sink := range dispatcher.sinks {
+		if err := sink.Publish(event); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Backoff is the delay before the next attempt: 1s, 2s, 4s... capped at 10m.
+func Backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
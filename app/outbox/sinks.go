@@ -0,0 +1,18 @@
+package outbox
+
+import (
+	"db_forum/app/models"
+	"fmt"
+)
+
+// LogSink prints every event, useful while developing a new consumer.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Publish(event *models.OutboxEvent) error {
+	fmt.Printf("outbox event %d %s forum=%q %s\n", event.Id, event.Type, event.Forum, event.Payload)
+	return nil
+}
@@ -0,0 +1,36 @@
+package reconcile
+
+import (
+	"db_forum/app/repositories"
+	"fmt"
+	"time"
+)
+
+// Job reconciles the counters on a schedule and prints what it found. It only
+// reports unless repair is set.
+type Job struct {
+	repoService repositories.ServiceRepository
+	repair      bool
+}
+
+func MakeJob(service repositories.ServiceRepository, repair bool) *Job {
+	return &Job{repoService: service, repair: repair}
+}
+
+func (job *Job) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		reconciliation, err := job.repoService.Reconcile(job.repair)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, drift := range reconciliation.Drifts {
+			subject := drift.Forum
+			if drift.Thread != 0 {
+				subject = fmt.Sprint(drift.Thread)
+			}
+			fmt.Printf("reconcile: %s of %s is %d, counted %d (repaired: %t)\n",
+				drift.Counter, subject, drift.Stored, drift.Actual, reconciliation.Repaired)
+		}
+	}
+}
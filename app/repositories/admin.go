@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"db_forum/app/models"
+	"db_forum/db"
+	"db_forum/pkg"
+	"db_forum/pkg/queries"
+
+	"github.com/jackc/pgx"
+)
+
+// AdminRepository holds the maintenance operations of forumctl that the API
+// does not offer.
+type AdminRepository interface {
+	Migrate() (err error)
+	DeleteUser(nickname string) (err error)
+	MergeUsers(source, target string, dryRun bool) (merge *models.UserMerge, err error)
+	DeleteForum(slug string) (err error)
+	DeleteThread(id int64) (err error)
+}
+
+type AdminRepositoryImpl struct {
+	db *pgx.ConnPool
+}
+
+func MakeAdminRepository(db *pgx.ConnPool) AdminRepository {
+	return &AdminRepositoryImpl{db: db}
+}
+
+func (adminRepository *AdminRepositoryImpl) Migrate() (err error) {
+	_, err = adminRepository.db.Exec(db.Schema)
+	return
+}
+
+// DeleteUser deletes a user that owns no forums, threads or posts, along with
+// the votes they cast on threads and posts.
+func (adminRepository *AdminRepositoryImpl) DeleteUser(nickname string) (err error) {
+	tx, err := adminRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var hasContent bool
+	if err = tx.QueryRow(queries.AdminUserHasContent, nickname).Scan(&hasContent); err != nil {
+		return
+	}
+	if hasContent {
+		return pkg.ErrUserHasContent
+	}
+	for _, query := range []string{queries.AdminDeleteUserVotes, queries.AdminDeleteUserScores, queries.AdminDeleteUserForums, queries.UserDeleteStats} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return
+		}
+	}
+	commandTag, err := tx.Exec(queries.AdminDeleteUser, nickname)
+	if err == nil && commandTag.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+	}
+	return
+}
+
+// MergeUsers moves the forums, threads, posts, votes, reputation and
+// user_forum rows of source over to target and deletes source, whose profile
+// then redirects to target. Where both voted for the same thread or post the
+// vote of target is kept. With dryRun only the counts are returned.
+func (adminRepository *AdminRepositoryImpl) MergeUsers(source, target string, dryRun bool) (merge *models.UserMerge, err error) {
+	tx, err := adminRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil || dryRun {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var locked int
+	if err = tx.QueryRow(queries.AdminMergeLock, source, target).Scan(&locked); err != nil {
+		return
+	}
+	if locked != 2 {
+		return nil, pgx.ErrNoRows
+	}
+	merge = &models.UserMerge{Source: source, Target: target, Merged: !dryRun}
+	err = tx.QueryRow(queries.AdminMergePreview, source, target).Scan(&merge.Forums, &merge.Threads, &merge.Posts,
+		&merge.Votes, &merge.VotesDropped, &merge.PostVotes, &merge.PostVotesDropped, &merge.ForumUsers)
+	if err != nil || dryRun {
+		return
+	}
+
+	// the dropped votes go first, so that the triggers take them back from
+	// the authors before the reputation of source moves
+	for _, query := range []string{queries.AdminMergeDropVotes, queries.AdminMergeDropScores, queries.AdminMergeVotes, queries.AdminMergeScores,
+		queries.UserMoveForums, queries.UserAddReputation, queries.UserMoveOwnForums, queries.UserMoveThreads, queries.UserMovePosts,
+		queries.UserMoveStats, queries.AdminMergeRedirects, queries.UserRedirectSave} {
+		if _, err = tx.Exec(query, source, target); err != nil {
+			return
+		}
+	}
+	for _, query := range []string{queries.UserDeleteForums, queries.UserDeleteStats, queries.UserDelete} {
+		if _, err = tx.Exec(query, source); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteForum deletes the forum with its threads, posts, votes and webhooks.
+func (adminRepository *AdminRepositoryImpl) DeleteForum(slug string) (err error) {
+	tx, err := adminRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, query := range []string{queries.AdminDeleteForumVotes, queries.AdminDeleteForumScores, queries.AdminDeleteForumPosts, queries.AdminDeleteForumThreads,
+		queries.AdminDeleteForumUsers, queries.AdminDeleteForumStats, queries.AdminDeleteForumStatsUsers, queries.AdminDeleteForumHooks} {
+		if _, err = tx.Exec(query, slug); err != nil {
+			return
+		}
+	}
+	commandTag, err := tx.Exec(queries.AdminDeleteForum, slug)
+	if err == nil && commandTag.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+	}
+	return
+}
+
+// DeleteThread deletes the thread with its posts and votes. The triggers only
+// count up, so the forum counters and user_forum with its activity are fixed here.
+func (adminRepository *AdminRepositoryImpl) DeleteThread(id int64) (err error) {
+	tx, err := adminRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, query := range []string{queries.AdminDeleteThreadVotes, queries.AdminDeleteThreadScores} {
+		if _, err = tx.Exec(query, id); err != nil {
+			return
+		}
+	}
+	commandTag, err := tx.Exec(queries.AdminDeleteThreadPosts, id)
+	if err != nil {
+		return
+	}
+	var forum string
+	if err = tx.QueryRow(queries.AdminDeleteThread, id).Scan(&forum); err != nil {
+		return
+	}
+	if _, err = tx.Exec(queries.AdminForumDiscount, forum, 1, commandTag.RowsAffected()); err != nil {
+		return
+	}
+	if _, err = tx.Exec(queries.AdminForumPruneUsers, forum); err != nil {
+		return
+	}
+	_, err = tx.Exec(queries.ServiceRepairForumActivity, forum)
+	return
+}
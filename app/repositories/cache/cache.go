@@ -0,0 +1,118 @@
+// Package cache wraps the forum, thread and user repositories with a
+// read-through cache. Entries are dropped by the writes that go through the
+// wrapped repositories; writes made elsewhere (another instance over the same
+// database, forumctl) are seen once the entries expire.
+package cache
+
+import (
+	"db_forum/app/models"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mailru/easyjson"
+)
+
+// Cache stores encoded entities by key. Errors of a cache are not fatal: a
+// failed Get is a miss, a failed Set or Delete is left to the expiry.
+type Cache interface {
+	Get(key string) (value []byte, ok bool, err error)
+	Set(key string, value []byte) error
+	Delete(keys ...string) error
+	Purge() error
+}
+
+func forumKey(slug string) string {
+	return "forum:" + strings.ToLower(slug)
+}
+
+func threadIdKey(id int64) string {
+	return "thread:id:" + strconv.FormatInt(id, 10)
+}
+
+func threadSlugKey(slug string) string {
+	return "thread:slug:" + strings.ToLower(slug)
+}
+
+func userKey(nickname string) string {
+	return "user:" + strings.ToLower(nickname)
+}
+
+var errShortEntry = errors.New("cache entry too short")
+
+// encode prefixes the JSON of the entity with its version, which the JSON
+// leaves out but the ETags need.
+func encode(version int64, entity easyjson.Marshaler) ([]byte, error) {
+	data, err := easyjson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(value, uint64(version))
+	return append(value, data...), nil
+}
+
+func decode(value []byte, entity easyjson.Unmarshaler) (version int64, err error) {
+	if len(value) < 8 {
+		return 0, errShortEntry
+	}
+	if err = easyjson.Unmarshal(value[8:], entity); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+func lookup(cache Cache, key string, entity easyjson.Unmarshaler) (version int64, ok bool) {
+	value, ok, err := cache.Get(key)
+	if err != nil || !ok {
+		return 0, false
+	}
+	version, err = decode(value, entity)
+	return version, err == nil
+}
+
+func store(cache Cache, key string, version int64, entity easyjson.Marshaler) {
+	if value, err := encode(version, entity); err == nil {
+		_ = cache.Set(key, value)
+	}
+}
+
+type counters struct {
+	hits   int64
+	misses int64
+}
+
+func (counters *counters) hit() {
+	atomic.AddInt64(&counters.hits, 1)
+}
+
+func (counters *counters) miss() {
+	atomic.AddInt64(&counters.misses, 1)
+}
+
+func (counters *counters) load() models.CacheCounters {
+	return models.CacheCounters{Hits: atomic.LoadInt64(&counters.hits), Misses: atomic.LoadInt64(&counters.misses)}
+}
+
+// Metrics counts the hits and misses of the wrapped repositories.
+type Metrics struct {
+	backend string
+	forum   counters
+	thread  counters
+	user    counters
+}
+
+func MakeMetrics(backend string) *Metrics {
+	return &Metrics{backend: backend}
+}
+
+func (metrics *Metrics) Stats() *models.CacheStats {
+	return &models.CacheStats{
+		Backend: metrics.backend,
+		Forum:   metrics.forum.load(),
+		Thread:  metrics.thread.load(),
+		User:    metrics.user.load(),
+	}
+}
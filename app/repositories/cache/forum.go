@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+type ForumRepository struct {
+	repositories.ForumRepository
+	cache   Cache
+	metrics *Metrics
+}
+
+func MakeForumRepository(forumRepository repositories.ForumRepository, cache Cache, metrics *Metrics) repositories.ForumRepository {
+	return &ForumRepository{ForumRepository: forumRepository, cache: cache, metrics: metrics}
+}
+
+func (forumRepository *ForumRepository) GetInfoAboutForum(slug string) (*models.Forum, error) {
+	key := forumKey(slug)
+	forum := new(models.Forum)
+	if version, ok := lookup(forumRepository.cache, key, forum); ok {
+		forumRepository.metrics.forum.hit()
+		forum.Version = version
+		return forum, nil
+	}
+	forumRepository.metrics.forum.miss()
+
+	forum, err := forumRepository.ForumRepository.GetInfoAboutForum(slug)
+	if err == nil {
+		store(forumRepository.cache, key, forum.Version, forum)
+	}
+	return forum, err
+}
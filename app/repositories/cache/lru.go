@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU keeps at most size entries in the process, each for at most ttl.
+type LRU struct {
+	mutex   sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func MakeLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{size: size, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (lru *LRU) Get(key string) ([]byte, bool, error) {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	element, ok := lru.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if lru.ttl > 0 && time.Now().After(entry.expires) {
+		lru.order.Remove(element)
+		delete(lru.entries, key)
+		return nil, false, nil
+	}
+	lru.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (lru *LRU) Set(key string, value []byte) error {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	expires := time.Now().Add(lru.ttl)
+	if element, ok := lru.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		lru.order.MoveToFront(element)
+		return nil
+	}
+	lru.entries[key] = lru.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for lru.order.Len() > lru.size {
+		oldest := lru.order.Back()
+		lru.order.Remove(oldest)
+		delete(lru.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+func (lru *LRU) Delete(keys ...string) error {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := lru.entries[key]; ok {
+			lru.order.Remove(element)
+			delete(lru.entries, key)
+		}
+	}
+	return nil
+}
+
+func (lru *LRU) Purge() error {
+	lru.mutex.Lock()
+	defer lru.mutex.Unlock()
+
+	lru.order.Init()
+	lru.entries = make(map[string]*list.Element)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const redisPrefix = "db_forum:"
+
+// Redis shares the cache between the instances of the forum. Its keys are
+// prefixed with db_forum: so Purge leaves the rest of the server alone.
+type Redis struct {
+	pool *redis.Pool
+	ttl  time.Duration
+}
+
+func MakeRedis(address string, ttl time.Duration) *Redis {
+	return &Redis{
+		pool: &redis.Pool{
+			MaxIdle:     16,
+			IdleTimeout: time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", address,
+					redis.DialConnectTimeout(time.Second), redis.DialReadTimeout(time.Second), redis.DialWriteTimeout(time.Second))
+			},
+		},
+		ttl: ttl,
+	}
+}
+
+func (cache *Redis) Get(key string) ([]byte, bool, error) {
+	conn := cache.pool.Get()
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", redisPrefix+key))
+	if err == redis.ErrNil {
+		return nil, false, nil
+	}
+	return value, err == nil, err
+}
+
+func (cache *Redis) Set(key string, value []byte) error {
+	conn := cache.pool.Get()
+	defer conn.Close()
+
+	var err error
+	if cache.ttl > 0 {
+		_, err = conn.Do("SET", redisPrefix+key, value, "PX", cache.ttl.Milliseconds())
+	} else {
+		_, err = conn.Do("SET", redisPrefix+key, value)
+	}
+	return err
+}
+
+func (cache *Redis) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	conn := cache.pool.Get()
+	defer conn.Close()
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = redisPrefix + key
+	}
+	_, err := conn.Do("DEL", args...)
+	return err
+}
+
+func (cache *Redis) Purge() error {
+	conn := cache.pool.Get()
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", redisPrefix+"*", "COUNT", 1000))
+		if err != nil {
+			return err
+		}
+		var keys []interface{}
+		if _, err = redis.Scan(reply, &cursor, &keys); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if _, err = conn.Do("DEL", keys...); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func (cache *Redis) Close() error {
+	return cache.pool.Close()
+}
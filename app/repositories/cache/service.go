@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+// ServiceRepository empties the cache when the database is cleared or its
+// counters are repaired.
+type ServiceRepository struct {
+	repositories.ServiceRepository
+	cache Cache
+}
+
+func MakeServiceRepository(serviceRepository repositories.ServiceRepository, cache Cache) repositories.ServiceRepository {
+	return &ServiceRepository{ServiceRepository: serviceRepository, cache: cache}
+}
+
+func (serviceRepository *ServiceRepository) ClearService() error {
+	err := serviceRepository.ServiceRepository.ClearService()
+	_ = serviceRepository.cache.Purge()
+	return err
+}
+
+func (serviceRepository *ServiceRepository) Reconcile(repair bool) (*models.Reconciliation, error) {
+	reconciliation, err := serviceRepository.ServiceRepository.Reconcile(repair)
+	if repair {
+		_ = serviceRepository.cache.Purge()
+	}
+	return reconciliation, err
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"strconv"
+)
+
+// ThreadRepository caches threads by id. The slug of a thread never changes,
+// so the slug entries only hold the id.
+type ThreadRepository struct {
+	repositories.ThreadRepository
+	cache   Cache
+	metrics *Metrics
+}
+
+func MakeThreadRepository(threadRepository repositories.ThreadRepository, cache Cache, metrics *Metrics) repositories.ThreadRepository {
+	return &ThreadRepository{ThreadRepository: threadRepository, cache: cache, metrics: metrics}
+}
+
+func (threadRepository *ThreadRepository) GetById(id int64) (*models.Thread, error) {
+	if thread, ok := threadRepository.cached(id); ok {
+		threadRepository.metrics.thread.hit()
+		return thread, nil
+	}
+	threadRepository.metrics.thread.miss()
+
+	thread, err := threadRepository.ThreadRepository.GetById(id)
+	if err == nil {
+		store(threadRepository.cache, threadIdKey(thread.Id), thread.Version, thread)
+	}
+	return thread, err
+}
+
+func (threadRepository *ThreadRepository) GetBySlug(slug string) (*models.Thread, error) {
+	// threads created without a slug all have an empty one
+	if slug == "" {
+		return threadRepository.ThreadRepository.GetBySlug(slug)
+	}
+	key := threadSlugKey(slug)
+	if value, ok, err := threadRepository.cache.Get(key); err == nil && ok {
+		if id, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			if thread, ok := threadRepository.cached(id); ok {
+				threadRepository.metrics.thread.hit()
+				return thread, nil
+			}
+		}
+	}
+	threadRepository.metrics.thread.miss()
+
+	thread, err := threadRepository.ThreadRepository.GetBySlug(slug)
+	if err == nil {
+		store(threadRepository.cache, threadIdKey(thread.Id), thread.Version, thread)
+		_ = threadRepository.cache.Set(key, []byte(strconv.FormatInt(thread.Id, 10)))
+	}
+	return thread, err
+}
+
+func (threadRepository *ThreadRepository) cached(id int64) (*models.Thread, bool) {
+	thread := new(models.Thread)
+	version, ok := lookup(threadRepository.cache, threadIdKey(id), thread)
+	thread.Version = version
+	return thread, ok
+}
+
+func (threadRepository *ThreadRepository) UpdateThread(thread *models.Thread) error {
+	err := threadRepository.ThreadRepository.UpdateThread(thread)
+	_ = threadRepository.cache.Delete(threadIdKey(thread.Id))
+	return err
+}
+
+// CreateThread and CreateThreadPosts change the counters of the forum.
+func (threadRepository *ThreadRepository) CreateThread(thread *models.Thread) error {
+	err := threadRepository.ThreadRepository.CreateThread(thread)
+	_ = threadRepository.cache.Delete(forumKey(thread.Forum))
+	return err
+}
+
+func (threadRepository *ThreadRepository) CreateThreadPosts(thread *models.Thread, posts *models.Posts) error {
+	err := threadRepository.ThreadRepository.CreateThreadPosts(thread, posts)
+	_ = threadRepository.cache.Delete(forumKey(thread.Forum))
+	return err
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"time"
+)
+
+type UserRepository struct {
+	repositories.UserRepository
+	cache   Cache
+	metrics *Metrics
+}
+
+func MakeUserRepository(userRepository repositories.UserRepository, cache Cache, metrics *Metrics) repositories.UserRepository {
+	return &UserRepository{UserRepository: userRepository, cache: cache, metrics: metrics}
+}
+
+func (userRepository *UserRepository) GetInfoAboutUser(nickname string) (*models.User, error) {
+	key := userKey(nickname)
+	user := new(models.User)
+	if version, ok := lookup(userRepository.cache, key, user); ok {
+		userRepository.metrics.user.hit()
+		user.Version = version
+		return user, nil
+	}
+	userRepository.metrics.user.miss()
+
+	user, err := userRepository.UserRepository.GetInfoAboutUser(nickname)
+	if err == nil {
+		store(userRepository.cache, key, user.Version, user)
+	}
+	return user, err
+}
+
+func (userRepository *UserRepository) UpdateUser(user *models.User) error {
+	err := userRepository.UserRepository.UpdateUser(user)
+	_ = userRepository.cache.Delete(userKey(user.Nickname))
+	return err
+}
+
+// DeleteUser purges the cache: the cached forums and threads of the user now
+// belong to the tombstone account, and the votes they cast are gone.
+func (userRepository *UserRepository) DeleteUser(nickname string) error {
+	err := userRepository.UserRepository.DeleteUser(nickname)
+	_ = userRepository.cache.Purge()
+	return err
+}
+
+// RenameUser purges the cache like DeleteUser, the cached forums and threads
+// name the user.
+func (userRepository *UserRepository) RenameUser(user *models.User, nickname string, ttl time.Duration) error {
+	err := userRepository.UserRepository.RenameUser(user, nickname, ttl)
+	_ = userRepository.cache.Purge()
+	return err
+}
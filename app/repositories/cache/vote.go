@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+// VoteRepository drops the thread whose votes changed and the author whose
+// reputation did.
+type VoteRepository struct {
+	repositories.VoteRepository
+	cache Cache
+}
+
+func MakeVoteRepository(voteRepository repositories.VoteRepository, cache Cache) repositories.VoteRepository {
+	return &VoteRepository{VoteRepository: voteRepository, cache: cache}
+}
+
+func (voteRepository *VoteRepository) VoteForThread(thread *models.Thread, vote *models.Vote) error {
+	err := voteRepository.VoteRepository.VoteForThread(thread, vote)
+	_ = voteRepository.cache.Delete(threadIdKey(thread.Id), userKey(thread.Author))
+	return err
+}
+
+func (voteRepository *VoteRepository) DeleteVote(thread *models.Thread, nickname string) error {
+	err := voteRepository.VoteRepository.DeleteVote(thread, nickname)
+	_ = voteRepository.cache.Delete(threadIdKey(thread.Id), userKey(thread.Author))
+	return err
+}
+
+func (voteRepository *VoteRepository) VoteForPost(post *models.Post, vote *models.Vote) error {
+	err := voteRepository.VoteRepository.VoteForPost(post, vote)
+	_ = voteRepository.cache.Delete(userKey(post.Author))
+	return err
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+
+	"github.com/jackc/pgx"
+	_ "github.com/lib/pq"
+)
+
+type EventRepository interface {
+	ListenThreadEvents(events chan<- *models.ThreadEvent) error
+}
+
+type EventRepositoryImpl struct {
+	db *pgx.ConnPool
+}
+
+func MakeEventRepository(db *pgx.ConnPool) EventRepository {
+	return &EventRepositoryImpl{db: db}
+}
+
+// ListenThreadEvents holds a pool connection subscribed to the thread_events
+// channel and forwards notifications until the connection fails.
+func (eventRepository *EventRepositoryImpl) ListenThreadEvents(events chan<- *models.ThreadEvent) error {
+	conn, err := eventRepository.db.Acquire()
+	if err != nil {
+		return err
+	}
+	defer eventRepository.db.Release(conn)
+
+	err = conn.Listen(queries.ThreadEventsChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(context.Background())
+		if err != nil {
+			return err
+		}
+
+		event := new(models.ThreadEvent)
+		if err = event.UnmarshalJSON([]byte(notification.Payload)); err != nil {
+			continue
+		}
+		events <- event
+	}
+}
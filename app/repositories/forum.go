@@ -6,13 +6,16 @@ import (
 	"db_forum/pkg/queries"
 	"github.com/jackc/pgx"
 	_ "github.com/lib/pq"
+	"time"
 )
 
 type ForumRepository interface {
 	CreateForum(forum *models.Forum) (err error)
 	GetInfoAboutForum(slug string) (forum *models.Forum, err error)
-	GetForumUsers(slug string, limit int, since string, desc bool) (*[]models.User, error)
+	GetForumUsers(slug, order string, limit int, since string, desc bool) (*[]models.ForumUser, error)
+	GetForumLeaderboard(slug string, limit int) (*models.Leaderboard, error)
 	GetForumThreads(slug string, limit int, since string, desc bool) (threads *[]models.Thread, err error)
+	GetForumStats(slug, bucket string, from, to time.Time) (*models.ForumStats, error)
 }
 
 type ForumRepositoryImpl struct {
@@ -30,39 +33,64 @@ func (forumRepository *ForumRepositoryImpl) CreateForum(forum *models.Forum) (er
 
 func (forumRepository *ForumRepositoryImpl) GetInfoAboutForum(slug string) (forum *models.Forum, err error) {
 	forum = new(models.Forum)
-	err = forumRepository.db.QueryRow(queries.ForumGetBySlug, slug).Scan(&forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads)
+	err = forumRepository.db.QueryRow(queries.ForumGetBySlug, slug).Scan(&forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads, &forum.Version)
 	return forum, err
 }
 
-func (forumRepository *ForumRepositoryImpl) GetForumUsers(slug string, limit int, since string, desc bool) (*[]models.User, error) {
-	var query string
-
-	var result *pgx.Rows
-	var innerError error
+// GetForumUsers lists the participants of the forum with their activity in
+// it. order is "activity", "posts" or "joined", anything else sorts by
+// nickname; since is the nickname to continue after.
+func (forumRepository *ForumRepositoryImpl) GetForumUsers(slug, order string, limit int, since string, desc bool) (*[]models.ForumUser, error) {
+	tails := []string{queries.ForumGetUsers, queries.ForumGetUsersDesc, queries.ForumGetUsersSince, queries.ForumGetUsersSinceDesc}
+	switch order {
+	case "activity":
+		tails = []string{queries.ForumGetUsersActivity, queries.ForumGetUsersActivityDesc, queries.ForumGetUsersActivitySince, queries.ForumGetUsersActivitySinceDesc}
+	case "posts":
+		tails = []string{queries.ForumGetUsersPosts, queries.ForumGetUsersPostsDesc, queries.ForumGetUsersPostsSince, queries.ForumGetUsersPostsSinceDesc}
+	case "joined":
+		tails = []string{queries.ForumGetUsersJoined, queries.ForumGetUsersJoinedDesc, queries.ForumGetUsersJoinedSince, queries.ForumGetUsersJoinedSinceDesc}
+	}
 
+	var rows *pgx.Rows
+	var err error
 	if since != "" {
 		if desc {
-			query = queries.ForumGetUsersSinceDesc
+			rows, err = forumRepository.db.Query(queries.ForumGetUsersBase+tails[3], slug, since, limit)
 		} else {
-			query = queries.ForumGetUsersSince
-		}
-		result, innerError = forumRepository.db.Query(query, slug, since, limit)
-		if innerError != nil {
-			return nil, innerError
+			rows, err = forumRepository.db.Query(queries.ForumGetUsersBase+tails[2], slug, since, limit)
 		}
 	} else {
 		if desc {
-			query = queries.ForumGetUsersDesc
+			rows, err = forumRepository.db.Query(queries.ForumGetUsersBase+tails[1], slug, limit)
 		} else {
-			query = queries.ForumGetUsers
+			rows, err = forumRepository.db.Query(queries.ForumGetUsersBase+tails[0], slug, limit)
 		}
-		result, innerError = forumRepository.db.Query(query, slug, limit)
-		if innerError != nil {
-			return nil, innerError
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return handlerows.ForumUser(rows)
+}
+
+// GetForumLeaderboard lists the participants of the forum by the reputation
+// their threads and posts in it earned, user_forum keeps it per forum.
+func (forumRepository *ForumRepositoryImpl) GetForumLeaderboard(slug string, limit int) (*models.Leaderboard, error) {
+	rows, err := forumRepository.db.Query(queries.ForumGetLeaderboard, slug, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaderboard := models.Leaderboard{}
+	for rows.Next() {
+		leader := models.Leader{}
+		if err = rows.Scan(&leader.Nickname, &leader.Fullname, &leader.Reputation); err != nil {
+			return nil, err
 		}
+		leaderboard = append(leaderboard, leader)
 	}
-	defer result.Close()
-	return handlerows.User(result)
+	return &leaderboard, rows.Err()
 }
 
 func (forumRepository *ForumRepositoryImpl) GetForumThreads(slug string, limit int, since string, desc bool) (threads *[]models.Thread, err error) {
@@ -96,3 +124,23 @@ func (forumRepository *ForumRepositoryImpl) GetForumThreads(slug string, limit i
 	defer result.Close()
 	return handlerows.Thread(result)
 }
+
+// GetForumStats sums up the hours of forum_stats from from until to into the
+// buckets of an hour or a day, leaving out those without activity.
+func (forumRepository *ForumRepositoryImpl) GetForumStats(slug, bucket string, from, to time.Time) (*models.ForumStats, error) {
+	rows, err := forumRepository.db.Query(queries.ForumGetStats, slug, bucket, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := models.ForumStats{}
+	for rows.Next() {
+		stat := models.ForumStatsBucket{}
+		if err = rows.Scan(&stat.Start, &stat.Threads, &stat.Posts, &stat.Users, &stat.Votes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return &stats, rows.Err()
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"time"
+
+	"github.com/jackc/pgx"
+	_ "github.com/lib/pq"
+)
+
+type IdempotencyRepository interface {
+	GetResponse(key string, ttl time.Duration) (*models.IdempotentResponse, error)
+	ReserveKey(key, requestHash string, ttl, lease time.Duration) (reserved bool, err error)
+	SaveResponse(response *models.IdempotentResponse) error
+	ReleaseKey(key, requestHash string) error
+	DeleteExpired(ttl time.Duration) error
+}
+
+type IdempotencyRepositoryImpl struct {
+	db *pgx.ConnPool
+}
+
+func MakeIdempotencyRepository(db *pgx.ConnPool) IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{db: db}
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) GetResponse(key string, ttl time.Duration) (*models.IdempotentResponse, error) {
+	response := new(models.IdempotentResponse)
+	err := idempotencyRepository.db.QueryRow(queries.IdempotencyGet, key, ttl.Seconds()).
+		Scan(&response.Key, &response.RequestHash, &response.Status, &response.ContentType, &response.Body)
+	return response, err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReserveKey(key, requestHash string, ttl, lease time.Duration) (bool, error) {
+	var reservedKey string
+	err := idempotencyRepository.db.QueryRow(queries.IdempotencyReserve, key, requestHash, ttl.Seconds(), lease.Seconds()).Scan(&reservedKey)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) SaveResponse(response *models.IdempotentResponse) error {
+	_, err := idempotencyRepository.db.Exec(queries.IdempotencySave, response.Key, response.RequestHash, response.Status, response.ContentType, response.Body)
+	return err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReleaseKey(key, requestHash string) error {
+	_, err := idempotencyRepository.db.Exec(queries.IdempotencyRelease, key, requestHash)
+	return err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) DeleteExpired(ttl time.Duration) error {
+	_, err := idempotencyRepository.db.Exec(queries.IdempotencyDeleteExpired, ttl.Seconds())
+	return err
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+type EventRepositoryImpl struct {
+	store *Store
+}
+
+func MakeEventRepository(store *Store) repositories.EventRepository {
+	return &EventRepositoryImpl{store: store}
+}
+
+// ListenThreadEvents registers events as a listener of the store, which never
+// loses its connection, so it does not return.
+func (eventRepository *EventRepositoryImpl) ListenThreadEvents(events chan<- *models.ThreadEvent) error {
+	store := eventRepository.store
+	store.mutex.Lock()
+	store.listeners = append(store.listeners, events)
+	store.mutex.Unlock()
+
+	select {}
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"sort"
+	"time"
+)
+
+type ForumRepositoryImpl struct {
+	store *Store
+}
+
+func MakeForumRepository(store *Store) repositories.ForumRepository {
+	return &ForumRepositoryImpl{store: store}
+}
+
+func (forumRepository *ForumRepositoryImpl) CreateForum(forum *models.Forum) error {
+	store := forumRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.users[citext(forum.User)]; !ok {
+		return ErrForeignKeyViolation
+	}
+	if _, ok := store.forums[citext(forum.Slug)]; ok {
+		return ErrUniqueViolation
+	}
+
+	stored := models.Forum{Title: forum.Title, User: forum.User, Slug: forum.Slug, Version: 1}
+	store.forums[citext(forum.Slug)] = &stored
+	return nil
+}
+
+func (forumRepository *ForumRepositoryImpl) GetInfoAboutForum(slug string) (*models.Forum, error) {
+	store := forumRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	forum := new(models.Forum)
+	stored, ok := store.forums[citext(slug)]
+	if !ok {
+		return forum, ErrNotFound
+	}
+	*forum = *stored
+	return forum, nil
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumUsers(slug, order string, limit int, since string, desc bool) (*[]models.ForumUser, error) {
+	store := forumRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	// participants go by the key of the order and then by nickname, which is
+	// citext collate "C": compared lowercased, byte by byte.
+	forumActivity := store.activity[citext(slug)]
+	keyed := order == "activity" || order == "posts" || order == "joined"
+	less := func(a, b string) bool {
+		x, y := forumActivity[a], forumActivity[b]
+		switch {
+		case order == "activity" && !x.lastActive.Equal(y.lastActive):
+			return x.lastActive.Before(y.lastActive)
+		case order == "posts" && x.posts != y.posts:
+			return x.posts < y.posts
+		case order == "joined" && !x.joined.Equal(y.joined):
+			return x.joined.Before(y.joined)
+		}
+		return a < b
+	}
+
+	// like the queries, a since that is not a participant has no position
+	// in the other orders
+	if _, ok := forumActivity[citext(since)]; since != "" && keyed && !ok {
+		users := make([]models.ForumUser, 0)
+		return &users, nil
+	}
+	var nicknames []string
+	for nickname := range store.userForum[citext(slug)] {
+		if since != "" && ((!desc && !less(citext(since), nickname)) || (desc && !less(nickname, citext(since)))) {
+			continue
+		}
+		nicknames = append(nicknames, nickname)
+	}
+	sort.Slice(nicknames, func(i, j int) bool {
+		if desc {
+			return less(nicknames[j], nicknames[i])
+		}
+		return less(nicknames[i], nicknames[j])
+	})
+	if len(nicknames) > limit {
+		nicknames = nicknames[:limit]
+	}
+
+	users := make([]models.ForumUser, 0, len(nicknames))
+	for _, nickname := range nicknames {
+		user, activity := store.users[nickname], forumActivity[nickname]
+		users = append(users, models.ForumUser{Nickname: user.Nickname, Fullname: user.Fullname, About: user.About, Email: user.Email, Reputation: user.Reputation,
+			Threads: activity.threads, Posts: activity.posts, Joined: activity.joined, LastActive: activity.lastActive})
+	}
+	return &users, nil
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumLeaderboard(slug string, limit int) (*models.Leaderboard, error) {
+	store := forumRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	leaderboard := models.Leaderboard{}
+	for nickname := range store.userForum[citext(slug)] {
+		user := store.users[nickname]
+		leaderboard = append(leaderboard, models.Leader{Nickname: user.Nickname, Fullname: user.Fullname, Reputation: store.reputation[citext(slug)][nickname]})
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Reputation != leaderboard[j].Reputation {
+			return leaderboard[i].Reputation > leaderboard[j].Reputation
+		}
+		return citext(leaderboard[i].Nickname) < citext(leaderboard[j].Nickname)
+	})
+	if len(leaderboard) > limit {
+		leaderboard = leaderboard[:limit]
+	}
+	return &leaderboard, nil
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumThreads(slug string, limit int, since string, desc bool) (*[]models.Thread, error) {
+	store := forumRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var sinceTime time.Time
+	if since != "" {
+		var err error
+		sinceTime, err = time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var threads []models.Thread
+	for _, thread := range store.threads {
+		if citext(thread.Forum) != citext(slug) {
+			continue
+		}
+		if since != "" && ((!desc && thread.Created.Before(sinceTime)) || (desc && thread.Created.After(sinceTime))) {
+			continue
+		}
+		threads = append(threads, *thread)
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		if desc {
+			return threads[i].Created.After(threads[j].Created)
+		}
+		return threads[i].Created.Before(threads[j].Created)
+	})
+	if len(threads) > limit {
+		threads = threads[:limit]
+	}
+	return &threads, nil
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumStats(slug, bucket string, from, to time.Time) (*models.ForumStats, error) {
+	store := forumRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	// the hours are summed up into the buckets like forum_stats is by date_trunc
+	size := time.Hour
+	if bucket == "day" {
+		size = 24 * time.Hour
+	}
+	buckets := make(map[int64]*models.ForumStatsBucket)
+	users := make(map[int64]map[string]struct{})
+	for hour, stats := range store.stats[citext(slug)] {
+		created := time.Unix(hour, 0)
+		if created.Before(from) || !created.Before(to) {
+			continue
+		}
+		start := created.Truncate(size)
+		stat, ok := buckets[start.Unix()]
+		if !ok {
+			stat = &models.ForumStatsBucket{Start: start.UTC()}
+			buckets[start.Unix()] = stat
+			users[start.Unix()] = make(map[string]struct{})
+		}
+		stat.Threads += stats.threads
+		stat.Posts += stats.posts
+		stat.Votes += stats.votes
+		for nickname := range stats.users {
+			users[start.Unix()][nickname] = struct{}{}
+		}
+	}
+
+	result := make(models.ForumStats, 0, len(buckets))
+	for start, stat := range buckets {
+		stat.Users = int32(len(users[start]))
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return &result, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"time"
+)
+
+type idempotentResponse struct {
+	models.IdempotentResponse
+	created time.Time
+}
+
+type IdempotencyRepositoryImpl struct {
+	store *Store
+}
+
+func MakeIdempotencyRepository(store *Store) repositories.IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{store: store}
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) GetResponse(key string, ttl time.Duration) (*models.IdempotentResponse, error) {
+	store := idempotencyRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	response := new(models.IdempotentResponse)
+	stored, ok := store.idempotencyKeys[key]
+	if !ok || time.Since(stored.created) >= ttl {
+		return response, ErrNotFound
+	}
+	*response = stored.IdempotentResponse
+	return response, nil
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReserveKey(key, requestHash string, ttl, lease time.Duration) (bool, error) {
+	store := idempotencyRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if stored, ok := store.idempotencyKeys[key]; ok {
+		age := time.Since(stored.created)
+		if age < ttl && (stored.Status != 0 || age < lease) {
+			return false, nil
+		}
+	}
+	store.idempotencyKeys[key] = &idempotentResponse{
+		IdempotentResponse: models.IdempotentResponse{Key: key, RequestHash: requestHash},
+		created:            time.Now(),
+	}
+	return true, nil
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) SaveResponse(response *models.IdempotentResponse) error {
+	store := idempotencyRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if stored, ok := store.idempotencyKeys[response.Key]; ok && stored.RequestHash == response.RequestHash && stored.Status == 0 {
+		stored.Status = response.Status
+		stored.ContentType = response.ContentType
+		stored.Body = response.Body
+	}
+	return nil
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReleaseKey(key, requestHash string) error {
+	store := idempotencyRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if stored, ok := store.idempotencyKeys[key]; ok && stored.RequestHash == requestHash && stored.Status == 0 {
+		delete(store.idempotencyKeys, key)
+	}
+	return nil
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) DeleteExpired(ttl time.Duration) error {
+	store := idempotencyRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for key, stored := range store.idempotencyKeys {
+		if time.Since(stored.created) >= ttl {
+			delete(store.idempotencyKeys, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+)
+
+type PostRepositoryImpl struct {
+	store *Store
+}
+
+func MakePostRepository(store *Store) repositories.PostRepository {
+	return &PostRepositoryImpl{store: store}
+}
+
+func (postStore *PostRepositoryImpl) GetPost(id int64) (*models.Post, error) {
+	store := postStore.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	post := new(models.Post)
+	stored := store.post(id)
+	if stored == nil {
+		return post, ErrNotFound
+	}
+	*post = stored.Post
+	return post, nil
+}
+
+func (postStore *PostRepositoryImpl) UpdatePost(post *models.Post) error {
+	store := postStore.store
+	var events []*models.ThreadEvent
+	defer func() { store.publish(events) }()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored := store.post(post.Id)
+	if stored == nil || (post.Version != 0 && post.Version != stored.Version) {
+		return pkg.ErrPreconditionFailed
+	}
+	if stored.Message != post.Message {
+		events = append(events, &models.ThreadEvent{Type: "edit", Thread: stored.Thread, Id: stored.Id})
+	}
+	stored.Message = post.Message
+	stored.IsEdited = post.IsEdited
+	stored.Version++
+	post.Version = stored.Version
+	return nil
+}
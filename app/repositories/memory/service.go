@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"sort"
+)
+
+type ServiceRepositoryImpl struct {
+	store *Store
+}
+
+func MakeServiceRepository(store *Store) repositories.ServiceRepository {
+	return &ServiceRepositoryImpl{store: store}
+}
+
+func (serviceRepository *ServiceRepositoryImpl) ClearService() error {
+	store := serviceRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.clear()
+	return nil
+}
+
+func (serviceRepository *ServiceRepositoryImpl) GetService() (*models.Status, error) {
+	store := serviceRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return &models.Status{
+		User:   int32(len(store.users)),
+		Forum:  int32(len(store.forums)),
+		Thread: int32(len(store.threads)),
+		Post:   int64(len(store.posts)),
+	}, nil
+}
+
+// Reconcile checks the counters the repositories keep against the rows. The
+// store updates both under one lock, so drifts only follow bugs.
+func (serviceRepository *ServiceRepositoryImpl) Reconcile(repair bool) (*models.Reconciliation, error) {
+	store := serviceRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	reconciliation := &models.Reconciliation{Drifts: make([]models.CounterDrift, 0), Repaired: repair}
+
+	posts := make(map[string]int64)
+	threads := make(map[string]int32)
+	participants := make(map[string]map[string]struct{})
+	activities := make(map[string]map[string]*participation)
+	participate := func(nickname, forum string, activity participation) {
+		if participants[citext(forum)] == nil {
+			participants[citext(forum)] = make(map[string]struct{})
+			activities[citext(forum)] = make(map[string]*participation)
+		}
+		participants[citext(forum)][citext(nickname)] = struct{}{}
+		if stored, ok := activities[citext(forum)][citext(nickname)]; ok {
+			stored.merge(activity)
+		} else {
+			activities[citext(forum)][citext(nickname)] = &activity
+		}
+	}
+	for _, thread := range store.threads {
+		threads[citext(thread.Forum)]++
+		participate(thread.Author, thread.Forum, participation{threads: 1, joined: thread.Created, lastActive: thread.Created})
+	}
+	for _, post := range store.posts {
+		posts[citext(post.Forum)]++
+		participate(post.Author, post.Forum, participation{posts: 1, joined: post.created, lastActive: post.created})
+	}
+
+	slugs := make([]string, 0, len(store.forums))
+	for slug := range store.forums {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	for _, slug := range slugs {
+		forum := store.forums[slug]
+		if forum.Posts != posts[slug] {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumPosts, Forum: forum.Slug, Stored: forum.Posts, Actual: posts[slug]})
+		}
+		if forum.Threads != threads[slug] {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumThreads, Forum: forum.Slug, Stored: int64(forum.Threads), Actual: int64(threads[slug])})
+		}
+		if repair && (forum.Posts != posts[slug] || forum.Threads != threads[slug]) {
+			forum.Posts = posts[slug]
+			forum.Threads = threads[slug]
+			forum.Version++
+		}
+	}
+
+	votes := make(map[int64]int32)
+	for key, voice := range store.votes {
+		votes[key.thread] += voice
+	}
+	for _, thread := range store.threads {
+		if thread.Votes == votes[thread.Id] {
+			continue
+		}
+		reconciliation.Drifts = append(reconciliation.Drifts,
+			models.CounterDrift{Counter: models.CounterThreadVotes, Thread: thread.Id, Stored: int64(thread.Votes), Actual: int64(votes[thread.Id])})
+		if repair {
+			thread.Votes = votes[thread.Id]
+			thread.Version++
+		}
+	}
+
+	// the activity is compared before the repair of user_forum replaces it
+	activityDrifts := make([]models.CounterDrift, 0)
+	for _, slug := range slugs {
+		nicknames := make([]string, 0, len(store.activity[slug]))
+		for nickname := range store.activity[slug] {
+			if _, ok := activities[slug][nickname]; ok {
+				nicknames = append(nicknames, nickname)
+			}
+		}
+		sort.Strings(nicknames)
+		for _, nickname := range nicknames {
+			stored, actual := store.activity[slug][nickname], activities[slug][nickname]
+			for _, counter := range []struct {
+				name           string
+				stored, actual int64
+			}{
+				{models.CounterForumUserThreads, int64(stored.threads), int64(actual.threads)},
+				{models.CounterForumUserPosts, int64(stored.posts), int64(actual.posts)},
+				{models.CounterForumUserJoined, stored.joined.UnixMicro(), actual.joined.UnixMicro()},
+				{models.CounterForumUserLastActive, stored.lastActive.UnixMicro(), actual.lastActive.UnixMicro()},
+			} {
+				if counter.stored != counter.actual {
+					activityDrifts = append(activityDrifts, models.CounterDrift{Counter: counter.name, Forum: store.forums[slug].Slug,
+						Nickname: store.users[nickname].Nickname, Stored: counter.stored, Actual: counter.actual})
+				}
+			}
+			if repair {
+				*stored = *actual
+			}
+		}
+	}
+
+	for _, slug := range slugs {
+		if sameSet(store.userForum[slug], participants[slug]) {
+			continue
+		}
+		reconciliation.Drifts = append(reconciliation.Drifts, models.CounterDrift{Counter: models.CounterForumUsers, Forum: store.forums[slug].Slug,
+			Stored: int64(len(store.userForum[slug])), Actual: int64(len(participants[slug]))})
+		if repair && participants[slug] == nil {
+			delete(store.userForum, slug)
+			delete(store.activity, slug)
+		} else if repair {
+			store.userForum[slug] = participants[slug]
+			store.activity[slug] = activities[slug]
+		}
+	}
+
+	reconciliation.Drifts = append(reconciliation.Drifts, activityDrifts...)
+	return reconciliation, nil
+}
+
+func sameSet(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
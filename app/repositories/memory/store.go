@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+var (
+	ErrNotFound            = pgx.ErrNoRows
+	ErrUniqueViolation     = errors.New("duplicate key value violates unique constraint")
+	ErrForeignKeyViolation = errors.New("insert or update violates foreign key constraint")
+)
+
+type post struct {
+	models.Post
+	created time.Time
+	path    []int64
+	voters  int32
+}
+
+type voteKey struct {
+	thread   int64
+	nickname string
+}
+
+type postVoteKey struct {
+	post     int64
+	nickname string
+}
+
+// redirect is a row of user_redirects, target being the lowercased nickname.
+type redirect struct {
+	target  string
+	created time.Time
+}
+
+// Store holds the tables shared by the in-memory repositories. Keys of citext
+// columns are lowercased, and the side effects of the SQL triggers (user_forum,
+// counters, paths, versions) are applied by the repositories themselves.
+type Store struct {
+	mutex sync.RWMutex
+
+	users        map[string]*models.User
+	usersByEmail map[string]*models.User
+	forums       map[string]*models.Forum
+	threads      []*models.Thread
+	posts        []*post
+	threadPosts  map[int64][]*post
+	votes        map[voteKey]int32
+	postVotes    map[postVoteKey]int32
+	userForum    map[string]map[string]struct{}
+	reputation   map[string]map[string]int32
+	activity     map[string]map[string]*participation
+	stats        map[string]map[int64]*statsHour
+	redirects    map[string]*redirect
+
+	idempotencyKeys map[string]*idempotentResponse
+	listeners       []chan<- *models.ThreadEvent
+}
+
+func MakeStore() *Store {
+	store := &Store{}
+	store.clear()
+	return store
+}
+
+func (store *Store) clear() {
+	store.users = make(map[string]*models.User)
+	store.usersByEmail = make(map[string]*models.User)
+	store.forums = make(map[string]*models.Forum)
+	store.threads = nil
+	store.posts = nil
+	store.threadPosts = make(map[int64][]*post)
+	store.votes = make(map[voteKey]int32)
+	store.postVotes = make(map[postVoteKey]int32)
+	store.userForum = make(map[string]map[string]struct{})
+	store.reputation = make(map[string]map[string]int32)
+	store.activity = make(map[string]map[string]*participation)
+	store.stats = make(map[string]map[int64]*statsHour)
+	store.redirects = make(map[string]*redirect)
+	store.idempotencyKeys = make(map[string]*idempotentResponse)
+}
+
+func citext(value string) string {
+	return strings.ToLower(value)
+}
+
+func (store *Store) thread(id int64) *models.Thread {
+	if id < 1 || id > int64(len(store.threads)) {
+		return nil
+	}
+	return store.threads[id-1]
+}
+
+func (store *Store) post(id int64) *post {
+	if id < 1 || id > int64(len(store.posts)) {
+		return nil
+	}
+	return store.posts[id-1]
+}
+
+// participation is the activity of a user in a forum that user_forum keeps
+// along with the membership.
+type participation struct {
+	threads    int32
+	posts      int32
+	joined     time.Time
+	lastActive time.Time
+}
+
+// merge adds the threads and posts of other, like the upsert of create_user.
+func (activity *participation) merge(other participation) {
+	activity.threads += other.threads
+	activity.posts += other.posts
+	if activity.joined.IsZero() || other.joined.Before(activity.joined) {
+		activity.joined = other.joined
+	}
+	if other.lastActive.After(activity.lastActive) {
+		activity.lastActive = other.lastActive
+	}
+}
+
+// addUserToForum is the create_user trigger of threads and posts.
+func (store *Store) addUserToForum(nickname, forum string, activity participation) {
+	forumUsers, ok := store.userForum[citext(forum)]
+	if !ok {
+		forumUsers = make(map[string]struct{})
+		store.userForum[citext(forum)] = forumUsers
+	}
+	forumUsers[citext(nickname)] = struct{}{}
+
+	forumActivity, ok := store.activity[citext(forum)]
+	if !ok {
+		forumActivity = make(map[string]*participation)
+		store.activity[citext(forum)] = forumActivity
+	}
+	if stored, ok := forumActivity[citext(nickname)]; ok {
+		stored.merge(activity)
+	} else {
+		forumActivity[citext(nickname)] = &activity
+	}
+}
+
+// statsHour is a row of forum_stats with the users of forum_stats_users, by
+// the unix time of the hour.
+type statsHour struct {
+	threads int32
+	posts   int32
+	votes   int32
+	users   map[string]struct{}
+}
+
+// countActivity is the count_activity function of the triggers.
+func (store *Store) countActivity(forum, nickname string, created time.Time, threads, posts, votes int32) {
+	forumStats, ok := store.stats[citext(forum)]
+	if !ok {
+		forumStats = make(map[int64]*statsHour)
+		store.stats[citext(forum)] = forumStats
+	}
+	hour := created.Truncate(time.Hour).Unix()
+	stats, ok := forumStats[hour]
+	if !ok {
+		stats = &statsHour{users: make(map[string]struct{})}
+		forumStats[hour] = stats
+	}
+	stats.threads += threads
+	stats.posts += posts
+	stats.votes += votes
+	stats.users[citext(nickname)] = struct{}{}
+}
+
+// addReputation is the add_reputation function of the vote triggers: the
+// author gains delta in total and in the forum, by forum and nickname.
+func (store *Store) addReputation(nickname, forum string, delta int32) {
+	if delta == 0 {
+		return
+	}
+	if user, ok := store.users[citext(nickname)]; ok {
+		user.Reputation += delta
+		user.Version++
+	}
+	forumReputation, ok := store.reputation[citext(forum)]
+	if !ok {
+		forumReputation = make(map[string]int32)
+		store.reputation[citext(forum)] = forumReputation
+	}
+	forumReputation[citext(nickname)] += delta
+}
+
+// publish hands events to the listeners once the store is unlocked, like
+// NOTIFY delivers them only after the transaction commits.
+func (store *Store) publish(events []*models.ThreadEvent) {
+	store.mutex.RLock()
+	listeners := store.listeners
+	store.mutex.RUnlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener <- event
+		}
+	}
+}
+
+func comparePaths(a, b []int64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,297 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// makeThread fills the store with the users alice and bob, a forum of alice
+// and a thread of hers with posts 1 and 2 as roots, 3 replying to 1, 4 to 3
+// and 5 to 2.
+func makeThread(t *testing.T) (*Store, *models.Thread) {
+	store := MakeStore()
+	users, forums, threads := MakeUserRepository(store), MakeForumRepository(store), MakeThreadRepository(store)
+
+	for _, nickname := range []string{"alice", "bob"} {
+		if err := users.CreateUser(&models.User{Nickname: nickname, Fullname: nickname, Email: nickname + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := forums.CreateForum(&models.Forum{Title: "Forum", User: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	thread := &models.Thread{Title: "Thread", Author: "alice", Forum: "forum", Message: "Message"}
+	if err := threads.CreateThread(thread); err != nil {
+		t.Fatal(err)
+	}
+	for _, batch := range []models.Posts{
+		{{Author: "bob", Message: "1"}, {Author: "bob", Message: "2"}},
+		{{Author: "alice", Message: "3", Parent: 1}},
+		{{Author: "bob", Message: "4", Parent: 3}, {Author: "alice", Message: "5", Parent: 2}},
+	} {
+		if err := threads.CreateThreadPosts(thread, &batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, thread
+}
+
+func TestCreateUserConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		user models.User
+		want error
+	}{
+		{"new user", models.User{Nickname: "bob", Email: "bob@example.com"}, nil},
+		{"same nickname", models.User{Nickname: "alice", Email: "bob@example.com"}, ErrUniqueViolation},
+		{"nickname in another case", models.User{Nickname: "ALICE", Email: "bob@example.com"}, ErrUniqueViolation},
+		{"email in another case", models.User{Nickname: "bob", Email: "Alice@Example.com"}, ErrUniqueViolation},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := MakeUserRepository(MakeStore())
+			if err := users.CreateUser(&models.User{Nickname: "alice", Email: "alice@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := users.CreateUser(&test.user); err != test.want {
+				t.Fatalf("CreateUser() = %v, want %v", err, test.want)
+			}
+			if _, err := users.GetInfoAboutUser("Alice"); err != nil {
+				t.Fatalf("GetInfoAboutUser() = %v", err)
+			}
+		})
+	}
+}
+
+func TestMissingReferences(t *testing.T) {
+	store, thread := makeThread(t)
+	forums, threads := MakeForumRepository(store), MakeThreadRepository(store)
+	votes := MakeVoteRepository(store)
+	other := &models.Thread{Title: "Other", Author: "bob", Forum: "forum", Message: "Message"}
+	if err := threads.CreateThread(other); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		create func() error
+		want   error
+	}{
+		{"forum of a missing user", func() error {
+			return forums.CreateForum(&models.Forum{Title: "Forum", User: "carol", Slug: "other"})
+		}, ErrForeignKeyViolation},
+		{"forum with a taken slug", func() error {
+			return forums.CreateForum(&models.Forum{Title: "Forum", User: "bob", Slug: "FORUM"})
+		}, ErrUniqueViolation},
+		{"thread in a missing forum", func() error {
+			return threads.CreateThread(&models.Thread{Title: "Thread", Author: "alice", Forum: "missing", Message: "Message"})
+		}, ErrForeignKeyViolation},
+		{"thread of a missing user", func() error {
+			return threads.CreateThread(&models.Thread{Title: "Thread", Author: "carol", Forum: "forum", Message: "Message"})
+		}, ErrForeignKeyViolation},
+		{"post of a missing user", func() error {
+			return threads.CreateThreadPosts(thread, &models.Posts{{Author: "carol", Message: "Post"}})
+		}, pkg.ErrUserNotFound},
+		{"post with a missing parent", func() error {
+			return threads.CreateThreadPosts(thread, &models.Posts{{Author: "alice", Message: "Post", Parent: 99}})
+		}, pkg.ErrParentPostNotExist},
+		{"post with a parent in another thread", func() error {
+			return threads.CreateThreadPosts(other, &models.Posts{{Author: "alice", Message: "Post", Parent: 1}})
+		}, pkg.ErrParentPostFromOtherThread},
+		{"vote for a missing thread", func() error {
+			return votes.VoteForThread(&models.Thread{Id: 99}, &models.Vote{Nickname: "alice", Voice: 1})
+		}, pkg.ErrThreadNotFound},
+		{"vote of a missing user", func() error {
+			return votes.VoteForThread(thread, &models.Vote{Nickname: "carol", Voice: 1})
+		}, pkg.ErrUserNotFound},
+		{"vote for a missing post", func() error {
+			return votes.VoteForPost(&models.Post{Id: 99}, &models.Vote{Nickname: "alice", Voice: 1})
+		}, pkg.ErrPostNotFound},
+		{"post vote of a missing user", func() error {
+			return votes.VoteForPost(&models.Post{Id: 1}, &models.Vote{Nickname: "carol", Voice: 1})
+		}, pkg.ErrUserNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.create(); err != test.want {
+				t.Fatalf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+
+	status, err := MakeServiceRepository(store).GetService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *status != (models.Status{User: 2, Forum: 1, Thread: 2, Post: 5}) {
+		t.Errorf("failed writes changed the store: %+v", *status)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	store, thread := makeThread(t)
+	forums, votes := MakeForumRepository(store), MakeVoteRepository(store)
+
+	forum, err := forums.GetInfoAboutForum("FORUM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forum.Threads != 1 || forum.Posts != 5 {
+		t.Errorf("forum has %d threads and %d posts, want 1 and 5", forum.Threads, forum.Posts)
+	}
+
+	for _, step := range []struct {
+		nickname string
+		voice    int32
+		want     int32
+	}{
+		{"alice", 1, 1},
+		{"alice", 1, 1},
+		{"bob", -1, 0},
+		{"ALICE", -1, -2},
+		{"bob", 1, 0},
+	} {
+		voted := &models.Thread{Id: thread.Id}
+		if err = votes.VoteForThread(voted, &models.Vote{Nickname: step.nickname, Voice: step.voice}); err != nil {
+			t.Fatal(err)
+		}
+		if voted.Votes != step.want {
+			t.Fatalf("%s votes %d: votes = %d, want %d", step.nickname, step.voice, voted.Votes, step.want)
+		}
+	}
+
+	for _, order := range []struct {
+		order string
+		desc  bool
+		want  []models.ForumUser
+	}{
+		{"nickname", false, []models.ForumUser{{Nickname: "alice", Threads: 1, Posts: 2}, {Nickname: "bob", Posts: 3}}},
+		{"posts", true, []models.ForumUser{{Nickname: "bob", Posts: 3}, {Nickname: "alice", Threads: 1, Posts: 2}}},
+	} {
+		users, err := forums.GetForumUsers("forum", order.order, 10, "", order.desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]models.ForumUser, 0, len(*users))
+		for _, user := range *users {
+			got = append(got, models.ForumUser{Nickname: user.Nickname, Threads: user.Threads, Posts: user.Posts})
+		}
+		if !reflect.DeepEqual(got, order.want) {
+			t.Errorf("forum users by %s = %+v, want %+v", order.order, got, order.want)
+		}
+	}
+}
+
+func TestThreadPostsOrder(t *testing.T) {
+	store, thread := makeThread(t)
+	threads := MakeThreadRepository(store)
+
+	tests := []struct {
+		sort  string
+		limit int
+		since int
+		desc  bool
+		want  []int64
+	}{
+		{"flat", 10, -1, false, []int64{1, 2, 3, 4, 5}},
+		{"flat", 2, 2, true, []int64{1}},
+		{"tree", 10, -1, false, []int64{1, 3, 4, 2, 5}},
+		{"tree", 10, -1, true, []int64{5, 2, 4, 3, 1}},
+		{"tree", 2, 3, false, []int64{4, 2}},
+		{"parent_tree", 1, -1, false, []int64{1, 3, 4}},
+		{"parent_tree", 1, -1, true, []int64{2, 5}},
+		{"parent_tree", 10, 4, false, []int64{2, 5}},
+	}
+	for _, test := range tests {
+		var posts *[]models.Post
+		var err error
+		switch test.sort {
+		case "flat":
+			posts, err = threads.GetThreadPostsFlat(thread.Id, test.limit, test.since, test.desc)
+		case "tree":
+			posts, err = threads.GetThreadPostsTree(thread.Id, test.limit, test.since, test.desc)
+		case "parent_tree":
+			posts, err = threads.GetThreadPostsParentTree(thread.Id, test.limit, test.since, test.desc)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]int64, 0, len(*posts))
+		for _, current := range *posts {
+			got = append(got, current.Id)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s limit %d since %d desc %t = %v, want %v", test.sort, test.limit, test.since, test.desc, got, test.want)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	store, thread := makeThread(t)
+	service := MakeServiceRepository(store)
+
+	store.forums["forum"].Threads = 5
+	store.thread(thread.Id).Votes = 3
+	delete(store.userForum["forum"], "bob")
+	store.activity["forum"]["alice"].posts = 7
+
+	reconciliation, err := service.Reconcile(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.CounterDrift{
+		{Counter: models.CounterForumThreads, Forum: "forum", Stored: 5, Actual: 1},
+		{Counter: models.CounterThreadVotes, Thread: thread.Id, Stored: 3, Actual: 0},
+		{Counter: models.CounterForumUsers, Forum: "forum", Stored: 1, Actual: 2},
+		{Counter: models.CounterForumUserPosts, Forum: "forum", Nickname: "alice", Stored: 7, Actual: 2},
+	}
+	if !reflect.DeepEqual(reconciliation.Drifts, want) {
+		t.Fatalf("drifts = %+v, want %+v", reconciliation.Drifts, want)
+	}
+
+	if reconciliation, err = service.Reconcile(false); err != nil {
+		t.Fatal(err)
+	}
+	if len(reconciliation.Drifts) != 0 {
+		t.Errorf("drifts after the repair = %+v", reconciliation.Drifts)
+	}
+}
+
+func TestIdempotencyClaim(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(repository repositories.IdempotencyRepository) error
+	}{
+		{"save", func(repository repositories.IdempotencyRepository) error {
+			return repository.SaveResponse(&models.IdempotentResponse{Key: "key", RequestHash: "first", Status: 201})
+		}},
+		{"release", func(repository repositories.IdempotencyRepository) error {
+			return repository.ReleaseKey("key", "first")
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := MakeIdempotencyRepository(MakeStore())
+			// the second request claims the key once the lease of the first is over
+			for _, requestHash := range []string{"first", "second"} {
+				if reserved, err := repository.ReserveKey("key", requestHash, time.Hour, 0); err != nil || !reserved {
+					t.Fatalf("ReserveKey(%s) = %t, %v", requestHash, reserved, err)
+				}
+			}
+			if err := test.finish(repository); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := repository.GetResponse("key", time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.RequestHash != "second" || stored.Status != 0 {
+				t.Errorf("the first request changed the claim of the second: %+v", *stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"sort"
+	"time"
+)
+
+type ThreadRepositoryImpl struct {
+	store *Store
+}
+
+func MakeThreadRepository(store *Store) repositories.ThreadRepository {
+	return &ThreadRepositoryImpl{store: store}
+}
+
+func (threadRepository *ThreadRepositoryImpl) CreateThread(thread *models.Thread) error {
+	store := threadRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	forum, ok := store.forums[citext(thread.Forum)]
+	if !ok {
+		return ErrForeignKeyViolation
+	}
+	if _, ok = store.users[citext(thread.Author)]; !ok {
+		return ErrForeignKeyViolation
+	}
+
+	stored := *thread
+	stored.Id = int64(len(store.threads) + 1)
+	stored.Votes = 0
+	stored.Version = 1
+	store.threads = append(store.threads, &stored)
+
+	store.addUserToForum(stored.Author, stored.Forum, participation{threads: 1, joined: stored.Created, lastActive: stored.Created})
+	store.countActivity(stored.Forum, stored.Author, stored.Created, 1, 0, 0)
+	forum.Threads++
+	forum.Version++
+
+	thread.Id = stored.Id
+	thread.Version = stored.Version
+	return nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThread(slugOrId interface{}) (*models.Thread, error) {
+	switch value := slugOrId.(type) {
+	case int64:
+		return threadRepository.GetById(value)
+	case string:
+		return threadRepository.GetBySlug(value)
+	}
+	return &models.Thread{}, ErrNotFound
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetBySlug(slug string) (*models.Thread, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	thread := new(models.Thread)
+	for _, stored := range store.threads {
+		if citext(stored.Slug) == citext(slug) {
+			*thread = *stored
+			return thread, nil
+		}
+	}
+	return thread, ErrNotFound
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetById(id int64) (*models.Thread, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	thread := new(models.Thread)
+	stored := store.thread(id)
+	if stored == nil {
+		return thread, ErrNotFound
+	}
+	*thread = *stored
+	return thread, nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) UpdateThread(thread *models.Thread) error {
+	store := threadRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored := store.thread(thread.Id)
+	if stored == nil || (thread.Version != 0 && thread.Version != stored.Version) {
+		return pkg.ErrPreconditionFailed
+	}
+	stored.Title = thread.Title
+	stored.Message = thread.Message
+	stored.Version++
+	thread.Version = stored.Version
+	return nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) CreateThreadPosts(thread *models.Thread, posts *models.Posts) error {
+	store := threadRepository.store
+	var events []*models.ThreadEvent
+	defer func() { store.publish(events) }()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	forum, ok := store.forums[citext(thread.Forum)]
+	if !ok || store.thread(thread.Id) == nil {
+		return ErrForeignKeyViolation
+	}
+	for _, newPost := range *posts {
+		if _, ok := store.users[citext(newPost.Author)]; !ok {
+			return pkg.ErrUserNotFound
+		}
+		if newPost.Parent == 0 {
+			continue
+		}
+		parent := store.post(newPost.Parent)
+		if parent == nil {
+			return pkg.ErrParentPostNotExist
+		}
+		if parent.Thread != thread.Id {
+			return pkg.ErrParentPostFromOtherThread
+		}
+	}
+
+	created := time.Now()
+	createdFormatted := created.Format(time.RFC3339)
+	for i := range *posts {
+		newPost := &(*posts)[i]
+		newPost.Id = int64(len(store.posts) + 1)
+		newPost.Forum = thread.Forum
+		newPost.Thread = thread.Id
+		newPost.IsEdited = false
+		newPost.Created = createdFormatted
+		newPost.Version = 1
+
+		stored := &post{Post: *newPost, created: created}
+		if newPost.Parent != 0 {
+			stored.path = append(stored.path, store.post(newPost.Parent).path...)
+		}
+		stored.path = append(stored.path, newPost.Id)
+		store.posts = append(store.posts, stored)
+		store.threadPosts[thread.Id] = append(store.threadPosts[thread.Id], stored)
+
+		store.addUserToForum(newPost.Author, newPost.Forum, participation{posts: 1, joined: created, lastActive: created})
+		store.countActivity(newPost.Forum, newPost.Author, created, 0, 1, 0)
+		forum.Posts++
+		forum.Version++
+		events = append(events, &models.ThreadEvent{Type: "post", Thread: thread.Id, Id: newPost.Id})
+	}
+	return nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsFlat(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	threadPosts := store.threadPosts[id]
+	posts := make([]models.Post, 0)
+	for i := range threadPosts {
+		current := threadPosts[i]
+		if desc {
+			current = threadPosts[len(threadPosts)-1-i]
+		}
+		if since != -1 && ((!desc && current.Id <= int64(since)) || (desc && current.Id >= int64(since))) {
+			continue
+		}
+		if len(posts) == limit {
+			break
+		}
+		posts = append(posts, current.Post)
+	}
+	return &posts, nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsTree(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var sincePath []int64
+	if since != -1 {
+		sincePost := store.post(int64(since))
+		if sincePost == nil {
+			return &[]models.Post{}, nil
+		}
+		sincePath = sincePost.path
+	}
+
+	posts := make([]models.Post, 0)
+	for _, current := range store.sortedByPath(id, desc) {
+		if sincePath != nil {
+			compared := comparePaths(current.path, sincePath)
+			if (!desc && compared <= 0) || (desc && compared >= 0) {
+				continue
+			}
+		}
+		if len(posts) == limit {
+			break
+		}
+		posts = append(posts, current.Post)
+	}
+	return &posts, nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsParentTree(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	sinceRoot := int64(-1)
+	if since != -1 {
+		sincePost := store.post(int64(since))
+		if sincePost == nil {
+			return &[]models.Post{}, nil
+		}
+		sinceRoot = sincePost.path[0]
+	}
+
+	// roots are ordered by path[1], which is their id
+	var roots []int64
+	threadPosts := store.threadPosts[id]
+	for i := range threadPosts {
+		current := threadPosts[i]
+		if desc {
+			current = threadPosts[len(threadPosts)-1-i]
+		}
+		if current.Parent != 0 {
+			continue
+		}
+		if sinceRoot != -1 && ((!desc && current.Id <= sinceRoot) || (desc && current.Id >= sinceRoot)) {
+			continue
+		}
+		if len(roots) == limit {
+			break
+		}
+		roots = append(roots, current.Id)
+	}
+
+	byRoot := make(map[int64][]*post)
+	for _, current := range store.sortedByPath(id, false) {
+		byRoot[current.path[0]] = append(byRoot[current.path[0]], current)
+	}
+
+	posts := make([]models.Post, 0)
+	for _, root := range roots {
+		for _, current := range byRoot[root] {
+			posts = append(posts, current.Post)
+		}
+	}
+	return &posts, nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsRanked(id int64, rank string, limit, since int, desc bool) (*[]models.Post, error) {
+	store := threadRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	less := func(a, b *post) bool {
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Id < b.Id
+	}
+	if rank == "controversial" {
+		less = func(a, b *post) bool {
+			if balanceA, balanceB := controversy(a), controversy(b); balanceA != balanceB {
+				return balanceA > balanceB
+			}
+			if a.voters != b.voters {
+				return a.voters > b.voters
+			}
+			return a.Id < b.Id
+		}
+	}
+
+	children := make(map[int64][]*post)
+	for _, current := range store.threadPosts[id] {
+		children[current.Parent] = append(children[current.Parent], current)
+	}
+	for _, siblings := range children {
+		sort.Slice(siblings, func(i, j int) bool { return less(siblings[i], siblings[j]) })
+	}
+
+	roots := children[0]
+	place := -1
+	if since != -1 {
+		sincePost := store.post(int64(since))
+		if sincePost == nil || sincePost.Thread != id {
+			return &[]models.Post{}, nil
+		}
+		place = 0
+		for place < len(roots) && roots[place].Id != sincePost.path[0] {
+			place++
+		}
+	}
+	from, to := rankedPage(len(roots), place, limit, desc)
+
+	posts := make([]models.Post, 0)
+	var walk func(current *post)
+	walk = func(current *post) {
+		posts = append(posts, current.Post)
+		for _, child := range children[current.Id] {
+			walk(child)
+		}
+	}
+	for i := from; i < to; i++ {
+		root := roots[i]
+		if desc {
+			root = roots[from+to-1-i]
+		}
+		walk(root)
+	}
+	return &posts, nil
+}
+
+// rankedPage returns the bounds of the page of the roots, count in all, that
+// comes after the root at place, or before it with desc: roots[from:to] is the
+// page in ranked order. Place -1 gives the first page, or the last one with desc.
+func rankedPage(count, place, limit int, desc bool) (from, to int) {
+	switch {
+	case place == -1 && desc:
+		from, to = count-limit, count
+	case place == -1:
+		from, to = 0, limit
+	case desc:
+		from, to = place-limit, place
+	default:
+		from, to = place+1, place+1+limit
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to > count {
+		to = count
+	}
+	if from > to {
+		from = to
+	}
+	return from, to
+}
+
+// controversy is twice the smaller of the up and down votes of the post.
+func controversy(current *post) int32 {
+	if current.Score > 0 {
+		return current.voters - current.Score
+	}
+	return current.voters + current.Score
+}
+
+func (store *Store) sortedByPath(thread int64, desc bool) []*post {
+	sorted := append([]*post(nil), store.threadPosts[thread]...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if desc {
+			return comparePaths(sorted[i].path, sorted[j].path) > 0
+		}
+		return comparePaths(sorted[i].path, sorted[j].path) < 0
+	})
+	return sorted
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"reflect"
+	"testing"
+)
+
+func TestRankedPage(t *testing.T) {
+	tests := []struct {
+		count, place, limit int
+		desc                bool
+		from, to            int
+	}{
+		{5, -1, 2, false, 0, 2},
+		{5, -1, 10, false, 0, 5},
+		{5, -1, 2, true, 3, 5},
+		{5, -1, 10, true, 0, 5},
+		{5, 1, 2, false, 2, 4},
+		{5, 3, 10, false, 4, 5},
+		{5, 4, 2, false, 5, 5},
+		{5, 3, 2, true, 1, 3},
+		{5, 1, 10, true, 0, 1},
+		{5, 0, 2, true, 0, 0},
+		// the root of the since post is not among the roots
+		{5, 5, 2, false, 5, 5},
+		{5, 5, 2, true, 3, 5},
+		{0, -1, 2, false, 0, 0},
+		{0, -1, 2, true, 0, 0},
+	}
+	for _, test := range tests {
+		from, to := rankedPage(test.count, test.place, test.limit, test.desc)
+		if from != test.from || to != test.to {
+			t.Errorf("rankedPage(%d, %d, %d, %t) = %d, %d, want %d, %d",
+				test.count, test.place, test.limit, test.desc, from, to, test.from, test.to)
+		}
+	}
+}
+
+// makeRankedThread fills the store with a thread of four roots ranked
+// 2 (with its reply 5), 1, 3, 4 by score and 3, 2 (with 5), 1, 4 by controversy,
+// and another thread with post 6.
+func makeRankedThread(t *testing.T) (*Store, int64) {
+	store := MakeStore()
+	users, forums := MakeUserRepository(store), MakeForumRepository(store)
+	threads, votes := MakeThreadRepository(store), MakeVoteRepository(store)
+
+	for _, nickname := range []string{"alice", "bob"} {
+		if err := users.CreateUser(&models.User{Nickname: nickname, Fullname: nickname, Email: nickname + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := forums.CreateForum(&models.Forum{Title: "Forum", User: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	thread, other := &models.Thread{Title: "Ranked", Author: "alice", Forum: "forum", Message: "Message"},
+		&models.Thread{Title: "Other", Author: "alice", Forum: "forum", Message: "Message"}
+	for _, current := range []*models.Thread{thread, other} {
+		if err := threads.CreateThread(current); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	posts := models.Posts{
+		{Author: "bob", Message: "1"},
+		{Author: "bob", Message: "2"},
+		{Author: "bob", Message: "3"},
+		{Author: "bob", Message: "4"},
+	}
+	if err := threads.CreateThreadPosts(thread, &posts); err != nil {
+		t.Fatal(err)
+	}
+	if err := threads.CreateThreadPosts(thread, &models.Posts{{Author: "alice", Message: "5", Parent: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := threads.CreateThreadPosts(other, &models.Posts{{Author: "alice", Message: "6"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vote := range []struct {
+		post     int64
+		nickname string
+		voice    int32
+	}{
+		{1, "alice", 1},
+		{2, "alice", 1},
+		{2, "bob", 1},
+		{3, "alice", 1},
+		{3, "bob", -1},
+	} {
+		if err := votes.VoteForPost(&models.Post{Id: vote.post}, &models.Vote{Nickname: vote.nickname, Voice: vote.voice}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, thread.Id
+}
+
+func TestGetThreadPostsRanked(t *testing.T) {
+	store, id := makeRankedThread(t)
+	threads := MakeThreadRepository(store)
+
+	tests := []struct {
+		name  string
+		rank  string
+		limit int
+		since int
+		desc  bool
+		want  []int64
+	}{
+		{"top", "top", 10, -1, false, []int64{2, 5, 1, 3, 4}},
+		{"top page", "top", 2, -1, false, []int64{2, 5, 1}},
+		{"top last page", "top", 2, -1, true, []int64{4, 3}},
+		{"top after a root", "top", 1, 1, false, []int64{3}},
+		{"top after a reply", "top", 2, 5, false, []int64{1, 3}},
+		{"top after the last root", "top", 2, 4, false, []int64{}},
+		{"top before a root", "top", 5, 1, true, []int64{2, 5}},
+		{"top before the first root", "top", 5, 2, true, []int64{}},
+		{"since a post of another thread", "top", 5, 6, false, []int64{}},
+		{"since a missing post", "top", 5, 99, false, []int64{}},
+		{"controversial page", "controversial", 2, -1, false, []int64{3, 2, 5}},
+		{"controversial last page", "controversial", 2, -1, true, []int64{4, 1}},
+		{"controversial after a root", "controversial", 10, 2, false, []int64{1, 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			posts, err := threads.GetThreadPostsRanked(id, test.rank, test.limit, test.since, test.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]int64, 0, len(*posts))
+			for _, current := range *posts {
+				got = append(got, current.Id)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetThreadPostsRanked() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
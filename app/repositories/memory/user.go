@@ -0,0 +1,452 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"sort"
+	"time"
+)
+
+type UserRepositoryImpl struct {
+	store *Store
+}
+
+func MakeUserRepository(store *Store) repositories.UserRepository {
+	return &UserRepositoryImpl{store: store}
+}
+
+func (userRepository *UserRepositoryImpl) CreateUser(user *models.User) error {
+	store := userRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.users[citext(user.Nickname)]; ok {
+		return ErrUniqueViolation
+	}
+	if _, ok := store.usersByEmail[citext(user.Email)]; ok {
+		return ErrUniqueViolation
+	}
+
+	stored := *user
+	stored.Version = 1
+	store.users[citext(user.Nickname)] = &stored
+	store.usersByEmail[citext(user.Email)] = &stored
+	return nil
+}
+
+func (userRepository *UserRepositoryImpl) UpdateUser(user *models.User) error {
+	store := userRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored, ok := store.users[citext(user.Nickname)]
+	if !ok || (user.Version != 0 && user.Version != stored.Version) {
+		return pkg.ErrPreconditionFailed
+	}
+	if other, ok := store.usersByEmail[citext(user.Email)]; ok && other != stored {
+		return ErrUniqueViolation
+	}
+
+	delete(store.usersByEmail, citext(stored.Email))
+	stored.Fullname = user.Fullname
+	stored.About = user.About
+	stored.Email = user.Email
+	stored.Version++
+	store.usersByEmail[citext(stored.Email)] = stored
+
+	*user = *stored
+	return nil
+}
+
+func (userRepository *UserRepositoryImpl) GetInfoAboutUser(nickname string) (*models.User, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	user := new(models.User)
+	stored, ok := store.users[citext(nickname)]
+	if !ok {
+		return user, ErrNotFound
+	}
+	*user = *stored
+	return user, nil
+}
+
+func (userRepository *UserRepositoryImpl) GetSimilarUsers(user *models.User) (*[]models.User, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var users []models.User
+	byNickname, nicknameOk := store.users[citext(user.Nickname)]
+	if nicknameOk {
+		users = append(users, *byNickname)
+	}
+	if byEmail, ok := store.usersByEmail[citext(user.Email)]; ok && (!nicknameOk || byEmail != byNickname) {
+		users = append(users, *byEmail)
+	}
+	return &users, nil
+}
+
+func (userRepository *UserRepositoryImpl) GetUserPosts(nickname string, limit, since int, desc bool) (*[]models.Post, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	posts := make([]models.Post, 0)
+	for i := range store.posts {
+		current := store.posts[i]
+		if desc {
+			current = store.posts[len(store.posts)-1-i]
+		}
+		if citext(current.Author) != citext(nickname) {
+			continue
+		}
+		if since != -1 && ((!desc && current.Id <= int64(since)) || (desc && current.Id >= int64(since))) {
+			continue
+		}
+		if len(posts) == limit {
+			break
+		}
+		posts = append(posts, current.Post)
+	}
+	return &posts, nil
+}
+
+func (userRepository *UserRepositoryImpl) GetUserThreads(nickname string, limit int, since string, desc bool) (*[]models.Thread, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var sinceTime time.Time
+	if since != "" {
+		var err error
+		sinceTime, err = time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var threads []models.Thread
+	for _, thread := range store.threads {
+		if citext(thread.Author) != citext(nickname) {
+			continue
+		}
+		if since != "" && ((!desc && thread.Created.Before(sinceTime)) || (desc && thread.Created.After(sinceTime))) {
+			continue
+		}
+		threads = append(threads, *thread)
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		if desc {
+			return threads[i].Created.After(threads[j].Created)
+		}
+		return threads[i].Created.Before(threads[j].Created)
+	})
+	if len(threads) > limit {
+		threads = threads[:limit]
+	}
+	return &threads, nil
+}
+
+func (userRepository *UserRepositoryImpl) GetUserForums(nickname string, limit int, since string, desc bool) (*[]models.UserForum, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	posts := make(map[string]int32)
+	for _, post := range store.posts {
+		if citext(post.Author) == citext(nickname) {
+			posts[citext(post.Forum)]++
+		}
+	}
+
+	forums := make([]models.UserForum, 0)
+	for slug, forumUsers := range store.userForum {
+		if _, ok := forumUsers[citext(nickname)]; !ok {
+			continue
+		}
+		if since != "" && ((!desc && slug <= citext(since)) || (desc && slug >= citext(since))) {
+			continue
+		}
+		forum := store.forums[slug]
+		forums = append(forums, models.UserForum{Slug: forum.Slug, Title: forum.Title, User: forum.User, Posts: posts[slug]})
+	}
+	sort.Slice(forums, func(i, j int) bool {
+		if desc {
+			return citext(forums[i].Slug) > citext(forums[j].Slug)
+		}
+		return citext(forums[i].Slug) < citext(forums[j].Slug)
+	})
+	if len(forums) > limit {
+		forums = forums[:limit]
+	}
+	return &forums, nil
+}
+
+func (userRepository *UserRepositoryImpl) ExportUser(nickname string) (*models.UserExport, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	stored, ok := store.users[citext(nickname)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user := *stored
+	export := &models.UserExport{User: &user, Threads: models.Threads{}, Posts: models.Posts{}, Votes: models.TransferVotes{}}
+	for _, thread := range store.threads {
+		if citext(thread.Author) == citext(nickname) {
+			export.Threads = append(export.Threads, *thread)
+		}
+	}
+	for _, post := range store.posts {
+		if citext(post.Author) == citext(nickname) {
+			export.Posts = append(export.Posts, post.Post)
+		}
+	}
+	for key, voice := range store.votes {
+		if key.nickname == citext(nickname) {
+			export.Votes = append(export.Votes, models.TransferVote{Nickname: user.Nickname, Thread: key.thread, Voice: voice})
+		}
+	}
+	for key, voice := range store.postVotes {
+		if key.nickname == citext(nickname) {
+			export.Votes = append(export.Votes, models.TransferVote{Nickname: user.Nickname, Thread: store.post(key.post).Thread, Post: key.post, Voice: voice})
+		}
+	}
+	sort.Slice(export.Votes, func(i, j int) bool {
+		if export.Votes[i].Thread != export.Votes[j].Thread {
+			return export.Votes[i].Thread < export.Votes[j].Thread
+		}
+		return export.Votes[i].Post < export.Votes[j].Post
+	})
+	return export, nil
+}
+
+// DeleteUser hands the forums, threads and posts of the user over to the
+// tombstone account along with their reputation, then deletes the user and
+// their votes like the vote triggers would.
+func (userRepository *UserRepositoryImpl) DeleteUser(nickname string) error {
+	store := userRepository.store
+	var events []*models.ThreadEvent
+	defer func() { store.publish(events) }()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	user, ok := store.users[citext(nickname)]
+	if !ok {
+		return ErrNotFound
+	}
+	tombstone, ok := store.users[citext(models.DeletedUser.Nickname)]
+	if !ok {
+		if _, ok := store.usersByEmail[citext(models.DeletedUser.Email)]; ok {
+			return pkg.ErrTombstoneTaken
+		}
+		stored := models.DeletedUser
+		stored.Version = 1
+		tombstone = &stored
+		store.users[citext(tombstone.Nickname)] = tombstone
+		store.usersByEmail[citext(tombstone.Email)] = tombstone
+	}
+
+	for key, voice := range store.votes {
+		if key.nickname != citext(nickname) {
+			continue
+		}
+		delete(store.votes, key)
+		thread := store.thread(key.thread)
+		thread.Votes -= voice
+		thread.Version++
+		store.addReputation(thread.Author, thread.Forum, -voice)
+		events = append(events, &models.ThreadEvent{Type: "vote", Thread: thread.Id, Votes: thread.Votes})
+	}
+	for key, voice := range store.postVotes {
+		if key.nickname != citext(nickname) {
+			continue
+		}
+		delete(store.postVotes, key)
+		post := store.post(key.post)
+		post.Score -= voice
+		post.voters--
+		post.Version++
+		store.addReputation(post.Author, post.Forum, -voice)
+	}
+
+	for _, forumUsers := range store.userForum {
+		if _, ok := forumUsers[citext(nickname)]; ok {
+			delete(forumUsers, citext(nickname))
+			forumUsers[citext(tombstone.Nickname)] = struct{}{}
+		}
+	}
+	for _, forumReputation := range store.reputation {
+		if reputation, ok := forumReputation[citext(nickname)]; ok {
+			delete(forumReputation, citext(nickname))
+			forumReputation[citext(tombstone.Nickname)] += reputation
+		}
+	}
+	for _, forumStats := range store.stats {
+		for _, stats := range forumStats {
+			if _, ok := stats.users[citext(nickname)]; ok {
+				delete(stats.users, citext(nickname))
+				stats.users[citext(tombstone.Nickname)] = struct{}{}
+			}
+		}
+	}
+	for _, forumActivity := range store.activity {
+		if activity, ok := forumActivity[citext(nickname)]; ok {
+			delete(forumActivity, citext(nickname))
+			if stored, ok := forumActivity[citext(tombstone.Nickname)]; ok {
+				stored.merge(*activity)
+			} else {
+				forumActivity[citext(tombstone.Nickname)] = activity
+			}
+		}
+	}
+	tombstone.Reputation += user.Reputation
+	tombstone.Version++
+
+	for _, forum := range store.forums {
+		if citext(forum.User) == citext(nickname) {
+			forum.User = tombstone.Nickname
+			forum.Version++
+		}
+	}
+	for _, thread := range store.threads {
+		if citext(thread.Author) == citext(nickname) {
+			thread.Author = tombstone.Nickname
+			thread.Version++
+		}
+	}
+	for _, post := range store.posts {
+		if citext(post.Author) == citext(nickname) {
+			post.Author = tombstone.Nickname
+			post.Version++
+		}
+	}
+
+	for name, redirect := range store.redirects {
+		if redirect.target == citext(nickname) {
+			delete(store.redirects, name)
+		}
+	}
+	delete(store.users, citext(nickname))
+	delete(store.usersByEmail, citext(user.Email))
+	return nil
+}
+
+// RenameUser changes the nickname of user.Nickname, conditioned on its Version
+// like UpdateUser, and rewrites every reference to it like on update cascade.
+// The old nickname redirects to the new one for ttl unless only its case
+// changed.
+func (userRepository *UserRepositoryImpl) RenameUser(user *models.User, nickname string, ttl time.Duration) error {
+	store := userRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	from, to := citext(user.Nickname), citext(nickname)
+	if redirect, ok := store.redirects[to]; ok && redirect.target != from && time.Since(redirect.created) < ttl {
+		return pkg.ErrUserAlreadyExist
+	}
+	stored, ok := store.users[from]
+	if !ok || (user.Version != 0 && user.Version != stored.Version) {
+		return pkg.ErrPreconditionFailed
+	}
+	if other, ok := store.users[to]; ok && other != stored {
+		return pkg.ErrUserAlreadyExist
+	}
+
+	delete(store.users, from)
+	stored.Nickname = nickname
+	stored.Version++
+	store.users[to] = stored
+
+	for _, forum := range store.forums {
+		if citext(forum.User) == from {
+			forum.User = nickname
+			forum.Version++
+		}
+	}
+	for _, thread := range store.threads {
+		if citext(thread.Author) == from {
+			thread.Author = nickname
+			thread.Version++
+		}
+	}
+	for _, post := range store.posts {
+		if citext(post.Author) == from {
+			post.Author = nickname
+			post.Version++
+		}
+	}
+
+	// the other tables are keyed by the lowercased nickname
+	if from != to {
+		for key, voice := range store.votes {
+			if key.nickname == from {
+				delete(store.votes, key)
+				store.votes[voteKey{thread: key.thread, nickname: to}] = voice
+			}
+		}
+		for key, voice := range store.postVotes {
+			if key.nickname == from {
+				delete(store.postVotes, key)
+				store.postVotes[postVoteKey{post: key.post, nickname: to}] = voice
+			}
+		}
+		for _, forumUsers := range store.userForum {
+			if _, ok := forumUsers[from]; ok {
+				delete(forumUsers, from)
+				forumUsers[to] = struct{}{}
+			}
+		}
+		for _, forumReputation := range store.reputation {
+			if reputation, ok := forumReputation[from]; ok {
+				delete(forumReputation, from)
+				forumReputation[to] = reputation
+			}
+		}
+		for _, forumActivity := range store.activity {
+			if activity, ok := forumActivity[from]; ok {
+				delete(forumActivity, from)
+				forumActivity[to] = activity
+			}
+		}
+		for _, forumStats := range store.stats {
+			for _, stats := range forumStats {
+				if _, ok := stats.users[from]; ok {
+					delete(stats.users, from)
+					stats.users[to] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for name, redirect := range store.redirects {
+		if name == to || time.Since(redirect.created) >= ttl {
+			delete(store.redirects, name)
+		} else if redirect.target == from {
+			redirect.target = to
+		}
+	}
+	if from != to {
+		store.redirects[from] = &redirect{target: to, created: time.Now()}
+	}
+
+	*user = *stored
+	return nil
+}
+
+func (userRepository *UserRepositoryImpl) GetUserRedirect(nickname string, ttl time.Duration) (string, error) {
+	store := userRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	redirect, ok := store.redirects[citext(nickname)]
+	if !ok || time.Since(redirect.created) >= ttl {
+		return "", ErrNotFound
+	}
+	return store.users[redirect.target].Nickname, nil
+}
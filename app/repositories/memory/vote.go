@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"sort"
+	"time"
+)
+
+type VoteRepositoryImpl struct {
+	store *Store
+}
+
+func MakeVoteRepository(store *Store) repositories.VoteRepository {
+	return &VoteRepositoryImpl{store: store}
+}
+
+func (voteRepository *VoteRepositoryImpl) VoteForThread(thread *models.Thread, vote *models.Vote) error {
+	store := voteRepository.store
+	var events []*models.ThreadEvent
+	defer func() { store.publish(events) }()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored := store.thread(thread.Id)
+	if stored == nil {
+		return pkg.ErrThreadNotFound
+	}
+	if _, ok := store.users[citext(vote.Nickname)]; !ok {
+		return pkg.ErrUserNotFound
+	}
+
+	key := voteKey{thread: thread.Id, nickname: citext(vote.Nickname)}
+	previous := store.votes[key]
+	store.votes[key] = vote.Voice
+	if previous != vote.Voice {
+		stored.Votes += vote.Voice - previous
+		stored.Version++
+		store.addReputation(stored.Author, stored.Forum, vote.Voice-previous)
+		store.countActivity(stored.Forum, vote.Nickname, time.Now(), 0, 0, 1)
+		events = append(events, &models.ThreadEvent{Type: "vote", Thread: thread.Id, Votes: stored.Votes})
+	}
+	thread.Votes, thread.Version = stored.Votes, stored.Version
+	return nil
+}
+
+func (voteRepository *VoteRepositoryImpl) VoteForPost(post *models.Post, vote *models.Vote) error {
+	store := voteRepository.store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored := store.post(post.Id)
+	if stored == nil {
+		return pkg.ErrPostNotFound
+	}
+	if _, ok := store.users[citext(vote.Nickname)]; !ok {
+		return pkg.ErrUserNotFound
+	}
+
+	key := postVoteKey{post: post.Id, nickname: citext(vote.Nickname)}
+	previous, voted := store.postVotes[key]
+	store.postVotes[key] = vote.Voice
+	if !voted {
+		stored.voters++
+	}
+	if !voted || previous != vote.Voice {
+		stored.Score += vote.Voice - previous
+		stored.Version++
+		store.addReputation(stored.Author, stored.Forum, vote.Voice-previous)
+		store.countActivity(stored.Forum, vote.Nickname, time.Now(), 0, 0, 1)
+	}
+	post.Score, post.Version = stored.Score, stored.Version
+	return nil
+}
+
+func (voteRepository *VoteRepositoryImpl) DeleteVote(thread *models.Thread, nickname string) error {
+	store := voteRepository.store
+	var events []*models.ThreadEvent
+	defer func() { store.publish(events) }()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored := store.thread(thread.Id)
+	key := voteKey{thread: thread.Id, nickname: citext(nickname)}
+	voice, ok := store.votes[key]
+	if !ok || stored == nil {
+		return pkg.ErrVoteNotFound
+	}
+	delete(store.votes, key)
+
+	stored.Votes -= voice
+	stored.Version++
+	store.addReputation(stored.Author, stored.Forum, -voice)
+	thread.Votes, thread.Version = stored.Votes, stored.Version
+	events = append(events, &models.ThreadEvent{Type: "vote", Thread: thread.Id, Votes: stored.Votes})
+	return nil
+}
+
+func (voteRepository *VoteRepositoryImpl) GetThreadVoters(id int64, limit int, since string, desc bool) (*models.ThreadVoters, error) {
+	store := voteRepository.store
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	voters := &models.ThreadVoters{Voters: []models.Voter{}}
+	var all []models.Voter
+	for key, voice := range store.votes {
+		if key.thread != id {
+			continue
+		}
+		voters.Votes += voice
+		if voice > 0 {
+			voters.Upvotes++
+		} else if voice < 0 {
+			voters.Downvotes++
+		}
+		all = append(all, models.Voter{Nickname: store.users[key.nickname].Nickname, Voice: voice})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if desc {
+			return citext(all[i].Nickname) > citext(all[j].Nickname)
+		}
+		return citext(all[i].Nickname) < citext(all[j].Nickname)
+	})
+
+	for _, voter := range all {
+		if since != "" && ((!desc && citext(voter.Nickname) <= citext(since)) || (desc && citext(voter.Nickname) >= citext(since))) {
+			continue
+		}
+		if len(voters.Voters) == limit {
+			break
+		}
+		voters.Voters = append(voters.Voters, voter)
+	}
+	return voters, nil
+}
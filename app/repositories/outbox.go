@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"time"
+
+	"github.com/jackc/pgx"
+	_ "github.com/lib/pq"
+)
+
+type OutboxRepository interface {
+	ClaimEvents(limit int, lease time.Duration) (*[]models.OutboxEvent, error)
+	MarkPublished(id int64) error
+	MarkFailed(id int64, lastError string, retryIn time.Duration) error
+	DeletePublished(olderThan time.Duration) error
+}
+
+type OutboxRepositoryImpl struct {
+	db *pgx.ConnPool
+}
+
+func MakeOutboxRepository(db *pgx.ConnPool) OutboxRepository {
+	return &OutboxRepositoryImpl{db: db}
+}
+
+func (outboxRepository *OutboxRepositoryImpl) ClaimEvents(limit int, lease time.Duration) (*[]models.OutboxEvent, error) {
+	rows, err := outboxRepository.db.Query(queries.OutboxClaim, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.OutboxEvent, 0)
+	for rows.Next() {
+		event := models.OutboxEvent{}
+		var payload string
+		err = rows.Scan(&event.Id, &event.Type, &event.Forum, &payload, &event.Attempts, &event.Created)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = []byte(payload)
+		events = append(events, event)
+	}
+	return &events, rows.Err()
+}
+
+func (outboxRepository *OutboxRepositoryImpl) MarkPublished(id int64) error {
+	_, err := outboxRepository.db.Exec(queries.OutboxPublished, id)
+	return err
+}
+
+func (outboxRepository *OutboxRepositoryImpl) MarkFailed(id int64, lastError string, retryIn time.Duration) error {
+	_, err := outboxRepository.db.Exec(queries.OutboxFailed, id, lastError, retryIn.Seconds())
+	return err
+}
+
+func (outboxRepository *OutboxRepositoryImpl) DeletePublished(olderThan time.Duration) error {
+	_, err := outboxRepository.db.Exec(queries.OutboxDeletePublished, olderThan.Seconds())
+	return err
+}
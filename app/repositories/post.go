@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"db_forum/app/models"
+	"db_forum/pkg"
 	"db_forum/pkg/queries"
 	"time"
 
@@ -34,12 +35,17 @@ func (postStore *PostRepositoryImpl) GetPost(id int64) (post *models.Post, err e
 			&post.IsEdited,
 			&post.Forum,
 			&post.Thread,
-			&timeScan)
+			&timeScan,
+			&post.Score,
+			&post.Version)
 	post.Created = timeScan.Format(time.RFC3339)
 	return
 }
 
 func (postStore *PostRepositoryImpl) UpdatePost(post *models.Post) (err error) {
-	_, err = postStore.db.Exec(queries.PostUpdate, post.Message, post.IsEdited, post.Id)
+	err = postStore.db.QueryRow(queries.PostUpdate, post.Message, post.IsEdited, post.Id, post.Version).Scan(&post.Version)
+	if err == pgx.ErrNoRows {
+		err = pkg.ErrPreconditionFailed
+	}
 	return
 }
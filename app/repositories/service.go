@@ -10,6 +10,7 @@ import (
 type ServiceRepository interface {
 	ClearService() (err error)
 	GetService() (status *models.Status, err error)
+	Reconcile(repair bool) (reconciliation *models.Reconciliation, err error)
 }
 
 type ServiceRepositoryImpl struct {
@@ -35,3 +36,135 @@ func (serviceRepository *ServiceRepositoryImpl) GetService() (status *models.Sta
 			&status.Post)
 	return
 }
+
+// Reconcile compares the counters kept by the triggers with the rows they
+// count. Repairs add the difference instead of overwriting the counter, so
+// writes committed in the meantime are not lost.
+func (serviceRepository *ServiceRepositoryImpl) Reconcile(repair bool) (reconciliation *models.Reconciliation, err error) {
+	tx, err := serviceRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	reconciliation = &models.Reconciliation{Drifts: make([]models.CounterDrift, 0), Repaired: repair}
+	rows, err := tx.Query(queries.ServiceDriftForums)
+	if err != nil {
+		return
+	}
+	for rows.Next() {
+		var forum string
+		var posts, actualPosts, threads, actualThreads int64
+		if err = rows.Scan(&forum, &posts, &actualPosts, &threads, &actualThreads); err != nil {
+			rows.Close()
+			return
+		}
+		if posts != actualPosts {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumPosts, Forum: forum, Stored: posts, Actual: actualPosts})
+		}
+		if threads != actualThreads {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumThreads, Forum: forum, Stored: threads, Actual: actualThreads})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	if err = scanDrifts(tx, queries.ServiceDriftThreads, models.CounterThreadVotes, reconciliation); err != nil {
+		return
+	}
+	if err = scanDrifts(tx, queries.ServiceDriftForumUsers, models.CounterForumUsers, reconciliation); err != nil {
+		return
+	}
+	if err = scanActivityDrifts(tx, queries.ServiceDriftForumActivity, reconciliation); err != nil {
+		return
+	}
+	if !repair {
+		return
+	}
+
+	activityRepaired := make(map[string]bool)
+	for _, drift := range reconciliation.Drifts {
+		switch drift.Counter {
+		case models.CounterForumPosts:
+			_, err = tx.Exec(queries.ServiceRepairForum, drift.Forum, drift.Actual-drift.Stored, 0)
+		case models.CounterForumThreads:
+			_, err = tx.Exec(queries.ServiceRepairForum, drift.Forum, 0, drift.Actual-drift.Stored)
+		case models.CounterThreadVotes:
+			_, err = tx.Exec(queries.ServiceRepairThread, drift.Thread, drift.Actual-drift.Stored)
+		case models.CounterForumUsers:
+			for _, query := range []string{queries.ServiceRepairForumUsers, queries.AdminForumPruneUsers, queries.ServiceRepairForumActivity} {
+				if _, err = tx.Exec(query, drift.Forum); err != nil {
+					break
+				}
+			}
+		case models.CounterForumUserThreads, models.CounterForumUserPosts, models.CounterForumUserJoined, models.CounterForumUserLastActive:
+			if !activityRepaired[drift.Forum] {
+				activityRepaired[drift.Forum] = true
+				_, err = tx.Exec(queries.ServiceRepairForumActivity, drift.Forum)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// scanDrifts reads rows of (forum or thread, stored, actual).
+func scanDrifts(tx *pgx.Tx, query, counter string, reconciliation *models.Reconciliation) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		drift := models.CounterDrift{Counter: counter}
+		if counter == models.CounterThreadVotes {
+			err = rows.Scan(&drift.Thread, &drift.Stored, &drift.Actual)
+		} else {
+			err = rows.Scan(&drift.Forum, &drift.Stored, &drift.Actual)
+		}
+		if err != nil {
+			return err
+		}
+		reconciliation.Drifts = append(reconciliation.Drifts, drift)
+	}
+	return rows.Err()
+}
+
+// scanActivityDrifts reads rows of (forum, nickname) followed by the stored and
+// actual threads, posts, joined and last_active, one drift per pair that differs.
+func scanActivityDrifts(tx *pgx.Tx, query string, reconciliation *models.Reconciliation) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	counters := []string{models.CounterForumUserThreads, models.CounterForumUserPosts, models.CounterForumUserJoined, models.CounterForumUserLastActive}
+	for rows.Next() {
+		var forum, nickname string
+		var values [8]int64
+		err = rows.Scan(&forum, &nickname, &values[0], &values[1], &values[2], &values[3], &values[4], &values[5], &values[6], &values[7])
+		if err != nil {
+			return err
+		}
+		for i, counter := range counters {
+			if stored, actual := values[2*i], values[2*i+1]; stored != actual {
+				reconciliation.Drifts = append(reconciliation.Drifts,
+					models.CounterDrift{Counter: counter, Forum: forum, Nickname: nickname, Stored: stored, Actual: actual})
+			}
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	_ "embed"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+//go:embed schema.sql
+var schema string
+
+// DB is the SQLite database shared by the repositories. SQLite has no
+// LISTEN/NOTIFY, so the repositories hand thread events to the listeners
+// themselves once their writes are committed.
+type DB struct {
+	*sql.DB
+
+	mutex     sync.Mutex
+	listeners []chan<- *models.ThreadEvent
+}
+
+// schemaVersion is the user_version of a database with the current schema.
+// Changing a table or a trigger of a schema already in use takes a migration
+// step in migrate, schema.sql only creates what is missing.
+const schemaVersion = 1
+
+// addedColumns were added to tables of the first schema, before it had a version.
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"posts", "score", "integer default 0"},
+	{"posts", "voters", "integer default 0"},
+	{"users", "reputation", "integer default 0"},
+	{"user_forum", "reputation", "integer default 0"},
+	{"user_forum", "threads", "integer default 0"},
+	{"user_forum", "posts", "integer default 0"},
+	{"user_forum", "joined", "integer"},
+	{"user_forum", "last_active", "integer"},
+}
+
+// Open opens the database file at path, creating the schema if needed and
+// migrating the one of an older database.
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", path))
+	if err != nil {
+		return nil, err
+	}
+	if err = migrate(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &DB{DB: db}, nil
+}
+
+func migrate(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err = tx.QueryRow(schemaVersionGet).Scan(&version); err != nil {
+		return err
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("sqlite: the database has schema version %d, newer than %d", version, schemaVersion)
+	}
+	// version 0 is a new database or one created before the schema had a
+	// version, by any of the earlier schemas
+	if version < 1 {
+		if err = migrateUnversioned(tx); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.Exec(schema); err != nil {
+		return err
+	}
+	if version < 1 {
+		if _, err = tx.Exec(schemaBackfillActivity); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.Exec(fmt.Sprintf(schemaVersionSet, schemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrateUnversioned adds the columns the tables lack and drops the triggers,
+// so the schema creates them anew with their current bodies.
+func migrateUnversioned(tx *sql.Tx) error {
+	for _, added := range addedColumns {
+		var missing bool
+		if err := tx.QueryRow(schemaColumnMissing, added.table, added.column).Scan(&missing); err != nil {
+			return err
+		}
+		if !missing {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf(schemaAddColumn, added.table, added.column, added.definition)); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.Query(schemaTriggers)
+	if err != nil {
+		return err
+	}
+	var triggers []string
+	for rows.Next() {
+		var trigger string
+		if err = rows.Scan(&trigger); err != nil {
+			rows.Close()
+			return err
+		}
+		triggers = append(triggers, trigger)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, trigger := range triggers {
+		if _, err = tx.Exec(fmt.Sprintf(schemaDropTrigger, trigger)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) publish(events ...*models.ThreadEvent) {
+	db.mutex.Lock()
+	listeners := db.listeners
+	db.mutex.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener <- event
+		}
+	}
+}
+
+// timestamps are stored as microseconds, the precision of postgres timestamptz
+func toMicro(value time.Time) int64 {
+	return value.UnixMicro()
+}
+
+func fromMicro(value int64) time.Time {
+	return time.UnixMicro(value)
+}
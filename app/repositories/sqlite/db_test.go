@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// firstSchema is the part of the schema the SQLite backend started with that
+// later versions changed.
+const firstSchema = `
+create table users
+(
+    nickname text collate nocase not null primary key,
+    fullname text                not null,
+    about    text,
+    email    text collate nocase not null unique,
+    version  integer default 1
+);
+
+create table forums
+(
+    title   text                not null,
+    user_   text collate nocase not null references users (nickname) on update cascade on delete cascade,
+    slug    text collate nocase not null primary key,
+    posts   integer default 0,
+    threads integer default 0,
+    version integer default 1
+);
+
+create table threads
+(
+    id      integer primary key autoincrement,
+    title   text                not null,
+    author  text collate nocase not null references users (nickname) on update cascade on delete cascade,
+    forum   text collate nocase not null references forums (slug) on update cascade on delete cascade,
+    message text                not null,
+    votes   integer default 0,
+    slug    text collate nocase,
+    created integer             not null,
+    version integer default 1
+);
+
+create table posts
+(
+    id        integer primary key autoincrement,
+    parent    integer references posts (id),
+    author    text collate nocase not null references users (nickname),
+    message   text                not null,
+    is_edited integer default 0,
+    forum     text collate nocase not null references forums (slug),
+    thread    integer             not null references threads (id),
+    created   integer             not null,
+    path      text    default '',
+    root      integer,
+    version   integer default 1
+);
+
+create table user_forum
+(
+    nickname text collate nocase not null references users (nickname),
+    forum    text collate nocase not null references forums (slug),
+    constraint user_forum_key unique (nickname, forum)
+);
+
+create trigger create_new_thread
+    after insert
+    on threads
+    for each row
+begin
+    insert or ignore into user_forum (nickname, forum) values (new.author, new.forum);
+    update forums set threads = threads + 1, version = version + 1 where slug = new.forum;
+end;
+
+insert into users (nickname, fullname, email) values ('alice', 'Alice', 'alice@example.com');
+insert into forums (title, user_, slug) values ('Forum', 'alice', 'forum');
+insert into threads (title, author, forum, message, created) values ('Thread', 'alice', 'forum', 'Message', 1700000000000000);
+`
+
+func TestOpenNew(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var version int
+	if err = db.QueryRow(schemaVersionGet).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != schemaVersion {
+		t.Fatalf("user_version = %d, want %d", version, schemaVersion)
+	}
+}
+
+func TestOpenMigratesUnversioned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forum.db")
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = old.Exec(firstSchema); err != nil {
+		t.Fatal(err)
+	}
+	_ = old.Close()
+
+	for i := 0; i < 2; i++ {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("open %d: %v", i+1, err)
+		}
+		for _, added := range addedColumns {
+			var missing bool
+			if err = db.QueryRow(schemaColumnMissing, added.table, added.column).Scan(&missing); err != nil {
+				t.Fatal(err)
+			}
+			if missing {
+				t.Errorf("open %d: %s has no column %s", i+1, added.table, added.column)
+			}
+		}
+		var threads, posts int
+		var joined sql.NullInt64
+		err = db.QueryRow("select threads, posts, joined from user_forum where nickname = 'alice';").Scan(&threads, &posts, &joined)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if threads != 1 || posts != 0 || joined.Int64 != 1700000000000000 {
+			t.Fatalf("open %d: user_forum has %d threads, %d posts, joined %v", i+1, threads, posts, joined)
+		}
+		_ = db.Close()
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// the trigger was replaced by the one counting the activity
+	_, err = db.Exec("insert into posts (author, message, forum, thread, created) values ('alice', 'Post', 'forum', 1, 1700000100000000);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var posts int
+	if err = db.QueryRow("select posts from user_forum where nickname = 'alice';").Scan(&posts); err != nil {
+		t.Fatal(err)
+	}
+	if posts != 1 {
+		t.Fatalf("user_forum has %d posts after a new post, want 1", posts)
+	}
+}
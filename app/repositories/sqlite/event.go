@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+type EventRepositoryImpl struct {
+	db *DB
+}
+
+func MakeEventRepository(db *DB) repositories.EventRepository {
+	return &EventRepositoryImpl{db: db}
+}
+
+// ListenThreadEvents registers events as a listener of the database. Events
+// are published in process, so there is no connection to lose and it does not
+// return.
+func (eventRepository *EventRepositoryImpl) ListenThreadEvents(events chan<- *models.ThreadEvent) error {
+	db := eventRepository.db
+	db.mutex.Lock()
+	db.listeners = append(db.listeners, events)
+	db.mutex.Unlock()
+
+	select {}
+}
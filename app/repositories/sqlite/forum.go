@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"time"
+)
+
+type ForumRepositoryImpl struct {
+	db *DB
+}
+
+func MakeForumRepository(db *DB) repositories.ForumRepository {
+	return &ForumRepositoryImpl{db: db}
+}
+
+func (forumRepository *ForumRepositoryImpl) CreateForum(forum *models.Forum) error {
+	_, err := forumRepository.db.Exec(forumCreate, forum.Title, forum.User, forum.Slug)
+	return err
+}
+
+func (forumRepository *ForumRepositoryImpl) GetInfoAboutForum(slug string) (*models.Forum, error) {
+	forum := new(models.Forum)
+	err := forumRepository.db.QueryRow(forumGetBySlug, slug).Scan(&forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads, &forum.Version)
+	return forum, err
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumUsers(slug, order string, limit int, since string, desc bool) (*[]models.ForumUser, error) {
+	tails := []string{forumGetUsers, forumGetUsersDesc, forumGetUsersSince, forumGetUsersSinceDesc}
+	switch order {
+	case "activity":
+		tails = []string{forumGetUsersActivity, forumGetUsersActivityDesc, forumGetUsersActivitySince, forumGetUsersActivitySinceDesc}
+	case "posts":
+		tails = []string{forumGetUsersPosts, forumGetUsersPostsDesc, forumGetUsersPostsSince, forumGetUsersPostsSinceDesc}
+	case "joined":
+		tails = []string{forumGetUsersJoined, forumGetUsersJoinedDesc, forumGetUsersJoinedSince, forumGetUsersJoinedSinceDesc}
+	}
+
+	var rows *sql.Rows
+	var err error
+	if since != "" {
+		if desc {
+			rows, err = forumRepository.db.Query(forumGetUsersBase+tails[3], slug, since, limit)
+		} else {
+			rows, err = forumRepository.db.Query(forumGetUsersBase+tails[2], slug, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = forumRepository.db.Query(forumGetUsersBase+tails[1], slug, limit)
+		} else {
+			rows, err = forumRepository.db.Query(forumGetUsersBase+tails[0], slug, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanForumUsers(rows)
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumLeaderboard(slug string, limit int) (*models.Leaderboard, error) {
+	rows, err := forumRepository.db.Query(forumGetLeaderboard, slug, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaderboard := models.Leaderboard{}
+	for rows.Next() {
+		leader := models.Leader{}
+		if err = rows.Scan(&leader.Nickname, &leader.Fullname, &leader.Reputation); err != nil {
+			return nil, err
+		}
+		leaderboard = append(leaderboard, leader)
+	}
+	return &leaderboard, rows.Err()
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumThreads(slug string, limit int, since string, desc bool) (*[]models.Thread, error) {
+	var rows *sql.Rows
+	var err error
+	if since != "" {
+		var sinceTime time.Time
+		sinceTime, err = time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return nil, err
+		}
+		if desc {
+			rows, err = forumRepository.db.Query(forumGetThreadsSinceDesc, slug, toMicro(sinceTime), limit)
+		} else {
+			rows, err = forumRepository.db.Query(forumGetThreadsSince, slug, toMicro(sinceTime), limit)
+		}
+	} else {
+		if desc {
+			rows, err = forumRepository.db.Query(forumGetThreadsDesc, slug, limit)
+		} else {
+			rows, err = forumRepository.db.Query(forumGetThreads, slug, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+func (forumRepository *ForumRepositoryImpl) GetForumStats(slug, bucket string, from, to time.Time) (*models.ForumStats, error) {
+	size := time.Hour
+	if bucket == "day" {
+		size = 24 * time.Hour
+	}
+	rows, err := forumRepository.db.Query(forumGetStats, slug, size.Microseconds(), toMicro(from), toMicro(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := models.ForumStats{}
+	for rows.Next() {
+		stat := models.ForumStatsBucket{}
+		var start int64
+		if err = rows.Scan(&start, &stat.Threads, &stat.Posts, &stat.Users, &stat.Votes); err != nil {
+			return nil, err
+		}
+		stat.Start = fromMicro(start)
+		stats = append(stats, stat)
+	}
+	return &stats, rows.Err()
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"time"
+)
+
+type IdempotencyRepositoryImpl struct {
+	db *DB
+}
+
+func MakeIdempotencyRepository(db *DB) repositories.IdempotencyRepository {
+	return &IdempotencyRepositoryImpl{db: db}
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) GetResponse(key string, ttl time.Duration) (*models.IdempotentResponse, error) {
+	response := new(models.IdempotentResponse)
+	err := idempotencyRepository.db.QueryRow(idempotencyGet, key, toMicro(time.Now().Add(-ttl))).
+		Scan(&response.Key, &response.RequestHash, &response.Status, &response.ContentType, &response.Body)
+	return response, err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReserveKey(key, requestHash string, ttl, lease time.Duration) (bool, error) {
+	now := time.Now()
+	var reservedKey string
+	err := idempotencyRepository.db.QueryRow(idempotencyReserve, key, requestHash, toMicro(now), toMicro(now.Add(-ttl)), toMicro(now.Add(-lease))).Scan(&reservedKey)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) SaveResponse(response *models.IdempotentResponse) error {
+	_, err := idempotencyRepository.db.Exec(idempotencySave, response.Key, response.RequestHash, response.Status, response.ContentType, response.Body)
+	return err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) ReleaseKey(key, requestHash string) error {
+	_, err := idempotencyRepository.db.Exec(idempotencyRelease, key, requestHash)
+	return err
+}
+
+func (idempotencyRepository *IdempotencyRepositoryImpl) DeleteExpired(ttl time.Duration) error {
+	_, err := idempotencyRepository.db.Exec(idempotencyDeleteExpired, toMicro(time.Now().Add(-ttl)))
+	return err
+}
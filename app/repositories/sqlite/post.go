@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"time"
+)
+
+type PostRepositoryImpl struct {
+	db *DB
+}
+
+func MakePostRepository(db *DB) repositories.PostRepository {
+	return &PostRepositoryImpl{db: db}
+}
+
+func (postStore *PostRepositoryImpl) GetPost(id int64) (*models.Post, error) {
+	post := new(models.Post)
+	var created int64
+	err := postStore.db.QueryRow(postGet, id).
+		Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &created, &post.Score, &post.Version)
+	post.Created = fromMicro(created).Format(time.RFC3339)
+	return post, err
+}
+
+func (postStore *PostRepositoryImpl) UpdatePost(post *models.Post) error {
+	tx, err := postStore.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var message string
+	err = tx.QueryRow(postMessage, post.Id).Scan(&message)
+	if err == nil {
+		err = tx.QueryRow(postUpdate, post.Message, post.IsEdited, post.Id, post.Version).Scan(&post.Version)
+	}
+	if err == sql.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if message != post.Message {
+		postStore.db.publish(&models.ThreadEvent{Type: "edit", Thread: post.Thread, Id: post.Id})
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+// Numbered ?NNN parameters may be repeated, unlike $N in sqlite.
+var (
+	forumCreate            = "insert into forums (title, user_, slug) values (?1, ?2, ?3);"
+	forumGetBySlug         = "select title, user_, slug, posts, threads, version from forums where slug = ?1;"
+	forumGetUsersBase      = "select u.nickname, u.fullname, u.about, u.email, u.reputation, uf.threads, uf.posts, uf.joined, uf.last_active from user_forum uf join users u on u.nickname = uf.nickname where uf.forum = ?1 "
+	forumGetUsers          = "order by uf.nickname limit ?2;"
+	forumGetUsersDesc      = "order by uf.nickname desc limit ?2;"
+	forumGetUsersSince     = "and uf.nickname > ?2 order by uf.nickname limit ?3;"
+	forumGetUsersSinceDesc = "and uf.nickname < ?2 order by uf.nickname desc limit ?3;"
+	// the other orders page by the position of the since participant
+	forumGetUsersActivity          = "order by uf.last_active, uf.nickname limit ?2;"
+	forumGetUsersActivityDesc      = "order by uf.last_active desc, uf.nickname desc limit ?2;"
+	forumGetUsersActivitySince     = "and (uf.last_active, uf.nickname) > ((select last_active from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.last_active, uf.nickname limit ?3;"
+	forumGetUsersActivitySinceDesc = "and (uf.last_active, uf.nickname) < ((select last_active from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.last_active desc, uf.nickname desc limit ?3;"
+	forumGetUsersPosts             = "order by uf.posts, uf.nickname limit ?2;"
+	forumGetUsersPostsDesc         = "order by uf.posts desc, uf.nickname desc limit ?2;"
+	forumGetUsersPostsSince        = "and (uf.posts, uf.nickname) > ((select posts from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.posts, uf.nickname limit ?3;"
+	forumGetUsersPostsSinceDesc    = "and (uf.posts, uf.nickname) < ((select posts from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.posts desc, uf.nickname desc limit ?3;"
+	forumGetUsersJoined            = "order by uf.joined, uf.nickname limit ?2;"
+	forumGetUsersJoinedDesc        = "order by uf.joined desc, uf.nickname desc limit ?2;"
+	forumGetUsersJoinedSince       = "and (uf.joined, uf.nickname) > ((select joined from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.joined, uf.nickname limit ?3;"
+	forumGetUsersJoinedSinceDesc   = "and (uf.joined, uf.nickname) < ((select joined from user_forum where forum = ?1 and nickname = ?2), ?2) order by uf.joined desc, uf.nickname desc limit ?3;"
+	forumGetLeaderboard            = "select users.nickname, users.fullname, user_forum.reputation from user_forum join users on users.nickname = user_forum.nickname where user_forum.forum = ?1 order by user_forum.reputation desc, user_forum.nickname limit ?2;"
+	forumGetThreads                = "select id, title, author, forum, message, votes, slug, created from threads where forum = ?1 order by created asc limit ?2;"
+	forumGetThreadsDesc            = "select id, title, author, forum, message, votes, slug, created from threads where forum = ?1 order by created desc limit ?2;"
+	forumGetThreadsSince           = "select id, title, author, forum, message, votes, slug, created from threads where forum = ?1 and created >= ?2 order by created asc limit ?3;"
+	forumGetThreadsSinceDesc       = "select id, title, author, forum, message, votes, slug, created from threads where forum = ?1 and created <= ?2 order by created desc limit ?3;"
+	// ?2 is the size of the buckets the hours are summed up into, in microseconds
+	forumGetStats = "select counts.bucket, counts.threads, counts.posts, coalesce(users.users, 0), counts.votes " +
+		"from (select hour - hour % ?2 as bucket, sum(threads) as threads, sum(posts) as posts, sum(votes) as votes from forum_stats where forum = ?1 and hour >= ?3 and hour < ?4 group by 1) counts " +
+		"left join (select hour - hour % ?2 as bucket, count(distinct nickname) as users from forum_stats_users where forum = ?1 and hour >= ?3 and hour < ?4 group by 1) users " +
+		"on users.bucket = counts.bucket order by counts.bucket;"
+
+	postGet     = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score, version from posts where id = ?1;"
+	postMessage = "select message from posts where id = ?1;"
+	postUpdate  = "update posts set message = ?1, is_edited = ?2, version = version + 1 where id = ?3 and (?4 = 0 or version = ?4) returning version;"
+	postCreate  = "insert into posts (parent, author, message, forum, thread, created) values (?1, ?2, ?3, ?4, ?5, ?6) returning id;"
+	postThread  = "select thread from posts where id = ?1;"
+	// ?2 is 0 for a post without a parent
+	postCreateTargets = "select exists(select 1 from users where nickname = ?1), ?2 = 0 or exists(select 1 from posts where id = ?2);"
+
+	schemaVersionGet    = "pragma user_version;"
+	schemaVersionSet    = "pragma user_version = %d;"
+	schemaColumnMissing = "select exists(select 1 from sqlite_master where type = 'table' and name = ?1) and not exists(select 1 from pragma_table_info(?1) where name = ?2);"
+	schemaAddColumn     = "alter table %s add column %s %s;"
+	schemaTriggers      = "select name from sqlite_master where type = 'trigger';"
+	schemaDropTrigger   = "drop trigger if exists \"%s\";"
+	// participants of a database older than the activity columns
+	schemaBackfillActivity = "update user_forum set (threads, posts, joined, last_active) = (select sum(thread), sum(1 - thread), min(created), max(created) " +
+		"from (select created, 1 as thread from threads where forum = user_forum.forum and author = user_forum.nickname " +
+		"union all select created, 0 from posts where forum = user_forum.forum and author = user_forum.nickname)) where joined is null;"
+
+	serviceClear = []string{
+		"delete from votes;",
+		"delete from post_votes;",
+		"delete from user_forum;",
+		"delete from forum_stats;",
+		"delete from forum_stats_users;",
+		"delete from posts;",
+		"delete from threads;",
+		"delete from forums;",
+		"delete from user_redirects;",
+		"delete from users;",
+		"delete from idempotency_keys;",
+	}
+	serviceGet = "select (select count(*) from users), (select count(*) from forums), (select count(*) from threads), (select count(*) from posts);"
+
+	serviceDriftForums = "select f.slug, f.posts, coalesce(p.n, 0), f.threads, coalesce(t.n, 0) from forums f " +
+		"left join (select forum, count(*) as n from posts group by forum) p on p.forum = f.slug left join (select forum, count(*) as n from threads group by forum) t on t.forum = f.slug " +
+		"where f.posts <> coalesce(p.n, 0) or f.threads <> coalesce(t.n, 0) order by f.slug;"
+	serviceDriftThreads = "select t.id, t.votes, coalesce(v.n, 0) from threads t left join (select thread, sum(voice) as n from votes group by thread) v on v.thread = t.id " +
+		"where t.votes <> coalesce(v.n, 0) order by t.id;"
+	serviceDriftForumUsers = "select f.slug, (select count(*) from user_forum where forum = f.slug), " +
+		"(select count(*) from (select author from threads where forum = f.slug union select author from posts where forum = f.slug)) from forums f " +
+		"where exists (select 1 from threads t where t.forum = f.slug and not exists (select 1 from user_forum u where u.forum = f.slug and u.nickname = t.author)) " +
+		"or exists (select 1 from posts p where p.forum = f.slug and not exists (select 1 from user_forum u where u.forum = f.slug and u.nickname = p.author)) " +
+		"or exists (select 1 from user_forum u where u.forum = f.slug and not exists (select 1 from threads where forum = f.slug and author = u.nickname) " +
+		"and not exists (select 1 from posts where forum = f.slug and author = u.nickname)) order by f.slug;"
+	serviceDriftForumActivity = "select u.forum, u.nickname, u.threads, a.threads, u.posts, a.posts, coalesce(u.joined, 0), a.joined, coalesce(u.last_active, 0), a.last_active from user_forum u " +
+		"join (select forum, author, sum(thread) as threads, sum(1 - thread) as posts, min(created) as joined, max(created) as last_active " +
+		"from (select forum, author, created, 1 as thread from threads union all select forum, author, created, 0 from posts) group by forum, author) a on a.forum = u.forum and a.author = u.nickname " +
+		"where u.threads is not a.threads or u.posts is not a.posts or u.joined is not a.joined or u.last_active is not a.last_active order by u.forum, u.nickname;"
+	serviceRepairForum      = "update forums set posts = posts + ?2, threads = threads + ?3, version = version + 1 where slug = ?1;"
+	serviceRepairThread     = "update threads set votes = votes + ?2, version = version + 1 where id = ?1;"
+	serviceRepairForumUsers = []string{
+		"insert or ignore into user_forum (nickname, forum) select author, forum from threads where forum = ?1 union select author, forum from posts where forum = ?1;",
+		"delete from user_forum where forum = ?1 and not exists (select 1 from threads where forum = user_forum.forum and author = user_forum.nickname) " +
+			"and not exists (select 1 from posts where forum = user_forum.forum and author = user_forum.nickname);",
+		serviceRepairForumActivity,
+	}
+	serviceRepairForumActivity = "update user_forum set (threads, posts, joined, last_active) = (select sum(thread), sum(1 - thread), min(created), max(created) " +
+		"from (select created, 1 as thread from threads where forum = ?1 and author = user_forum.nickname " +
+		"union all select created, 0 from posts where forum = ?1 and author = user_forum.nickname)) where forum = ?1;"
+
+	threadCreate  = "insert into threads (title, author, forum, message, slug, created) values (?1, ?2, ?3, ?4, ?5, ?6) returning id, version;"
+	threadGetSlug = "select id, title, author, forum, message, votes, slug, created, version from threads where slug = ?1 order by id limit 1;"
+	threadGetId   = "select id, title, author, forum, message, votes, slug, created, version from threads where id = ?1;"
+	threadUpdate  = "update threads set title = ?1, message = ?2, version = version + 1 where id = ?3 and (?4 = 0 or version = ?4) returning version;"
+
+	threadPostsBase = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts "
+
+	threadFlat          = "where thread = ?1 and id > ?2 order by id limit ?3;"
+	threadFlatDesc      = "where thread = ?1 and id < ?2 order by id desc limit ?3;"
+	threadFlatSince     = "where thread = ?1 order by id limit ?2;"
+	threadFlatSinceDesc = "where thread = ?1 order by id desc limit ?2;"
+
+	threadTree          = "where thread = ?1 and path > (select path from posts where id = ?2) order by path limit ?3;"
+	threadTreeDesc      = "where thread = ?1 and path < (select path from posts where id = ?2) order by path desc limit ?3;"
+	threadTreeSince     = "where thread = ?1 order by path limit ?2;"
+	threadTreeSinceDesc = "where thread = ?1 order by path desc limit ?2;"
+
+	threadParentTree          = "where root in (select id from posts where thread = ?1 and parent is null and id > (select root from posts where id = ?2) order by id limit ?3) order by path;"
+	threadParentTreeDesc      = "where root in (select id from posts where thread = ?1 and parent is null and id < (select root from posts where id = ?2) order by id desc limit ?3) order by root desc, path;"
+	threadParentTreeSince     = "where root in (select id from posts where thread = ?1 and parent is null order by id limit ?2) order by path;"
+	threadParentTreeSinceDesc = "where root in (select id from posts where thread = ?1 and parent is null order by id desc limit ?2) order by root desc, path;"
+
+	// the keys of the ranked tree are the places along the path, 10 digits each
+	threadRankedBase        = "with recursive ranked as (select id, parent, row_number() over (partition by parent order by "
+	threadRankTop           = "score desc, id"
+	threadRankControversial = "min(voters + score, voters - score) desc, voters desc, id"
+	threadRankedTree        = ") as place from posts where thread = ?1), tree as (select id, place as root, printf('%010d', place) as key from ranked where parent is null " +
+		"union all select r.id, t.root, t.key || '.' || printf('%010d', r.place) from ranked r join tree t on r.parent = t.id) " +
+		"select p.id, coalesce(p.parent, 0), p.author, p.message, p.is_edited, p.forum, p.thread, p.created, p.score from tree join posts p on p.id = tree.id "
+
+	threadRanked          = "where tree.root > (select root from tree where id = ?2) and tree.root <= (select root from tree where id = ?2) + ?3 order by tree.key;"
+	threadRankedDesc      = "where tree.root < (select root from tree where id = ?2) and tree.root >= (select root from tree where id = ?2) - ?3 order by tree.root desc, tree.key;"
+	threadRankedSince     = "where tree.root <= ?2 order by tree.key;"
+	threadRankedSinceDesc = "where tree.root > (select count(*) from ranked where parent is null) - ?2 order by tree.root desc, tree.key;"
+
+	userCreate     = "insert into users (nickname, fullname, about, email) values (?1, ?2, ?3, ?4);"
+	userUpdate     = "update users set fullname = ?1, about = ?2, email = ?3, version = version + 1 where nickname = ?4 and (?5 = 0 or version = ?5) returning fullname, about, email, reputation, version;"
+	userGet        = "select nickname, fullname, about, email, reputation, version from users where nickname = ?1;"
+	userGetSimilar = "select nickname, fullname, about, email, reputation from users where nickname = ?1 or email = ?2;"
+
+	userGetPosts            = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = ?1 order by id limit ?2;"
+	userGetPostsDesc        = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = ?1 order by id desc limit ?2;"
+	userGetPostsSince       = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = ?1 and id > ?2 order by id limit ?3;"
+	userGetPostsSinceDesc   = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = ?1 and id < ?2 order by id desc limit ?3;"
+	userGetThreads          = "select id, title, author, forum, message, votes, slug, created from threads where author = ?1 order by created asc limit ?2;"
+	userGetThreadsDesc      = "select id, title, author, forum, message, votes, slug, created from threads where author = ?1 order by created desc limit ?2;"
+	userGetThreadsSince     = "select id, title, author, forum, message, votes, slug, created from threads where author = ?1 and created >= ?2 order by created asc limit ?3;"
+	userGetThreadsSinceDesc = "select id, title, author, forum, message, votes, slug, created from threads where author = ?1 and created <= ?2 order by created desc limit ?3;"
+	userGetForumsBase       = "select f.slug, f.title, f.user_, (select count(*) from posts p where p.author = uf.nickname and p.forum = uf.forum) from user_forum uf join forums f on f.slug = uf.forum where uf.nickname = ?1 "
+	userGetForums           = "order by uf.forum limit ?2;"
+	userGetForumsDesc       = "order by uf.forum desc limit ?2;"
+	userGetForumsSince      = "and uf.forum > ?2 order by uf.forum limit ?3;"
+	userGetForumsSinceDesc  = "and uf.forum < ?2 order by uf.forum desc limit ?3;"
+
+	userExportThreads = "select id, title, author, forum, message, votes, slug, created from threads where author = ?1 order by id;"
+	userExportPosts   = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = ?1 order by id;"
+	userExportVotes   = "select nickname, thread, 0, voice from votes where nickname = ?1 union all " +
+		"select v.nickname, p.thread, v.post, v.voice from post_votes v join posts p on p.id = v.post where v.nickname = ?1 order by 2, 3;"
+	userTombstone      = "insert into users (nickname, fullname, about, email) values (?1, ?2, '', ?3) on conflict (nickname) do nothing;"
+	userTombstoneTaken = "select exists(select 1 from users where email = ?2 and nickname <> ?1);"
+	userLock           = "select nickname from users where nickname = ?1;"
+	userVotedThreads   = "select thread from votes where nickname = ?1;"
+	userDeleteVotes    = "delete from votes where nickname = ?1;"
+	userDeleteScores   = "delete from post_votes where nickname = ?1;"
+	userMoveForums     = "insert into user_forum (nickname, forum, reputation, threads, posts, joined, last_active) select ?2, forum, reputation, threads, posts, joined, last_active from user_forum where nickname = ?1 " +
+		"on conflict (nickname, forum) do update set reputation = user_forum.reputation + excluded.reputation, threads = user_forum.threads + excluded.threads, posts = user_forum.posts + excluded.posts, " +
+		"joined = min(user_forum.joined, excluded.joined), last_active = max(user_forum.last_active, excluded.last_active);"
+	userAddReputation = "update users set reputation = reputation + (select reputation from users where nickname = ?1), version = version + 1 where nickname = ?2;"
+	userMoveOwnForums = "update forums set user_ = ?2, version = version + 1 where user_ = ?1;"
+	userMoveThreads   = "update threads set author = ?2, version = version + 1 where author = ?1;"
+	userMovePosts     = "update posts set author = ?2, version = version + 1 where author = ?1;"
+	userDeleteForums  = "delete from user_forum where nickname = ?1;"
+	userMoveStats     = "insert or ignore into forum_stats_users (forum, hour, nickname) select forum, hour, ?2 from forum_stats_users where nickname = ?1;"
+	userDeleteStats   = "delete from forum_stats_users where nickname = ?1;"
+	userDelete        = "delete from users where nickname = ?1;"
+
+	// posts, votes, post_votes, user_forum and forum_stats_users reference users without on
+	// update cascade, and the cascade of forums and threads skips a change of
+	// case only, so the rename updates every reference with the foreign keys
+	// deferred
+	userRename = []string{
+		"update forums set user_ = ?2, version = version + 1 where user_ in (?1, ?2);",
+		"update threads set author = ?2, version = version + 1 where author in (?1, ?2);",
+		"update posts set author = ?2, version = version + 1 where author = ?1;",
+		"update votes set nickname = ?2 where nickname = ?1;",
+		"update post_votes set nickname = ?2 where nickname = ?1;",
+		"update user_forum set nickname = ?2 where nickname = ?1;",
+		"update forum_stats_users set nickname = ?2 where nickname = ?1;",
+		"update user_redirects set target = ?2 where target = ?1;",
+	}
+	userRenameDeferKeys = "pragma defer_foreign_keys = on;"
+	userRenameProfile   = "update users set nickname = ?2, version = version + 1 where nickname = ?1 and (?3 = 0 or version = ?3) returning nickname, fullname, about, email, reputation, version;"
+	userRedirectGet     = "select target from user_redirects where nickname = ?1 and created > ?2;"
+	userRedirectTaken   = "select exists(select 1 from user_redirects where nickname = ?1 and target <> ?2 and created > ?3);"
+	userRedirectPrune   = "delete from user_redirects where nickname = ?1 or created <= ?2;"
+	userRedirectSave    = "insert into user_redirects (nickname, target, created) values (?1, ?2, ?3) on conflict (nickname) do update set target = excluded.target, created = excluded.created;"
+
+	voteQuery       = "insert into votes (nickname, thread, voice) values (?1, ?2, ?3) on conflict (nickname, thread) do update set voice = excluded.voice;"
+	voteDelete      = "delete from votes where thread = ?1 and nickname = ?2;"
+	voteThreadTotal = "select votes, version from threads where id = ?1;"
+	voteTargets     = "select exists(select 1 from users where nickname = ?1), exists(select 1 from threads where id = ?2);"
+	voteCounts      = "select coalesce(sum(voice), 0), count(*) filter (where voice > 0), count(*) filter (where voice < 0) from votes where thread = ?1;"
+	voters          = "select nickname, voice from votes where thread = ?1 order by nickname limit ?2;"
+	votersDesc      = "select nickname, voice from votes where thread = ?1 order by nickname desc limit ?2;"
+	votersSince     = "select nickname, voice from votes where thread = ?1 and nickname > ?2 order by nickname limit ?3;"
+	votersSinceDesc = "select nickname, voice from votes where thread = ?1 and nickname < ?2 order by nickname desc limit ?3;"
+	postVote        = "insert into post_votes (nickname, post, voice) values (?1, ?2, ?3) on conflict (post, nickname) do update set voice = excluded.voice;"
+	postScore       = "select score, version from posts where id = ?1;"
+	postVoteTargets = "select exists(select 1 from users where nickname = ?1), exists(select 1 from posts where id = ?2);"
+
+	idempotencyGet           = "select key, request_hash, status, content_type, coalesce(body, x'') from idempotency_keys where key = ?1 and created > ?2;"
+	idempotencyReserve       = "insert into idempotency_keys (key, request_hash, created) values (?1, ?2, ?3) on conflict (key) do update set request_hash = excluded.request_hash, status = 0, content_type = '', body = null, created = excluded.created where idempotency_keys.created <= ?4 or (idempotency_keys.status = 0 and idempotency_keys.created <= ?5) returning key;"
+	idempotencySave          = "update idempotency_keys set status = ?3, content_type = ?4, body = ?5 where key = ?1 and request_hash = ?2 and status = 0;"
+	idempotencyRelease       = "delete from idempotency_keys where key = ?1 and request_hash = ?2 and status = 0;"
+	idempotencyDeleteExpired = "delete from idempotency_keys where created <= ?1;"
+)
@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"time"
+)
+
+func scanPosts(rows *sql.Rows) (*[]models.Post, error) {
+	posts := make([]models.Post, 0)
+	for rows.Next() {
+		post := models.Post{}
+		var created int64
+		err := rows.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &created, &post.Score)
+		if err != nil {
+			return nil, err
+		}
+		post.Created = fromMicro(created).Format(time.RFC3339)
+		posts = append(posts, post)
+	}
+	return &posts, rows.Err()
+}
+
+func scanThreads(rows *sql.Rows) (*[]models.Thread, error) {
+	threads := make([]models.Thread, 0)
+	for rows.Next() {
+		thread := models.Thread{}
+		var created int64
+		err := rows.Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &created)
+		if err != nil {
+			return nil, err
+		}
+		thread.Created = fromMicro(created)
+		threads = append(threads, thread)
+	}
+	return &threads, rows.Err()
+}
+
+func scanUsers(rows *sql.Rows) (*[]models.User, error) {
+	users := make([]models.User, 0)
+	for rows.Next() {
+		user := models.User{}
+		var about sql.NullString
+		err := rows.Scan(&user.Nickname, &user.Fullname, &about, &user.Email, &user.Reputation)
+		if err != nil {
+			return nil, err
+		}
+		user.About = about.String
+		users = append(users, user)
+	}
+	return &users, rows.Err()
+}
+
+func scanForumUsers(rows *sql.Rows) (*[]models.ForumUser, error) {
+	users := make([]models.ForumUser, 0)
+	for rows.Next() {
+		user := models.ForumUser{}
+		var about sql.NullString
+		var joined, lastActive int64
+		err := rows.Scan(&user.Nickname, &user.Fullname, &about, &user.Email, &user.Reputation, &user.Threads, &user.Posts, &joined, &lastActive)
+		if err != nil {
+			return nil, err
+		}
+		user.About = about.String
+		user.Joined = fromMicro(joined)
+		user.LastActive = fromMicro(lastActive)
+		users = append(users, user)
+	}
+	return &users, rows.Err()
+}
@@ -0,0 +1,352 @@
+-- Схема SQLite: citext заменён на collate nocase, path bigint[] на строку из
+-- id фиксированной ширины через точку, created хранится в микросекундах
+pragma foreign_keys = on;
+
+create table if not exists users
+(
+    nickname text collate nocase not null primary key,
+    fullname text                not null,
+    about    text,
+    email    text collate nocase not null unique,
+    reputation integer default 0,
+    version  integer default 1
+);
+
+create table if not exists forums
+(
+    title   text                not null,
+    user_   text collate nocase not null references users (nickname) on update cascade on delete cascade,
+    slug    text collate nocase not null primary key,
+    posts   integer default 0,
+    threads integer default 0,
+    version integer default 1
+);
+
+create table if not exists threads
+(
+    id      integer primary key autoincrement,
+    title   text                not null,
+    author  text collate nocase not null references users (nickname) on update cascade on delete cascade,
+    forum   text collate nocase not null references forums (slug) on update cascade on delete cascade,
+    message text                not null,
+    votes   integer default 0,
+    slug    text collate nocase,
+    created integer             not null,
+    version integer default 1
+);
+
+create table if not exists posts
+(
+    id        integer primary key autoincrement,
+    parent    integer references posts (id),
+    author    text collate nocase not null references users (nickname),
+    message   text                not null,
+    is_edited integer default 0,
+    forum     text collate nocase not null references forums (slug),
+    thread    integer             not null references threads (id),
+    created   integer             not null,
+    path      text    default '',
+    root      integer,
+    score     integer default 0,
+    voters    integer default 0,
+    version   integer default 1
+);
+
+create table if not exists votes
+(
+    nickname text collate nocase not null references users (nickname),
+    thread   integer             not null references threads (id),
+    voice    integer             not null,
+    constraint user_thread_key unique (nickname, thread)
+);
+
+create table if not exists post_votes
+(
+    nickname text collate nocase not null references users (nickname),
+    post     integer             not null references posts (id),
+    voice    integer             not null,
+    constraint user_post_key unique (post, nickname)
+);
+
+create table if not exists user_forum
+(
+    nickname text collate nocase not null references users (nickname),
+    forum    text collate nocase not null references forums (slug),
+    reputation integer default 0,
+    -- активность участника: его ветки и сообщения в форуме, первое и последнее из них
+    threads     integer default 0,
+    posts       integer default 0,
+    joined      integer,
+    last_active integer,
+    constraint user_forum_key unique (nickname, forum)
+);
+
+-- прежние nickname переименованных пользователей
+create table if not exists user_redirects
+(
+    nickname text collate nocase not null primary key,
+    target   text collate nocase not null references users (nickname) on update cascade on delete cascade,
+    created  integer             not null
+);
+
+-- активность форумов по часам: hour - начало часа в микросекундах
+create table if not exists forum_stats
+(
+    forum   text collate nocase not null references forums (slug),
+    hour    integer             not null,
+    threads integer default 0,
+    posts   integer default 0,
+    votes   integer default 0,
+    primary key (forum, hour)
+);
+
+create table if not exists forum_stats_users
+(
+    forum    text collate nocase not null references forums (slug),
+    hour     integer             not null,
+    nickname text collate nocase not null references users (nickname),
+    primary key (forum, hour, nickname)
+);
+
+create table if not exists idempotency_keys
+(
+    key          text primary key,
+    request_hash text    not null,
+    status       integer default 0,
+    content_type text    default '',
+    body         blob,
+    created      integer not null
+);
+
+create index if not exists threads_forum_created on threads (forum, created);
+create index if not exists threads_slug on threads (slug);
+create index if not exists posts_thread_id on posts (thread, id);
+create index if not exists posts_author_id on posts (author, id);
+create index if not exists threads_author_created on threads (author, created);
+create index if not exists posts_thread_path on posts (thread, path);
+create index if not exists posts_root_path on posts (root, path);
+create index if not exists user_forum_forum on user_forum (forum, nickname);
+create index if not exists user_forum_reputation on user_forum (forum, reputation desc, nickname);
+create index if not exists user_forum_activity on user_forum (forum, last_active, nickname);
+create index if not exists user_forum_posts on user_forum (forum, posts, nickname);
+create index if not exists user_forum_joined on user_forum (forum, joined, nickname);
+create index if not exists idempotency_keys_created on idempotency_keys (created);
+
+-- Триггеры
+create trigger if not exists create_new_thread
+    after insert
+    on threads
+    for each row
+begin
+    insert into user_forum (nickname, forum, threads, joined, last_active) values (new.author, new.forum, 1, new.created, new.created)
+    on conflict (nickname, forum) do update set threads     = threads + 1,
+                                                joined      = min(joined, excluded.joined),
+                                                last_active = max(last_active, excluded.last_active);
+    update forums set threads = threads + 1, version = version + 1 where slug = new.forum;
+end;
+
+-- path каждого поста: path родителя и собственный id из 19 цифр, поэтому
+-- строки сравниваются так же, как массивы bigint[]
+create trigger if not exists create_new_post
+    after insert
+    on posts
+    for each row
+begin
+    update posts
+    set path = coalesce((select path || '.' from posts where id = new.parent), '') || printf('%019d', new.id),
+        root = coalesce((select root from posts where id = new.parent), new.id)
+    where id = new.id;
+    insert into user_forum (nickname, forum, posts, joined, last_active) values (new.author, new.forum, 1, new.created, new.created)
+    on conflict (nickname, forum) do update set posts       = posts + 1,
+                                                joined      = min(joined, excluded.joined),
+                                                last_active = max(last_active, excluded.last_active);
+    update forums set posts = posts + 1, version = version + 1 where slug = new.forum;
+end;
+
+create trigger if not exists create_votes
+    after insert
+    on votes
+    for each row
+begin
+    update threads set votes = votes + new.voice, version = version + 1 where id = new.thread;
+end;
+
+create trigger if not exists update_votes
+    after update
+    on votes
+    for each row
+    when old.voice <> new.voice
+begin
+    update threads set votes = votes - old.voice + new.voice, version = version + 1 where id = new.thread;
+end;
+
+create trigger if not exists delete_votes
+    after delete
+    on votes
+    for each row
+begin
+    update threads set votes = votes - old.voice, version = version + 1 where id = old.thread;
+end;
+
+create trigger if not exists create_post_vote
+    after insert
+    on post_votes
+    for each row
+begin
+    update posts set score = score + new.voice, voters = voters + 1, version = version + 1 where id = new.post;
+end;
+
+create trigger if not exists update_post_vote
+    after update
+    on post_votes
+    for each row
+    when old.voice <> new.voice
+begin
+    update posts set score = score - old.voice + new.voice, version = version + 1 where id = new.post;
+end;
+
+create trigger if not exists delete_post_vote
+    after delete
+    on post_votes
+    for each row
+begin
+    update posts set score = score - old.voice, voters = voters - 1, version = version + 1 where id = old.post;
+end;
+
+-- репутация автора ветки или сообщения: всего и в форуме
+create trigger if not exists create_vote_reputation
+    after insert
+    on votes
+    for each row
+begin
+    update users set reputation = reputation + new.voice, version = version + 1 where nickname = (select author from threads where id = new.thread);
+    update user_forum set reputation = reputation + new.voice where (nickname, forum) = (select author, forum from threads where id = new.thread);
+end;
+
+create trigger if not exists update_vote_reputation
+    after update
+    on votes
+    for each row
+    when old.voice <> new.voice
+begin
+    update users set reputation = reputation - old.voice + new.voice, version = version + 1 where nickname = (select author from threads where id = new.thread);
+    update user_forum set reputation = reputation - old.voice + new.voice where (nickname, forum) = (select author, forum from threads where id = new.thread);
+end;
+
+create trigger if not exists delete_vote_reputation
+    after delete
+    on votes
+    for each row
+begin
+    update users set reputation = reputation - old.voice, version = version + 1 where nickname = (select author from threads where id = old.thread);
+    update user_forum set reputation = reputation - old.voice where (nickname, forum) = (select author, forum from threads where id = old.thread);
+end;
+
+create trigger if not exists create_post_vote_reputation
+    after insert
+    on post_votes
+    for each row
+begin
+    update users set reputation = reputation + new.voice, version = version + 1 where nickname = (select author from posts where id = new.post);
+    update user_forum set reputation = reputation + new.voice where (nickname, forum) = (select author, forum from posts where id = new.post);
+end;
+
+create trigger if not exists update_post_vote_reputation
+    after update
+    on post_votes
+    for each row
+    when old.voice <> new.voice
+begin
+    update users set reputation = reputation - old.voice + new.voice, version = version + 1 where nickname = (select author from posts where id = new.post);
+    update user_forum set reputation = reputation - old.voice + new.voice where (nickname, forum) = (select author, forum from posts where id = new.post);
+end;
+
+create trigger if not exists delete_post_vote_reputation
+    after delete
+    on post_votes
+    for each row
+begin
+    update users set reputation = reputation - old.voice, version = version + 1 where nickname = (select author from posts where id = old.post);
+    update user_forum set reputation = reputation - old.voice where (nickname, forum) = (select author, forum from posts where id = old.post);
+end;
+
+-- статистика форумов; голос считается, когда его отдают или меняют, по времени голосования
+create trigger if not exists count_thread
+    after insert
+    on threads
+    for each row
+begin
+    insert into forum_stats (forum, hour, threads) values (new.forum, new.created - new.created % 3600000000, 1)
+    on conflict (forum, hour) do update set threads = threads + 1;
+    insert into forum_stats_users (forum, hour, nickname) values (new.forum, new.created - new.created % 3600000000, new.author)
+    on conflict do nothing;
+end;
+
+create trigger if not exists count_post
+    after insert
+    on posts
+    for each row
+begin
+    insert into forum_stats (forum, hour, posts) values (new.forum, new.created - new.created % 3600000000, 1)
+    on conflict (forum, hour) do update set posts = posts + 1;
+    insert into forum_stats_users (forum, hour, nickname) values (new.forum, new.created - new.created % 3600000000, new.author)
+    on conflict do nothing;
+end;
+
+create trigger if not exists count_vote
+    after insert
+    on votes
+    for each row
+begin
+    insert into forum_stats (forum, hour, votes) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, 1 from threads where id = new.thread
+    on conflict (forum, hour) do update set votes = votes + 1;
+    insert into forum_stats_users (forum, hour, nickname) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, new.nickname from threads where id = new.thread
+    on conflict do nothing;
+end;
+
+create trigger if not exists count_vote_change
+    after update
+    on votes
+    for each row
+    when old.voice <> new.voice
+begin
+    insert into forum_stats (forum, hour, votes) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, 1 from threads where id = new.thread
+    on conflict (forum, hour) do update set votes = votes + 1;
+    insert into forum_stats_users (forum, hour, nickname) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, new.nickname from threads where id = new.thread
+    on conflict do nothing;
+end;
+
+create trigger if not exists count_post_vote
+    after insert
+    on post_votes
+    for each row
+begin
+    insert into forum_stats (forum, hour, votes) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, 1 from posts where id = new.post
+    on conflict (forum, hour) do update set votes = votes + 1;
+    insert into forum_stats_users (forum, hour, nickname) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, new.nickname from posts where id = new.post
+    on conflict do nothing;
+end;
+
+create trigger if not exists count_post_vote_change
+    after update
+    on post_votes
+    for each row
+    when old.voice <> new.voice
+begin
+    insert into forum_stats (forum, hour, votes) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, 1 from posts where id = new.post
+    on conflict (forum, hour) do update set votes = votes + 1;
+    insert into forum_stats_users (forum, hour, nickname) select forum, cast(strftime('%s', 'now') as integer) / 3600 * 3600000000, new.nickname from posts where id = new.post
+    on conflict do nothing;
+end;
+
+-- первое открытие базы со статистикой: ветки и сообщения по их created, прошлые голоса восстановить не из чего
+insert or ignore into forum_stats_users (forum, hour, nickname)
+select forum, created - created % 3600000000, author from threads where not exists (select 1 from forum_stats)
+union
+select forum, created - created % 3600000000, author from posts where not exists (select 1 from forum_stats);
+
+insert into forum_stats (forum, hour, threads, posts)
+select forum, created - created % 3600000000, sum(thread), sum(1 - thread)
+from (select forum, created, 1 as thread from threads union all select forum, created, 0 from posts)
+where not exists (select 1 from forum_stats)
+group by 1, 2;
@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+type ServiceRepositoryImpl struct {
+	db *DB
+}
+
+func MakeServiceRepository(db *DB) repositories.ServiceRepository {
+	return &ServiceRepositoryImpl{db: db}
+}
+
+func (serviceRepository *ServiceRepositoryImpl) ClearService() error {
+	tx, err := serviceRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range serviceClear {
+		if _, err = tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (serviceRepository *ServiceRepositoryImpl) GetService() (*models.Status, error) {
+	status := new(models.Status)
+	err := serviceRepository.db.QueryRow(serviceGet).Scan(&status.User, &status.Forum, &status.Thread, &status.Post)
+	return status, err
+}
+
+func (serviceRepository *ServiceRepositoryImpl) Reconcile(repair bool) (*models.Reconciliation, error) {
+	tx, err := serviceRepository.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reconciliation := &models.Reconciliation{Drifts: make([]models.CounterDrift, 0), Repaired: repair}
+	rows, err := tx.Query(serviceDriftForums)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var forum string
+		var posts, actualPosts, threads, actualThreads int64
+		if err = rows.Scan(&forum, &posts, &actualPosts, &threads, &actualThreads); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if posts != actualPosts {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumPosts, Forum: forum, Stored: posts, Actual: actualPosts})
+		}
+		if threads != actualThreads {
+			reconciliation.Drifts = append(reconciliation.Drifts,
+				models.CounterDrift{Counter: models.CounterForumThreads, Forum: forum, Stored: threads, Actual: actualThreads})
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = scanDrifts(tx, serviceDriftThreads, models.CounterThreadVotes, reconciliation); err != nil {
+		return nil, err
+	}
+	if err = scanDrifts(tx, serviceDriftForumUsers, models.CounterForumUsers, reconciliation); err != nil {
+		return nil, err
+	}
+	if err = scanActivityDrifts(tx, serviceDriftForumActivity, reconciliation); err != nil {
+		return nil, err
+	}
+	if !repair {
+		return reconciliation, nil
+	}
+
+	activityRepaired := make(map[string]bool)
+	for _, drift := range reconciliation.Drifts {
+		switch drift.Counter {
+		case models.CounterForumPosts:
+			_, err = tx.Exec(serviceRepairForum, drift.Forum, drift.Actual-drift.Stored, 0)
+		case models.CounterForumThreads:
+			_, err = tx.Exec(serviceRepairForum, drift.Forum, 0, drift.Actual-drift.Stored)
+		case models.CounterThreadVotes:
+			_, err = tx.Exec(serviceRepairThread, drift.Thread, drift.Actual-drift.Stored)
+		case models.CounterForumUsers:
+			for _, query := range serviceRepairForumUsers {
+				if _, err = tx.Exec(query, drift.Forum); err != nil {
+					break
+				}
+			}
+		case models.CounterForumUserThreads, models.CounterForumUserPosts, models.CounterForumUserJoined, models.CounterForumUserLastActive:
+			if !activityRepaired[drift.Forum] {
+				activityRepaired[drift.Forum] = true
+				_, err = tx.Exec(serviceRepairForumActivity, drift.Forum)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reconciliation, tx.Commit()
+}
+
+// scanDrifts reads rows of (forum or thread, stored, actual).
+func scanDrifts(tx *sql.Tx, query, counter string, reconciliation *models.Reconciliation) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		drift := models.CounterDrift{Counter: counter}
+		if counter == models.CounterThreadVotes {
+			err = rows.Scan(&drift.Thread, &drift.Stored, &drift.Actual)
+		} else {
+			err = rows.Scan(&drift.Forum, &drift.Stored, &drift.Actual)
+		}
+		if err != nil {
+			return err
+		}
+		reconciliation.Drifts = append(reconciliation.Drifts, drift)
+	}
+	return rows.Err()
+}
+
+// scanActivityDrifts reads rows of (forum, nickname) followed by the stored and
+// actual threads, posts, joined and last_active, one drift per pair that differs.
+func scanActivityDrifts(tx *sql.Tx, query string, reconciliation *models.Reconciliation) error {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	counters := []string{models.CounterForumUserThreads, models.CounterForumUserPosts, models.CounterForumUserJoined, models.CounterForumUserLastActive}
+	for rows.Next() {
+		var forum, nickname string
+		var values [8]int64
+		err = rows.Scan(&forum, &nickname, &values[0], &values[1], &values[2], &values[3], &values[4], &values[5], &values[6], &values[7])
+		if err != nil {
+			return err
+		}
+		for i, counter := range counters {
+			if stored, actual := values[2*i], values[2*i+1]; stored != actual {
+				reconciliation.Drifts = append(reconciliation.Drifts,
+					models.CounterDrift{Counter: counter, Forum: forum, Nickname: nickname, Stored: stored, Actual: actual})
+			}
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"db_forum/app/models"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReconcileActivity(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	users, forums, threads := MakeUserRepository(db), MakeForumRepository(db), MakeThreadRepository(db)
+
+	if err = users.CreateUser(&models.User{Nickname: "alice", Fullname: "Alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = forums.CreateForum(&models.Forum{Title: "Forum", User: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	thread := &models.Thread{Title: "Thread", Author: "alice", Forum: "forum", Message: "Message"}
+	if err = threads.CreateThread(thread); err != nil {
+		t.Fatal(err)
+	}
+	if err = threads.CreateThreadPosts(thread, &models.Posts{{Author: "alice", Message: "1"}, {Author: "alice", Message: "2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec("update user_forum set threads = 3, posts = 1 where nickname = 'alice';"); err != nil {
+		t.Fatal(err)
+	}
+
+	service := MakeServiceRepository(db)
+	reconciliation, err := service.Reconcile(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.CounterDrift{
+		{Counter: models.CounterForumUserThreads, Forum: "forum", Nickname: "alice", Stored: 3, Actual: 1},
+		{Counter: models.CounterForumUserPosts, Forum: "forum", Nickname: "alice", Stored: 1, Actual: 2},
+	}
+	if !reflect.DeepEqual(reconciliation.Drifts, want) {
+		t.Fatalf("drifts = %+v, want %+v", reconciliation.Drifts, want)
+	}
+
+	if reconciliation, err = service.Reconcile(false); err != nil {
+		t.Fatal(err)
+	}
+	if len(reconciliation.Drifts) != 0 {
+		t.Errorf("drifts after the repair = %+v", reconciliation.Drifts)
+	}
+}
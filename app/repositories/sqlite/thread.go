@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"time"
+)
+
+type ThreadRepositoryImpl struct {
+	db *DB
+}
+
+func MakeThreadRepository(db *DB) repositories.ThreadRepository {
+	return &ThreadRepositoryImpl{db: db}
+}
+
+func (threadRepository *ThreadRepositoryImpl) scanThread(row *sql.Row) (*models.Thread, error) {
+	thread := new(models.Thread)
+	var created int64
+	err := row.Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &created, &thread.Version)
+	if err == nil {
+		thread.Created = fromMicro(created)
+	}
+	return thread, err
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetBySlug(slug string) (*models.Thread, error) {
+	return threadRepository.scanThread(threadRepository.db.QueryRow(threadGetSlug, slug))
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetById(id int64) (*models.Thread, error) {
+	return threadRepository.scanThread(threadRepository.db.QueryRow(threadGetId, id))
+}
+
+func (threadRepository *ThreadRepositoryImpl) CreateThread(thread *models.Thread) error {
+	// the stored value is truncated to microseconds like a postgres timestamptz
+	thread.Created = fromMicro(toMicro(thread.Created))
+	return threadRepository.db.QueryRow(threadCreate, thread.Title, thread.Author, thread.Forum, thread.Message, thread.Slug, toMicro(thread.Created)).
+		Scan(&thread.Id, &thread.Version)
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThread(slugOrId interface{}) (*models.Thread, error) {
+	switch value := slugOrId.(type) {
+	case int64:
+		return threadRepository.GetById(value)
+	case string:
+		return threadRepository.GetBySlug(value)
+	}
+	return &models.Thread{}, sql.ErrNoRows
+}
+
+func (threadRepository *ThreadRepositoryImpl) UpdateThread(thread *models.Thread) error {
+	err := threadRepository.db.QueryRow(threadUpdate, thread.Title, thread.Message, thread.Id, thread.Version).Scan(&thread.Version)
+	if err == sql.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	return err
+}
+
+func (threadRepository *ThreadRepositoryImpl) CreateThreadPosts(thread *models.Thread, posts *models.Posts) error {
+	created := time.Now()
+	createdFormatted := created.Format(time.RFC3339)
+
+	tx, err := threadRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statement, err := tx.Prepare(postCreate)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	events := make([]*models.ThreadEvent, 0, len(*posts))
+	for i := range *posts {
+		post := &(*posts)[i]
+		post.Forum = thread.Forum
+		post.Thread = thread.Id
+		post.Created = createdFormatted
+
+		var parent interface{}
+		if post.Parent != 0 {
+			// the schema does not check that the parent is in the same thread
+			var parentThread int64
+			err = tx.QueryRow(postThread, post.Parent).Scan(&parentThread)
+			if err == sql.ErrNoRows {
+				return pkg.ErrParentPostNotExist
+			}
+			if err != nil {
+				return err
+			}
+			if parentThread != thread.Id {
+				return pkg.ErrParentPostFromOtherThread
+			}
+			parent = post.Parent
+		}
+		err = statement.QueryRow(parent, post.Author, post.Message, thread.Forum, thread.Id, toMicro(created)).Scan(&post.Id)
+		if err != nil {
+			return missingReference(tx, postCreateTargets, post.Author, post.Parent, pkg.ErrParentPostNotExist, err)
+		}
+		events = append(events, &models.ThreadEvent{Type: "post", Thread: thread.Id, Id: post.Id})
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	threadRepository.db.publish(events...)
+	return nil
+}
+
+func (threadRepository *ThreadRepositoryImpl) getPosts(id int64, limit, since int, desc bool, base, query, queryDesc, querySince, querySinceDesc string) (*[]models.Post, error) {
+	var rows *sql.Rows
+	var err error
+	if since == -1 {
+		if desc {
+			rows, err = threadRepository.db.Query(base+querySinceDesc, id, limit)
+		} else {
+			rows, err = threadRepository.db.Query(base+querySince, id, limit)
+		}
+	} else {
+		if desc {
+			rows, err = threadRepository.db.Query(base+queryDesc, id, since, limit)
+		} else {
+			rows, err = threadRepository.db.Query(base+query, id, since, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPosts(rows)
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsFlat(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	return threadRepository.getPosts(id, limit, since, desc, threadPostsBase, threadFlat, threadFlatDesc, threadFlatSince, threadFlatSinceDesc)
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsTree(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	return threadRepository.getPosts(id, limit, since, desc, threadPostsBase, threadTree, threadTreeDesc, threadTreeSince, threadTreeSinceDesc)
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsParentTree(id int64, limit, since int, desc bool) (*[]models.Post, error) {
+	return threadRepository.getPosts(id, limit, since, desc, threadPostsBase, threadParentTree, threadParentTreeDesc, threadParentTreeSince, threadParentTreeSinceDesc)
+}
+
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsRanked(id int64, rank string, limit, since int, desc bool) (*[]models.Post, error) {
+	order := threadRankTop
+	if rank == "controversial" {
+		order = threadRankControversial
+	}
+	return threadRepository.getPosts(id, limit, since, desc, threadRankedBase+order+threadRankedTree, threadRanked, threadRankedDesc, threadRankedSince, threadRankedSinceDesc)
+}
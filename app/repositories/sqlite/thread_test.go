@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateThreadPostsReferences(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	users, forums, threads := MakeUserRepository(db), MakeForumRepository(db), MakeThreadRepository(db)
+
+	if err = users.CreateUser(&models.User{Nickname: "alice", Fullname: "Alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = forums.CreateForum(&models.Forum{Title: "Forum", User: "alice", Slug: "forum"}); err != nil {
+		t.Fatal(err)
+	}
+	thread := &models.Thread{Title: "Thread", Author: "alice", Forum: "forum", Message: "Message"}
+	other := &models.Thread{Title: "Other", Author: "alice", Forum: "forum", Message: "Message"}
+	for _, created := range []*models.Thread{thread, other} {
+		if err = threads.CreateThread(created); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = threads.CreateThreadPosts(thread, &models.Posts{{Author: "alice", Message: "Root"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		thread *models.Thread
+		post   models.Post
+		want   error
+	}{
+		{"missing author", thread, models.Post{Author: "carol", Message: "Post"}, pkg.ErrUserNotFound},
+		{"missing parent", thread, models.Post{Author: "alice", Message: "Post", Parent: 99}, pkg.ErrParentPostNotExist},
+		{"parent in another thread", other, models.Post{Author: "alice", Message: "Post", Parent: 1}, pkg.ErrParentPostFromOtherThread},
+		{"reply", thread, models.Post{Author: "alice", Message: "Post", Parent: 1}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := threads.CreateThreadPosts(test.thread, &models.Posts{test.post}); err != test.want {
+				t.Fatalf("CreateThreadPosts() = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
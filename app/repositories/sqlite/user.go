@@ -0,0 +1,323 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+type UserRepositoryImpl struct {
+	db *DB
+}
+
+func MakeUserRepository(db *DB) repositories.UserRepository {
+	return &UserRepositoryImpl{db: db}
+}
+
+func (userRepository *UserRepositoryImpl) CreateUser(user *models.User) error {
+	_, err := userRepository.db.Exec(userCreate, user.Nickname, user.Fullname, user.About, user.Email)
+	return err
+}
+
+func (userRepository *UserRepositoryImpl) UpdateUser(user *models.User) error {
+	err := userRepository.db.QueryRow(userUpdate, user.Fullname, user.About, user.Email, user.Nickname, user.Version).Scan(&user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err == sql.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	return err
+}
+
+func (userRepository *UserRepositoryImpl) GetInfoAboutUser(nickname string) (*models.User, error) {
+	user := new(models.User)
+	err := userRepository.db.QueryRow(userGet, nickname).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	return user, err
+}
+
+func (userRepository *UserRepositoryImpl) GetSimilarUsers(user *models.User) (*[]models.User, error) {
+	rows, err := userRepository.db.Query(userGetSimilar, user.Nickname, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanUsers(rows)
+}
+
+func (userRepository *UserRepositoryImpl) GetUserPosts(nickname string, limit, since int, desc bool) (*[]models.Post, error) {
+	var rows *sql.Rows
+	var err error
+	if since != -1 {
+		if desc {
+			rows, err = userRepository.db.Query(userGetPostsSinceDesc, nickname, since, limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetPostsSince, nickname, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(userGetPostsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetPosts, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPosts(rows)
+}
+
+func (userRepository *UserRepositoryImpl) GetUserThreads(nickname string, limit int, since string, desc bool) (*[]models.Thread, error) {
+	var rows *sql.Rows
+	var err error
+	if since != "" {
+		var sinceTime time.Time
+		sinceTime, err = time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return nil, err
+		}
+		if desc {
+			rows, err = userRepository.db.Query(userGetThreadsSinceDesc, nickname, toMicro(sinceTime), limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetThreadsSince, nickname, toMicro(sinceTime), limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(userGetThreadsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetThreads, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+func (userRepository *UserRepositoryImpl) GetUserForums(nickname string, limit int, since string, desc bool) (*[]models.UserForum, error) {
+	var rows *sql.Rows
+	var err error
+	if since != "" {
+		if desc {
+			rows, err = userRepository.db.Query(userGetForumsBase+userGetForumsSinceDesc, nickname, since, limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetForumsBase+userGetForumsSince, nickname, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(userGetForumsBase+userGetForumsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(userGetForumsBase+userGetForums, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	forums := make([]models.UserForum, 0)
+	for rows.Next() {
+		forum := models.UserForum{}
+		if err = rows.Scan(&forum.Slug, &forum.Title, &forum.User, &forum.Posts); err != nil {
+			return nil, err
+		}
+		forums = append(forums, forum)
+	}
+	return &forums, rows.Err()
+}
+
+func (userRepository *UserRepositoryImpl) ExportUser(nickname string) (*models.UserExport, error) {
+	tx, err := userRepository.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	export := &models.UserExport{User: new(models.User)}
+	user := export.User
+	err = tx.QueryRow(userGet, nickname).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(userExportThreads, nickname)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := scanThreads(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	export.Threads = *threads
+
+	rows, err = tx.Query(userExportPosts, nickname)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := scanPosts(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	export.Posts = *posts
+
+	rows, err = tx.Query(userExportVotes, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	export.Votes = make(models.TransferVotes, 0)
+	for rows.Next() {
+		vote := models.TransferVote{}
+		if err = rows.Scan(&vote.Nickname, &vote.Thread, &vote.Post, &vote.Voice); err != nil {
+			return nil, err
+		}
+		export.Votes = append(export.Votes, vote)
+	}
+	return export, rows.Err()
+}
+
+// DeleteUser hands the forums, threads and posts of the user over to the
+// tombstone account along with their reputation, then deletes the user and
+// their votes. The triggers fix the counters, the threads that lost votes are
+// reported to the listeners.
+func (userRepository *UserRepositoryImpl) DeleteUser(nickname string) error {
+	tx, err := userRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tombstone := models.DeletedUser
+	var taken bool
+	if err = tx.QueryRow(userTombstoneTaken, tombstone.Nickname, tombstone.Email).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return pkg.ErrTombstoneTaken
+	}
+	if _, err = tx.Exec(userTombstone, tombstone.Nickname, tombstone.Fullname, tombstone.Email); err != nil {
+		return err
+	}
+	if err = tx.QueryRow(userLock, nickname).Scan(&nickname); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(userVotedThreads, nickname)
+	if err != nil {
+		return err
+	}
+	var threads []int64
+	for rows.Next() {
+		var thread int64
+		if err = rows.Scan(&thread); err != nil {
+			rows.Close()
+			return err
+		}
+		threads = append(threads, thread)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, query := range []string{userDeleteVotes, userDeleteScores} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return err
+		}
+	}
+	// the forums, threads and posts are moved before the user is deleted: the
+	// foreign keys of forums and threads cascade on delete
+	for _, query := range []string{userMoveForums, userAddReputation, userMoveOwnForums, userMoveThreads, userMovePosts, userMoveStats} {
+		if _, err = tx.Exec(query, nickname, tombstone.Nickname); err != nil {
+			return err
+		}
+	}
+	for _, query := range []string{userDeleteForums, userDeleteStats, userDelete} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return err
+		}
+	}
+
+	events := make([]*models.ThreadEvent, 0, len(threads))
+	for _, thread := range threads {
+		event := &models.ThreadEvent{Type: "vote", Thread: thread}
+		if err = tx.QueryRow(voteThreadTotal, thread).Scan(&event.Votes, new(int64)); err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	userRepository.db.publish(events...)
+	return nil
+}
+
+// RenameUser changes the nickname of user.Nickname, conditioned on its Version
+// like UpdateUser, and fills user with the renamed profile. The old nickname
+// redirects to the new one for ttl unless only its case changed.
+func (userRepository *UserRepositoryImpl) RenameUser(user *models.User, nickname string, ttl time.Duration) error {
+	tx, err := userRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var taken bool
+	if err = tx.QueryRow(userRedirectTaken, nickname, user.Nickname, toMicro(now.Add(-ttl))).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return pkg.ErrUserAlreadyExist
+	}
+	if _, err = tx.Exec(userRenameDeferKeys); err != nil {
+		return err
+	}
+	previous := user.Nickname
+	err = tx.QueryRow(userRenameProfile, previous, nickname, user.Version).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err == sql.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	if uniqueViolation(err) {
+		// taken since the use case looked the nickname up
+		return pkg.ErrUserAlreadyExist
+	}
+	if err != nil {
+		return err
+	}
+	for _, query := range userRename {
+		if _, err = tx.Exec(query, previous, nickname); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.Exec(userRedirectPrune, nickname, toMicro(now.Add(-ttl))); err != nil {
+		return err
+	}
+	if !strings.EqualFold(previous, nickname) {
+		if _, err = tx.Exec(userRedirectSave, previous, nickname, toMicro(now)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (userRepository *UserRepositoryImpl) GetUserRedirect(nickname string, ttl time.Duration) (string, error) {
+	var target string
+	err := userRepository.db.QueryRow(userRedirectGet, nickname, toMicro(time.Now().Add(-ttl))).Scan(&target)
+	return target, err
+}
+
+// uniqueViolation reports whether err is a violation of a primary key or
+// unique constraint.
+func uniqueViolation(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+)
+
+type VoteRepositoryImpl struct {
+	db *DB
+}
+
+func MakeVoteRepository(db *DB) repositories.VoteRepository {
+	return &VoteRepositoryImpl{db: db}
+}
+
+func (voteRepository *VoteRepositoryImpl) VoteForThread(thread *models.Thread, vote *models.Vote) error {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(voteQuery, vote.Nickname, thread.Id, vote.Voice); err != nil {
+		return missingReference(tx, voteTargets, vote.Nickname, thread.Id, pkg.ErrThreadNotFound, err)
+	}
+	if err = tx.QueryRow(voteThreadTotal, thread.Id).Scan(&thread.Votes, &thread.Version); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	voteRepository.db.publish(&models.ThreadEvent{Type: "vote", Thread: thread.Id, Votes: thread.Votes})
+	return nil
+}
+
+func (voteRepository *VoteRepositoryImpl) VoteForPost(post *models.Post, vote *models.Vote) error {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(postVote, vote.Nickname, post.Id, vote.Voice); err != nil {
+		return missingReference(tx, postVoteTargets, vote.Nickname, post.Id, pkg.ErrPostNotFound, err)
+	}
+	if err = tx.QueryRow(postScore, post.Id).Scan(&post.Score, &post.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (voteRepository *VoteRepositoryImpl) DeleteVote(thread *models.Thread, nickname string) error {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(voteDelete, thread.Id, nickname)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pkg.ErrVoteNotFound
+	}
+	if err = tx.QueryRow(voteThreadTotal, thread.Id).Scan(&thread.Votes, &thread.Version); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	voteRepository.db.publish(&models.ThreadEvent{Type: "vote", Thread: thread.Id, Votes: thread.Votes})
+	return nil
+}
+
+func (voteRepository *VoteRepositoryImpl) GetThreadVoters(id int64, limit int, since string, desc bool) (*models.ThreadVoters, error) {
+	threadVoters := &models.ThreadVoters{Voters: []models.Voter{}}
+	err := voteRepository.db.QueryRow(voteCounts, id).Scan(&threadVoters.Votes, &threadVoters.Upvotes, &threadVoters.Downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *sql.Rows
+	if since == "" {
+		if desc {
+			rows, err = voteRepository.db.Query(votersDesc, id, limit)
+		} else {
+			rows, err = voteRepository.db.Query(voters, id, limit)
+		}
+	} else {
+		if desc {
+			rows, err = voteRepository.db.Query(votersSinceDesc, id, since, limit)
+		} else {
+			rows, err = voteRepository.db.Query(votersSince, id, since, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		voter := models.Voter{}
+		if err = rows.Scan(&voter.Nickname, &voter.Voice); err != nil {
+			return nil, err
+		}
+		threadVoters.Voters = append(threadVoters.Voters, voter)
+	}
+	return threadVoters, rows.Err()
+}
+
+// missingReference tells which side of a failed vote is missing: SQLite does
+// not name the violated foreign key, so the voter and the target are looked up
+// in the transaction. err is returned when both exist.
+func missingReference(tx *sql.Tx, query, nickname string, id int64, targetNotFound, err error) error {
+	var userExists, targetExists bool
+	if errCheck := tx.QueryRow(query, nickname, id).Scan(&userExists, &targetExists); errCheck != nil {
+		return err
+	}
+	switch {
+	case !targetExists:
+		return targetNotFound
+	case !userExists:
+		return pkg.ErrUserNotFound
+	}
+	return err
+}
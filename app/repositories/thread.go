@@ -5,7 +5,6 @@ import (
 	"db_forum/pkg"
 	"db_forum/pkg/handlerows"
 	"db_forum/pkg/queries"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -17,12 +16,12 @@ import (
 type ThreadRepository interface {
 	CreateThread(thread *models.Thread) (err error)
 	GetThread(slugOrId interface{}) (*models.Thread, error)
-	GetThreadVotes(id int64) (votesAmount int32, err error)
 	UpdateThread(thread *models.Thread) error
 	CreateThreadPosts(thread *models.Thread, posts *models.Posts) error
 	GetThreadPostsTree(id int64, limit, since int, desc bool) (*[]models.Post, error)
 	GetThreadPostsParentTree(id int64, limit, since int, desc bool) (posts *[]models.Post, err error)
 	GetThreadPostsFlat(id int64, limit, since int, desc bool) (posts *[]models.Post, err error)
+	GetThreadPostsRanked(id int64, rank string, limit, since int, desc bool) (posts *[]models.Post, err error)
 	GetBySlug(slug string) (thread *models.Thread, err error)
 	GetById(id int64) (thread *models.Thread, err error)
 }
@@ -38,14 +37,14 @@ func MakeThreadRepository(db *pgx.ConnPool) ThreadRepository {
 func (threadRepository *ThreadRepositoryImpl) GetBySlug(slug string) (thread *models.Thread, err error) {
 	thread = &models.Thread{}
 	err = threadRepository.db.QueryRow(queries.ThreadGetSlug, slug).
-		Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created)
+		Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created, &thread.Version)
 	return
 }
 
 func (threadRepository *ThreadRepositoryImpl) GetById(id int64) (thread *models.Thread, err error) {
 	thread = &models.Thread{}
 	err = threadRepository.db.QueryRow(queries.ThreadGetId, id).
-		Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created)
+		Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created, &thread.Version)
 	return
 }
 
@@ -53,7 +52,8 @@ func (threadRepository *ThreadRepositoryImpl) CreateThread(thread *models.Thread
 	err = threadRepository.db.QueryRow(queries.ThreadCreate, thread.Title, thread.Author, thread.Forum, thread.Message, thread.Slug, thread.Created).
 		Scan(
 			&thread.Id,
-			&thread.Created)
+			&thread.Created,
+			&thread.Version)
 	return
 }
 
@@ -63,95 +63,170 @@ func (threadRepository *ThreadRepositoryImpl) GetThread(slugOrId interface{}) (*
 	switch slugOrId.(type) {
 	case string:
 		err = threadRepository.db.QueryRow(queries.ThreadGetSlug, slugOrId).
-			Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created)
+			Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created, &thread.Version)
 	case int64:
 		id, _ := strconv.Atoi(slugOrId.(string))
 		err = threadRepository.db.QueryRow(queries.ThreadGetId, int64(id)).
-			Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created)
+			Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Votes, &thread.Slug, &thread.Created, &thread.Version)
 	}
 	return thread, err
 }
 
-func (threadRepository *ThreadRepositoryImpl) GetThreadVotes(id int64) (int32, error) {
-	var votes int32
-	err := threadRepository.db.QueryRow(queries.ThreadVotes, id).Scan(&votes)
-	return votes, err
-}
-
 func (threadRepository *ThreadRepositoryImpl) UpdateThread(thread *models.Thread) error {
-	_, err := threadRepository.db.Exec(queries.ThreadUpdate, thread.Title, thread.Message, thread.Id)
+	err := threadRepository.db.QueryRow(queries.ThreadUpdate, thread.Title, thread.Message, thread.Id, thread.Version).Scan(&thread.Version)
+	if err == pgx.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
 	return err
 }
 
-func (threadRepository *ThreadRepositoryImpl) createPartPosts(thread *models.Thread, posts *models.Posts, from, to int, created time.Time, createdFormatted string) (err error) {
-
-	args := make([]interface{}, 0, 0)
-	query := queries.PostPart
-	j := 0
-	for i := from; i < to; i++ {
-		(*posts)[i].Forum = thread.Forum
-		(*posts)[i].Thread = thread.Id
-		(*posts)[i].Created = createdFormatted
-		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d),", j*6+1, j*6+2, j*6+3, j*6+4, j*6+5, j*6+6)
-		if (*posts)[i].Parent != 0 {
-			args = append(args, (*posts)[i].Parent, (*posts)[i].Author, (*posts)[i].Message, thread.Forum, thread.Id, created)
-		} else {
-			args = append(args, nil, (*posts)[i].Author, (*posts)[i].Message, thread.Forum, thread.Id, created)
-		}
-		j++
+// CreateThreadPosts writes the posts in one transaction with COPY. Their ids
+// are taken from the sequence up front, so the paths are computed here; the
+// per row path, counter and user_forum triggers are off for the transaction
+// and their work is done once for the whole batch.
+func (threadRepository *ThreadRepositoryImpl) CreateThreadPosts(thread *models.Thread, posts *models.Posts) (err error) {
+	if len(*posts) == 0 {
+		return
 	}
-	query = query[:len(query)-1]
-	query += " RETURNING id;"
-
-	isSuccess := false
-	k := 0
-
-	for !isSuccess {
+	created := time.Now()
+	createdFormatted := created.Format(time.RFC3339)
 
-		resultRows, err := threadRepository.db.Query(query, args...)
+	tx, err := threadRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
 		if err != nil {
-			fmt.Println(err)
-			return pkg.ErrParentPostNotExist
+			_ = tx.Rollback()
+			return
 		}
-		defer resultRows.Close()
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(queries.PostBulk); err != nil {
+		return
+	}
+	ids, err := reservePostIds(tx, len(*posts))
+	if err != nil {
+		return
+	}
+	paths, err := parentPaths(tx, thread.Id, posts)
+	if err != nil {
+		return
+	}
 
-		for i := from; resultRows.Next(); i++ {
-			isSuccess = true
-			var id int64
-			if err = resultRows.Scan(&id); err != nil {
-				return err
+	rows := make([][]interface{}, 0, len(*posts))
+	authors := make(map[string]string)
+	counts := make(map[string]int32)
+	for i := range *posts {
+		post := &(*posts)[i]
+		post.Id = ids[i]
+		post.Forum = thread.Forum
+		post.Thread = thread.Id
+		post.Created = createdFormatted
+
+		var parent interface{}
+		var path []int64
+		if post.Parent != 0 {
+			parentPath, ok := paths[post.Parent]
+			if !ok {
+				return parentError(tx, post.Parent)
 			}
-			(*posts)[i].Id = id
+			parent = post.Parent
+			path = append(path, parentPath...)
 		}
-		k++
-		if k >= 3 {
-			break
+		path = append(path, post.Id)
+		paths[post.Id] = path
+
+		rows = append(rows, []interface{}{post.Id, parent, post.Author, post.Message, thread.Forum, thread.Id, created, path})
+		authors[strings.ToLower(post.Author)] = post.Author
+		counts[strings.ToLower(post.Author)]++
+	}
+
+	columns := []string{"id", "parent", "author", "message", "forum", "thread", "created", "path"}
+	if _, err = tx.CopyFrom(pgx.Identifier{"posts"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		// the author is the only reference left to check
+		if foreignKeyViolation(err, "posts_author_fkey") {
+			return pkg.ErrUserNotFound
 		}
+		return
+	}
+
+	nicknames := make([]string, 0, len(authors))
+	postCounts := make([]int32, 0, len(authors))
+	for key, author := range authors {
+		nicknames = append(nicknames, author)
+		postCounts = append(postCounts, counts[key])
+	}
+	if _, err = tx.Exec(queries.PostForumUsers, nicknames, thread.Forum, postCounts, created); err != nil {
+		return
+	}
+	if _, err = tx.Exec(queries.PostForumStats, nicknames, thread.Forum, postCounts, created); err != nil {
+		return
 	}
+	_, err = tx.Exec(queries.PostForumDelta, thread.Forum, len(*posts))
 	return
 }
 
-func (threadRepository *ThreadRepositoryImpl) CreateThreadPosts(thread *models.Thread, posts *models.Posts) (err error) {
-	created := time.Now()
-	createdFormatted := created.Format(time.RFC3339)
+func reservePostIds(tx *pgx.Tx, count int) ([]int64, error) {
+	rows, err := tx.Query(queries.PostNextIds, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	parts := len(*posts) / 20
-	for i := 0; i < parts+1; i++ {
-		if i == parts {
-			if i*20 != len(*posts) {
-				err = threadRepository.createPartPosts(thread, posts, i*20, len(*posts), created, createdFormatted)
-				if err != nil {
-					return err
-				}
-			}
-		} else {
-			err = threadRepository.createPartPosts(thread, posts, i*20, i*20+20, created, createdFormatted)
-			if err != nil {
-				return err
-			}
+	ids := make([]int64, 0, count)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
 	}
-	return
+	return ids, rows.Err()
+}
+
+// parentPaths reads the paths of the stored parents of the posts in the
+// thread, parents within the batch are added as their paths are computed.
+func parentPaths(tx *pgx.Tx, thread int64, posts *models.Posts) (map[int64][]int64, error) {
+	parents := make([]int64, 0)
+	for _, post := range *posts {
+		if post.Parent != 0 {
+			parents = append(parents, post.Parent)
+		}
+	}
+	paths := make(map[int64][]int64, len(*posts)+len(parents))
+	if len(parents) == 0 {
+		return paths, nil
+	}
+
+	rows, err := tx.Query(queries.PostPaths, parents, thread)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var path []int64
+		if err = rows.Scan(&id, &path); err != nil {
+			return nil, err
+		}
+		paths[id] = path
+	}
+	return paths, rows.Err()
+}
+
+// parentError tells a parent stored in another thread from a missing one.
+func parentError(tx *pgx.Tx, parent int64) error {
+	var exists bool
+	if err := tx.QueryRow(queries.PostExists, parent).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return pkg.ErrParentPostFromOtherThread
+	}
+	return pkg.ErrParentPostNotExist
 }
 
 func (threadRepository *ThreadRepositoryImpl) GetThreadPostsTree(id int64, limit, since int, desc bool) (*[]models.Post, error) {
@@ -224,3 +299,34 @@ func (threadRepository *ThreadRepositoryImpl) GetThreadPostsFlat(id int64, limit
 	defer rows.Close()
 	return handlerows.Post(rows)
 }
+
+// GetThreadPostsRanked returns the posts as parent_tree does, ordered by score
+// (rank "top") or by how evenly the votes split ("controversial") instead of
+// by id among the siblings of every level.
+func (threadRepository *ThreadRepositoryImpl) GetThreadPostsRanked(id int64, rank string, limit, since int, desc bool) (*[]models.Post, error) {
+	order := queries.ThreadRankTop
+	if rank == "controversial" {
+		order = queries.ThreadRankControversial
+	}
+
+	var rows *pgx.Rows
+	var err error
+	if since == -1 {
+		if desc {
+			rows, err = threadRepository.db.Query(strings.Join([]string{queries.ThreadRankedBase, order, queries.ThreadRankedTree, queries.ThreadRankedSinceDesc}, ""), id, limit)
+		} else {
+			rows, err = threadRepository.db.Query(strings.Join([]string{queries.ThreadRankedBase, order, queries.ThreadRankedTree, queries.ThreadRankedSince}, ""), id, limit)
+		}
+	} else {
+		if desc {
+			rows, err = threadRepository.db.Query(strings.Join([]string{queries.ThreadRankedBase, order, queries.ThreadRankedTree, queries.ThreadRankedDesc}, ""), id, since, limit)
+		} else {
+			rows, err = threadRepository.db.Query(strings.Join([]string{queries.ThreadRankedBase, order, queries.ThreadRankedTree, queries.ThreadRanked}, ""), id, since, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return handlerows.Post(rows)
+}
@@ -1,11 +1,15 @@
 package repositories
 
 import (
+	"context"
 	"db_forum/app/models"
+	"db_forum/pkg"
 	"db_forum/pkg/handlerows"
 	"db_forum/pkg/queries"
 	"github.com/jackc/pgx"
 	_ "github.com/lib/pq"
+	"strings"
+	"time"
 )
 
 type UserRepository interface {
@@ -13,6 +17,13 @@ type UserRepository interface {
 	UpdateUser(user *models.User) error
 	GetInfoAboutUser(nickname string) (*models.User, error)
 	GetSimilarUsers(user *models.User) (*[]models.User, error)
+	GetUserPosts(nickname string, limit, since int, desc bool) (*[]models.Post, error)
+	GetUserThreads(nickname string, limit int, since string, desc bool) (*[]models.Thread, error)
+	GetUserForums(nickname string, limit int, since string, desc bool) (*[]models.UserForum, error)
+	ExportUser(nickname string) (*models.UserExport, error)
+	DeleteUser(nickname string) error
+	RenameUser(user *models.User, nickname string, ttl time.Duration) error
+	GetUserRedirect(nickname string, ttl time.Duration) (string, error)
 }
 
 type UserRepositoryImpl struct {
@@ -29,12 +40,16 @@ func (userRepository *UserRepositoryImpl) CreateUser(user *models.User) error {
 }
 
 func (userRepository *UserRepositoryImpl) UpdateUser(user *models.User) error {
-	return userRepository.db.QueryRow(queries.UserUpdate, user.Fullname, user.About, user.Email, user.Nickname).Scan(&user.Fullname, &user.About, &user.Email)
+	err := userRepository.db.QueryRow(queries.UserUpdate, user.Fullname, user.About, user.Email, user.Nickname, user.Version).Scan(&user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err == pgx.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	return err
 }
 
 func (userRepository *UserRepositoryImpl) GetInfoAboutUser(nickname string) (*models.User, error) {
 	user := new(models.User)
-	err := userRepository.db.QueryRow(queries.UserGet, nickname).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email)
+	err := userRepository.db.QueryRow(queries.UserGet, nickname).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
 	return user, err
 }
 
@@ -46,3 +61,248 @@ func (userRepository *UserRepositoryImpl) GetSimilarUsers(user *models.User) (*[
 	defer resultRows.Close()
 	return handlerows.User(resultRows)
 }
+
+func (userRepository *UserRepositoryImpl) GetUserPosts(nickname string, limit, since int, desc bool) (*[]models.Post, error) {
+	var rows *pgx.Rows
+	var err error
+	if since != -1 {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetPostsSinceDesc, nickname, since, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetPostsSince, nickname, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetPostsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetPosts, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return handlerows.Post(rows)
+}
+
+func (userRepository *UserRepositoryImpl) GetUserThreads(nickname string, limit int, since string, desc bool) (*[]models.Thread, error) {
+	var rows *pgx.Rows
+	var err error
+	if since != "" {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetThreadsSinceDesc, nickname, since, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetThreadsSince, nickname, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetThreadsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetThreads, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return handlerows.Thread(rows)
+}
+
+// GetUserForums lists the forums of user_forum for the user, counting their
+// posts in each.
+func (userRepository *UserRepositoryImpl) GetUserForums(nickname string, limit int, since string, desc bool) (*[]models.UserForum, error) {
+	var rows *pgx.Rows
+	var err error
+	if since != "" {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetForumsBase+queries.UserGetForumsSinceDesc, nickname, since, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetForumsBase+queries.UserGetForumsSince, nickname, since, limit)
+		}
+	} else {
+		if desc {
+			rows, err = userRepository.db.Query(queries.UserGetForumsBase+queries.UserGetForumsDesc, nickname, limit)
+		} else {
+			rows, err = userRepository.db.Query(queries.UserGetForumsBase+queries.UserGetForums, nickname, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	forums := make([]models.UserForum, 0)
+	for rows.Next() {
+		forum := models.UserForum{}
+		if err = rows.Scan(&forum.Slug, &forum.Title, &forum.User, &forum.Posts); err != nil {
+			return nil, err
+		}
+		forums = append(forums, forum)
+	}
+	return &forums, rows.Err()
+}
+
+// ExportUser collects the profile, threads, posts and votes of the user from a
+// single snapshot.
+func (userRepository *UserRepositoryImpl) ExportUser(nickname string) (*models.UserExport, error) {
+	tx, err := userRepository.db.BeginEx(context.Background(), &pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	export := &models.UserExport{User: new(models.User)}
+	user := export.User
+	err = tx.QueryRow(queries.UserGet, nickname).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(queries.UserExportThreads, nickname)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := handlerows.Thread(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	export.Threads = *threads
+
+	rows, err = tx.Query(queries.UserExportPosts, nickname)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := handlerows.Post(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	export.Posts = *posts
+
+	rows, err = tx.Query(queries.UserExportVotes, nickname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	export.Votes = make(models.TransferVotes, 0)
+	for rows.Next() {
+		vote := models.TransferVote{}
+		if err = rows.Scan(&vote.Nickname, &vote.Thread, &vote.Post, &vote.Voice); err != nil {
+			return nil, err
+		}
+		export.Votes = append(export.Votes, vote)
+	}
+	return export, rows.Err()
+}
+
+// DeleteUser hands the forums, threads and posts of the user over to the
+// tombstone account along with their reputation, then deletes the user and
+// their votes. The vote triggers fix the thread votes, post scores and the
+// reputation of the authors.
+func (userRepository *UserRepositoryImpl) DeleteUser(nickname string) (err error) {
+	tx, err := userRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	tombstone := models.DeletedUser
+	var taken bool
+	if err = tx.QueryRow(queries.UserTombstoneTaken, tombstone.Nickname, tombstone.Email).Scan(&taken); err != nil {
+		return
+	}
+	if taken {
+		return pkg.ErrTombstoneTaken
+	}
+	if _, err = tx.Exec(queries.UserTombstone, tombstone.Nickname, tombstone.Fullname, tombstone.Email); err != nil {
+		return
+	}
+	if err = tx.QueryRow(queries.UserLock, nickname).Scan(&nickname); err != nil {
+		return
+	}
+	for _, query := range []string{queries.UserDeleteVotes, queries.UserDeleteScores} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return
+		}
+	}
+	// the forums, threads and posts are moved before the user is deleted: the
+	// foreign keys of forums and threads cascade on delete
+	for _, query := range []string{queries.UserMoveForums, queries.UserAddReputation, queries.UserMoveOwnForums,
+		queries.UserMoveThreads, queries.UserMovePosts, queries.UserMoveStats} {
+		if _, err = tx.Exec(query, nickname, tombstone.Nickname); err != nil {
+			return
+		}
+	}
+	for _, query := range []string{queries.UserDeleteForums, queries.UserDeleteStats} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return
+		}
+	}
+	_, err = tx.Exec(queries.UserDelete, nickname)
+	return
+}
+
+// RenameUser changes the nickname of user.Nickname, conditioned on its Version
+// like UpdateUser, and fills user with the renamed profile. The references
+// follow by on update cascade, the old nickname redirects to the new one for
+// ttl unless only its case changed.
+func (userRepository *UserRepositoryImpl) RenameUser(user *models.User, nickname string, ttl time.Duration) (err error) {
+	tx, err := userRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var taken bool
+	if err = tx.QueryRow(queries.UserRedirectTaken, nickname, user.Nickname, ttl.Seconds()).Scan(&taken); err != nil {
+		return
+	}
+	if taken {
+		return pkg.ErrUserAlreadyExist
+	}
+	previous := user.Nickname
+	err = tx.QueryRow(queries.UserRename, previous, nickname, user.Version).Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Version)
+	if err == pgx.ErrNoRows {
+		return pkg.ErrPreconditionFailed
+	}
+	if uniqueViolation(err, "users_pkey") {
+		// taken since the use case looked the nickname up
+		return pkg.ErrUserAlreadyExist
+	}
+	if err != nil {
+		return
+	}
+	for _, query := range []string{queries.UserRenameForums, queries.UserRenameThreads, queries.UserRenamePosts} {
+		if _, err = tx.Exec(query, nickname); err != nil {
+			return
+		}
+	}
+	if _, err = tx.Exec(queries.UserRedirectPrune, nickname, ttl.Seconds()); err != nil {
+		return
+	}
+	if !strings.EqualFold(previous, nickname) {
+		_, err = tx.Exec(queries.UserRedirectSave, previous, nickname)
+	}
+	return
+}
+
+// GetUserRedirect returns the nickname a user renamed from nickname has now,
+// if that was less than ttl ago.
+func (userRepository *UserRepositoryImpl) GetUserRedirect(nickname string, ttl time.Duration) (string, error) {
+	var target string
+	err := userRepository.db.QueryRow(queries.UserRedirectGet, nickname, ttl.Seconds()).Scan(&target)
+	return target, err
+}
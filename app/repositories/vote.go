@@ -2,13 +2,17 @@ package repositories
 
 import (
 	"db_forum/app/models"
+	"db_forum/pkg"
 	"db_forum/pkg/queries"
 	"github.com/jackc/pgx"
 	_ "github.com/lib/pq"
 )
 
 type VoteRepository interface {
-	VoteForThread(id int64, vote *models.Vote) error
+	VoteForThread(thread *models.Thread, vote *models.Vote) error
+	VoteForPost(post *models.Post, vote *models.Vote) error
+	DeleteVote(thread *models.Thread, nickname string) error
+	GetThreadVoters(id int64, limit int, since string, desc bool) (*models.ThreadVoters, error)
 }
 
 type VoteRepositoryImpl struct {
@@ -19,7 +23,136 @@ func MakeVoteRepository(db *pgx.ConnPool) VoteRepository {
 	return &VoteRepositoryImpl{db: db}
 }
 
-func (voteRepository *VoteRepositoryImpl) VoteForThread(id int64, vote *models.Vote) error {
-	_, err := voteRepository.db.Exec(queries.Vote, vote.Nickname, id, vote.Voice)
-	return err
+// VoteForThread upserts the vote and sets the votes and version of the thread
+// after it. When the vote changes the trigger holds the row of the thread
+// until the commit, so concurrent votes can't slip in between.
+func (voteRepository *VoteRepositoryImpl) VoteForThread(thread *models.Thread, vote *models.Vote) (err error) {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(queries.Vote, vote.Nickname, thread.Id, vote.Voice); err != nil {
+		switch {
+		case foreignKeyViolation(err, "votes_thread_fkey"):
+			err = pkg.ErrThreadNotFound
+		case foreignKeyViolation(err, "votes_nickname_fkey"):
+			err = pkg.ErrUserNotFound
+		}
+		return
+	}
+	err = tx.QueryRow(queries.VoteThreadTotal, thread.Id).Scan(&thread.Votes, &thread.Version)
+	return
+}
+
+// VoteForPost upserts the vote and sets the score and version of the post
+// after it. The trigger holds the row of the post until the commit, so the
+// score is the one this vote produced.
+func (voteRepository *VoteRepositoryImpl) VoteForPost(post *models.Post, vote *models.Vote) (err error) {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(queries.PostVote, vote.Nickname, post.Id, vote.Voice); err != nil {
+		switch {
+		case foreignKeyViolation(err, "post_votes_post_fkey"):
+			err = pkg.ErrPostNotFound
+		case foreignKeyViolation(err, "post_votes_nickname_fkey"):
+			err = pkg.ErrUserNotFound
+		}
+		return
+	}
+	err = tx.QueryRow(queries.PostScore, post.Id).Scan(&post.Score, &post.Version)
+	return
+}
+
+// DeleteVote removes the vote of nickname and sets the votes and version of
+// the thread after it, read under the lock the trigger took.
+func (voteRepository *VoteRepositoryImpl) DeleteVote(thread *models.Thread, nickname string) (err error) {
+	tx, err := voteRepository.db.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	commandTag, err := tx.Exec(queries.VoteDelete, thread.Id, nickname)
+	if err != nil {
+		return
+	}
+	if commandTag.RowsAffected() == 0 {
+		return pkg.ErrVoteNotFound
+	}
+	err = tx.QueryRow(queries.VoteThreadTotal, thread.Id).Scan(&thread.Votes, &thread.Version)
+	return
+}
+
+func (voteRepository *VoteRepositoryImpl) GetThreadVoters(id int64, limit int, since string, desc bool) (*models.ThreadVoters, error) {
+	voters := &models.ThreadVoters{Voters: []models.Voter{}}
+	err := voteRepository.db.QueryRow(queries.VoteCounts, id).Scan(&voters.Votes, &voters.Upvotes, &voters.Downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *pgx.Rows
+	if since == "" {
+		if desc {
+			rows, err = voteRepository.db.Query(queries.VotersDesc, id, limit)
+		} else {
+			rows, err = voteRepository.db.Query(queries.Voters, id, limit)
+		}
+	} else {
+		if desc {
+			rows, err = voteRepository.db.Query(queries.VotersSinceDesc, id, since, limit)
+		} else {
+			rows, err = voteRepository.db.Query(queries.VotersSince, id, since, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		voter := models.Voter{}
+		if err = rows.Scan(&voter.Nickname, &voter.Voice); err != nil {
+			return nil, err
+		}
+		voters.Voters = append(voters.Voters, voter)
+	}
+	return voters, rows.Err()
+}
+
+// foreignKeyViolation reports whether err is a violation of the foreign key
+// named constraint.
+func foreignKeyViolation(err error, constraint string) bool {
+	pgErr, ok := err.(pgx.PgError)
+	return ok && pgErr.Code == "23503" && pgErr.ConstraintName == constraint
+}
+
+// uniqueViolation reports whether err is a violation of the unique constraint
+// named constraint.
+func uniqueViolation(err error, constraint string) bool {
+	pgErr, ok := err.(pgx.PgError)
+	return ok && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
 }
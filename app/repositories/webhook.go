@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"time"
+
+	"github.com/jackc/pgx"
+	_ "github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(webhook *models.Webhook) error
+	GetWebhook(forum string, id int64) (*models.Webhook, error)
+	GetForumWebhooks(forum string) (*[]models.Webhook, error)
+	DeleteWebhook(forum string, id int64) (deleted bool, err error)
+	GetDeliveries(webhook int64, limit int, since int64, desc bool) (*[]models.WebhookDelivery, error)
+	ClaimDeliveries(limit int, lease time.Duration) (*[]models.WebhookDelivery, error)
+	MarkDelivered(id int64, responseCode int) error
+	MarkFailed(id int64, status string, responseCode int, lastError string, retryIn time.Duration) error
+	EnqueueDeliveries(event *models.OutboxEvent) error
+}
+
+type WebhookRepositoryImpl struct {
+	db *pgx.ConnPool
+}
+
+func MakeWebhookRepository(db *pgx.ConnPool) WebhookRepository {
+	return &WebhookRepositoryImpl{db: db}
+}
+
+func (webhookRepository *WebhookRepositoryImpl) CreateWebhook(webhook *models.Webhook) error {
+	return webhookRepository.db.QueryRow(queries.WebhookCreate, webhook.Forum, webhook.Url, webhook.Secret, webhook.Events).
+		Scan(&webhook.Id, &webhook.Created)
+}
+
+func (webhookRepository *WebhookRepositoryImpl) GetWebhook(forum string, id int64) (*models.Webhook, error) {
+	webhook := new(models.Webhook)
+	err := webhookRepository.db.QueryRow(queries.WebhookGet, id, forum).
+		Scan(&webhook.Id, &webhook.Forum, &webhook.Url, &webhook.Events, &webhook.Created)
+	return webhook, err
+}
+
+func (webhookRepository *WebhookRepositoryImpl) GetForumWebhooks(forum string) (*[]models.Webhook, error) {
+	rows, err := webhookRepository.db.Query(queries.WebhookGetByForum, forum)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		webhook := models.Webhook{}
+		err = rows.Scan(&webhook.Id, &webhook.Forum, &webhook.Url, &webhook.Events, &webhook.Created)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return &webhooks, rows.Err()
+}
+
+func (webhookRepository *WebhookRepositoryImpl) DeleteWebhook(forum string, id int64) (bool, error) {
+	commandTag, err := webhookRepository.db.Exec(queries.WebhookDelete, id, forum)
+	if err != nil {
+		return false, err
+	}
+	return commandTag.RowsAffected() > 0, nil
+}
+
+func (webhookRepository *WebhookRepositoryImpl) GetDeliveries(webhook int64, limit int, since int64, desc bool) (*[]models.WebhookDelivery, error) {
+	var rows *pgx.Rows
+	var err error
+	if since == -1 {
+		if desc {
+			rows, err = webhookRepository.db.Query(queries.WebhookDeliveriesBase+queries.WebhookDeliveriesDesc, webhook, limit)
+		} else {
+			rows, err = webhookRepository.db.Query(queries.WebhookDeliveriesBase+queries.WebhookDeliveries, webhook, limit)
+		}
+	} else {
+		if desc {
+			rows, err = webhookRepository.db.Query(queries.WebhookDeliveriesBase+queries.WebhookDeliveriesSinceDesc, webhook, since, limit)
+		} else {
+			rows, err = webhookRepository.db.Query(queries.WebhookDeliveriesBase+queries.WebhookDeliveriesSince, webhook, since, limit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		var payload string
+		err = rows.Scan(&delivery.Id, &delivery.Webhook, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+			&delivery.ResponseCode, &delivery.LastError, &delivery.Created, &delivery.NextAttempt, &delivery.Delivered)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Payload = []byte(payload)
+		deliveries = append(deliveries, delivery)
+	}
+	return &deliveries, rows.Err()
+}
+
+// ClaimDeliveries leases due deliveries to the caller: they are not handed out
+// again until the lease runs out, so a crashed worker's deliveries are retried.
+func (webhookRepository *WebhookRepositoryImpl) ClaimDeliveries(limit int, lease time.Duration) (*[]models.WebhookDelivery, error) {
+	rows, err := webhookRepository.db.Query(queries.WebhookDeliveriesClaim, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := models.WebhookDelivery{}
+		var payload string
+		err = rows.Scan(&delivery.Id, &delivery.Webhook, &delivery.Event, &payload, &delivery.Attempts, &delivery.Url, &delivery.Secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Payload = []byte(payload)
+		deliveries = append(deliveries, delivery)
+	}
+	return &deliveries, rows.Err()
+}
+
+func (webhookRepository *WebhookRepositoryImpl) MarkDelivered(id int64, responseCode int) error {
+	_, err := webhookRepository.db.Exec(queries.WebhookDeliverySucceeded, id, responseCode)
+	return err
+}
+
+func (webhookRepository *WebhookRepositoryImpl) MarkFailed(id int64, status string, responseCode int, lastError string, retryIn time.Duration) error {
+	_, err := webhookRepository.db.Exec(queries.WebhookDeliveryFailed, id, status, responseCode, lastError, retryIn.Seconds())
+	return err
+}
+
+// EnqueueDeliveries queues the event for every webhook of its forum subscribed
+// to it, an event enqueued twice is delivered once.
+func (webhookRepository *WebhookRepositoryImpl) EnqueueDeliveries(event *models.OutboxEvent) error {
+	_, err := webhookRepository.db.Exec(queries.WebhookEnqueue, event.Id, event.Forum, event.Type, string(event.Payload))
+	return err
+}
@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	EventPost = "post"
+	EventEdit = "edit"
+	EventVote = "vote"
+
+	subscriptionBuffer = 64
+	reconnectDelay     = time.Second
+)
+
+type Message struct {
+	Event string
+	// Id is set only for new posts, it is the position a client resumes from.
+	Id   int64
+	Data []byte
+}
+
+type Subscription struct {
+	Messages chan Message
+	thread   int64
+	closed   bool
+}
+
+// Hub fans out thread events received from PostgreSQL to the subscribers of
+// this instance, every instance listens to the same channel.
+type Hub struct {
+	repoEvent     repositories.EventRepository
+	repoPost      repositories.PostRepository
+	mutex         sync.Mutex
+	subscriptions map[int64]map[*Subscription]struct{}
+}
+
+func MakeHub(event repositories.EventRepository, post repositories.PostRepository) *Hub {
+	return &Hub{repoEvent: event, repoPost: post, subscriptions: make(map[int64]map[*Subscription]struct{})}
+}
+
+func (hub *Hub) Subscribe(thread int64) *Subscription {
+	subscription := &Subscription{Messages: make(chan Message, subscriptionBuffer), thread: thread}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if hub.subscriptions[thread] == nil {
+		hub.subscriptions[thread] = make(map[*Subscription]struct{})
+	}
+	hub.subscriptions[thread][subscription] = struct{}{}
+	return subscription
+}
+
+func (hub *Hub) Unsubscribe(subscription *Subscription) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.drop(subscription)
+}
+
+func (hub *Hub) drop(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	close(subscription.Messages)
+
+	delete(hub.subscriptions[subscription.thread], subscription)
+	if len(hub.subscriptions[subscription.thread]) == 0 {
+		delete(hub.subscriptions, subscription.thread)
+	}
+}
+
+func (hub *Hub) hasSubscribers(thread int64) bool {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return len(hub.subscriptions[thread]) > 0
+}
+
+// broadcast never blocks, a subscriber that can't keep up is dropped and
+// has to reconnect with Last-Event-ID.
+func (hub *Hub) broadcast(thread int64, message Message) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	for subscription := range hub.subscriptions[thread] {
+		select {
+		case subscription.Messages <- message:
+		default:
+			hub.drop(subscription)
+		}
+	}
+}
+
+func (hub *Hub) Run() {
+	events := make(chan *models.ThreadEvent, subscriptionBuffer)
+	go func() {
+		for {
+			err := hub.repoEvent.ListenThreadEvents(events)
+			if err != nil {
+				fmt.Println(err)
+			}
+			time.Sleep(reconnectDelay)
+		}
+	}()
+
+	for event := range events {
+		if !hub.hasSubscribers(event.Thread) {
+			continue
+		}
+		message, err := hub.makeMessage(event)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		hub.broadcast(event.Thread, message)
+	}
+}
+
+func (hub *Hub) makeMessage(event *models.ThreadEvent) (Message, error) {
+	switch event.Type {
+	case EventPost, EventEdit:
+		post, err := hub.repoPost.GetPost(event.Id)
+		if err != nil {
+			return Message{}, err
+		}
+		return MakePostMessage(event.Type, post)
+	default:
+		data, err := event.MarshalJSON()
+		return Message{Event: event.Type, Data: data}, err
+	}
+}
+
+func MakePostMessage(eventType string, post *models.Post) (Message, error) {
+	data, err := post.MarshalJSON()
+	if err != nil {
+		return Message{}, err
+	}
+	message := Message{Event: eventType, Data: data}
+	if eventType == EventPost {
+		message.Id = post.Id
+	}
+	return message, nil
+}
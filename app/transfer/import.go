@@ -0,0 +1,506 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+const (
+	defaultBatchSize = 5000
+	maxRecordSize    = 64 << 20
+)
+
+type ImportOptions struct {
+	// Name identifies the import. Running an import again under the name of
+	// one that failed skips the records it already committed.
+	Name string
+	// Remap gives threads and posts new ids instead of keeping the exported
+	// ones, so a forum can be loaded next to existing data.
+	Remap     bool
+	BatchSize int
+	Progress  func(Progress)
+}
+
+// Import loads an export, committing it in batches. The rows are written with
+// COPY in bulk mode, which skips the counter triggers, so the import computes
+// the paths of the posts and adds up the forum and thread counters and
+// user_forum of every batch itself. Foreign keys are still checked, and no
+// webhook events are sent for the imported rows.
+func Import(db *pgx.ConnPool, r io.Reader, options ImportOptions) (progress Progress, err error) {
+	if options.Name == "" {
+		return progress, errors.New("transfer: the import needs a name")
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+
+	var remap, finished bool
+	var committed int64
+	err = db.QueryRow(queries.TransferImportStart, options.Name, options.Remap).Scan(&remap, &committed, &finished)
+	if err != nil {
+		return
+	}
+	if remap != options.Remap {
+		return progress, fmt.Errorf("transfer: import %q was started with remap %t", options.Name, remap)
+	}
+	progress.Records = committed
+	if finished {
+		return
+	}
+
+	importer := &importer{
+		db:        db,
+		options:   options,
+		threadIds: make(map[int64]int64),
+		postIds:   make(map[int64]int64),
+		paths:     make(map[int64][]int64),
+	}
+	if remap {
+		if err = importer.loadIds(); err != nil {
+			return
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	batch := make([]*models.TransferRecord, 0, options.BatchSize)
+	var line int64
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		line++
+		if line <= committed {
+			continue
+		}
+		record := new(models.TransferRecord)
+		if err = record.UnmarshalJSON(scanner.Bytes()); err != nil {
+			return progress, fmt.Errorf("record %d: %w", line, err)
+		}
+		batch = append(batch, record)
+		if len(batch) == options.BatchSize {
+			if err = importer.flush(batch, line, &progress); err != nil {
+				return
+			}
+			batch = batch[:0]
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(batch) > 0 {
+		if err = importer.flush(batch, line, &progress); err != nil {
+			return
+		}
+	}
+	_, err = db.Exec(queries.TransferImportFinish, options.Name)
+	return
+}
+
+type importer struct {
+	db      *pgx.ConnPool
+	options ImportOptions
+
+	// old ids to new ones, when remapping
+	threadIds map[int64]int64
+	postIds   map[int64]int64
+	// paths of the posts imported in this run, by their new id
+	paths map[int64][]int64
+}
+
+func (importer *importer) loadIds() error {
+	rows, err := importer.db.Query(queries.TransferImportIds, importer.options.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var oldId, newId int64
+		if err = rows.Scan(&kind, &oldId, &newId); err != nil {
+			return err
+		}
+		if kind == RecordThread {
+			importer.threadIds[oldId] = newId
+		} else {
+			importer.postIds[oldId] = newId
+		}
+	}
+	return rows.Err()
+}
+
+// batch collects what the triggers would have done for the rows of a batch.
+type batch struct {
+	tx      *pgx.Tx
+	posts   map[string]int64
+	threads map[string]int64
+	votes   map[int64]int64
+	scores  map[int64][2]int64
+	users   map[[2]string][2]string
+	ids     [][]interface{}
+}
+
+func (batch *batch) participate(nickname, forum string) {
+	key := [2]string{strings.ToLower(nickname), strings.ToLower(forum)}
+	batch.users[key] = [2]string{nickname, forum}
+}
+
+// flush commits the records in one transaction together with the number of
+// records committed through the last of them.
+func (importer *importer) flush(records []*models.TransferRecord, through int64, progress *Progress) (err error) {
+	tx, err := importer.db.Begin()
+	if err != nil {
+		return
+	}
+	counted := Progress{}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		progress.Records = through
+		progress.Users += counted.Users
+		progress.Forums += counted.Forums
+		progress.Threads += counted.Threads
+		progress.Posts += counted.Posts
+		progress.Votes += counted.Votes
+		if importer.options.Progress != nil {
+			importer.options.Progress(*progress)
+		}
+	}()
+
+	if _, err = tx.Exec(queries.TransferBulk); err != nil {
+		return
+	}
+
+	current := &batch{
+		tx:      tx,
+		posts:   make(map[string]int64),
+		threads: make(map[string]int64),
+		votes:   make(map[int64]int64),
+		scores:  make(map[int64][2]int64),
+		users:   make(map[[2]string][2]string),
+	}
+	// records of one type are written together, in the order of the export
+	for start := 0; start < len(records); {
+		end := start
+		for end < len(records) && records[end].Type == records[start].Type {
+			end++
+		}
+		switch records[start].Type {
+		case RecordUser:
+			err = importer.users(current, records[start:end], through-int64(len(records)-start)+1)
+		case RecordForum:
+			err = importer.forums(current, records[start:end])
+		case RecordThread:
+			err = importer.threads(current, records[start:end])
+		case RecordPost:
+			err = importer.posts(current, records[start:end])
+		case RecordVote:
+			err = importer.votes(current, records[start:end])
+		default:
+			err = fmt.Errorf("unknown record type %q", records[start].Type)
+		}
+		if err != nil {
+			return fmt.Errorf("records %d-%d: %w", through-int64(len(records)-start)+1, through-int64(len(records)-end), err)
+		}
+		counted.add(records[start].Type, int64(end-start))
+		start = end
+	}
+
+	return importer.finish(current, through)
+}
+
+// users keeps the users already stored under the same nickname. A user whose
+// email belongs to someone else stops the import, since the rows after it
+// would refer to a nickname that is not there; first is the number of the
+// first record, to point at the offending one.
+func (importer *importer) users(batch *batch, records []*models.TransferRecord, first int64) error {
+	for i, record := range records {
+		user := record.User
+		if user == nil {
+			return errors.New("user record without a user")
+		}
+		// the tombstone of the exported database comes as it is, nobody else
+		// may take its nickname or email
+		isTombstone := strings.EqualFold(user.Nickname, models.DeletedUser.Nickname) && strings.EqualFold(user.Email, models.DeletedUser.Email)
+		if !isTombstone && models.ClaimsTombstone(user.Nickname, user.Email) {
+			return fmt.Errorf("record %d: user %s: the nickname and email of user %s are reserved", first+int64(i), user.Nickname, models.DeletedUser.Nickname)
+		}
+		var nickname string
+		err := batch.tx.QueryRow(queries.TransferUserInsert, user.Nickname, user.Fullname, user.About, user.Email).Scan(&nickname)
+		if err == pgx.ErrNoRows {
+			var owner string
+			err = batch.tx.QueryRow(queries.TransferUserEmailOwner, user.Nickname, user.Email).Scan(&owner)
+			if err == nil {
+				return fmt.Errorf("record %d: user %s: email %s belongs to user %s", first+int64(i), user.Nickname, user.Email, owner)
+			}
+			if err == pgx.ErrNoRows {
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (importer *importer) forums(batch *batch, records []*models.TransferRecord) error {
+	for _, record := range records {
+		forum := record.Forum
+		if forum == nil {
+			return errors.New("forum record without a forum")
+		}
+		if _, err := batch.tx.Exec(queries.TransferForumInsert, forum.Title, forum.User, forum.Slug); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (importer *importer) threads(batch *batch, records []*models.TransferRecord) error {
+	ids, err := importer.reserveIds(batch.tx, queries.TransferNextThreadIds, len(records))
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for i, record := range records {
+		thread := record.Thread
+		if thread == nil {
+			return errors.New("thread record without a thread")
+		}
+		id := thread.Id
+		if importer.options.Remap {
+			id = ids[i]
+			importer.threadIds[thread.Id] = id
+			batch.ids = append(batch.ids, []interface{}{importer.options.Name, RecordThread, thread.Id, id})
+		}
+		rows = append(rows, []interface{}{id, thread.Title, thread.Author, thread.Forum, thread.Message, thread.Slug, thread.Created})
+		batch.threads[thread.Forum]++
+		batch.participate(thread.Author, thread.Forum)
+	}
+	_, err = batch.tx.CopyFrom(pgx.Identifier{"threads"}, []string{"id", "title", "author", "forum", "message", "slug", "created"}, pgx.CopyFromRows(rows))
+	return err
+}
+
+func (importer *importer) posts(batch *batch, records []*models.TransferRecord) error {
+	ids, err := importer.reserveIds(batch.tx, queries.PostNextIds, len(records))
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, 0, len(records))
+	for i, record := range records {
+		post := record.Post
+		if post == nil {
+			return errors.New("post record without a post")
+		}
+		id, parent, thread := post.Id, post.Parent, post.Thread
+		if importer.options.Remap {
+			id = ids[i]
+			importer.postIds[post.Id] = id
+			batch.ids = append(batch.ids, []interface{}{importer.options.Name, RecordPost, post.Id, id})
+
+			var ok bool
+			if thread, ok = importer.threadIds[post.Thread]; !ok {
+				return fmt.Errorf("post %d: thread %d is not in the import", post.Id, post.Thread)
+			}
+			if parent != 0 {
+				if parent, ok = importer.postIds[post.Parent]; !ok {
+					return fmt.Errorf("post %d: parent %d is not in the import", post.Id, post.Parent)
+				}
+			}
+		}
+
+		path, err := importer.path(batch.tx, parent)
+		if err != nil {
+			return fmt.Errorf("post %d: %w", post.Id, err)
+		}
+		path = append(path[:len(path):len(path)], id)
+		importer.paths[id] = path
+
+		created, err := time.Parse(time.RFC3339Nano, post.Created)
+		if err != nil {
+			return fmt.Errorf("post %d: %w", post.Id, err)
+		}
+		var parentId interface{}
+		if parent != 0 {
+			parentId = parent
+		}
+		rows = append(rows, []interface{}{id, parentId, post.Author, post.Message, post.IsEdited, post.Forum, thread, created, path})
+		batch.posts[post.Forum]++
+		batch.participate(post.Author, post.Forum)
+	}
+	_, err = batch.tx.CopyFrom(pgx.Identifier{"posts"}, []string{"id", "parent", "author", "message", "is_edited", "forum", "thread", "created", "path"}, pgx.CopyFromRows(rows))
+	return err
+}
+
+func (importer *importer) votes(batch *batch, records []*models.TransferRecord) error {
+	var threadRows, postRows [][]interface{}
+	for _, record := range records {
+		vote := record.Vote
+		if vote == nil {
+			return errors.New("vote record without a vote")
+		}
+		if vote.Post != 0 {
+			post := vote.Post
+			if importer.options.Remap {
+				var ok bool
+				if post, ok = importer.postIds[vote.Post]; !ok {
+					return fmt.Errorf("vote of %s: post %d is not in the import", vote.Nickname, vote.Post)
+				}
+			}
+			postRows = append(postRows, []interface{}{vote.Nickname, post, vote.Voice})
+			score := batch.scores[post]
+			batch.scores[post] = [2]int64{score[0] + int64(vote.Voice), score[1] + 1}
+			continue
+		}
+		thread := vote.Thread
+		if importer.options.Remap {
+			var ok bool
+			if thread, ok = importer.threadIds[vote.Thread]; !ok {
+				return fmt.Errorf("vote of %s: thread %d is not in the import", vote.Nickname, vote.Thread)
+			}
+		}
+		threadRows = append(threadRows, []interface{}{vote.Nickname, thread, vote.Voice})
+		batch.votes[thread] += int64(vote.Voice)
+	}
+	if len(threadRows) > 0 {
+		if _, err := batch.tx.CopyFrom(pgx.Identifier{"votes"}, []string{"nickname", "thread", "voice"}, pgx.CopyFromRows(threadRows)); err != nil {
+			return err
+		}
+	}
+	if len(postRows) > 0 {
+		if _, err := batch.tx.CopyFrom(pgx.Identifier{"post_votes"}, []string{"nickname", "post", "voice"}, pgx.CopyFromRows(postRows)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveIds takes count ids from the sequence when remapping.
+func (importer *importer) reserveIds(tx *pgx.Tx, query string, count int) ([]int64, error) {
+	if !importer.options.Remap {
+		return nil, nil
+	}
+	rows, err := tx.Query(query, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, count)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// path returns the path of the parent post, which comes before its children
+// in the export, so it is either imported in this run or already stored.
+func (importer *importer) path(tx *pgx.Tx, parent int64) ([]int64, error) {
+	if parent == 0 {
+		return nil, nil
+	}
+	if path, ok := importer.paths[parent]; ok {
+		return path, nil
+	}
+	var path []int64
+	err := tx.QueryRow(queries.TransferPostPath, parent).Scan(&path)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("parent %d not found", parent)
+	}
+	return path, err
+}
+
+// finish applies the counters of the batch and records its progress.
+func (importer *importer) finish(batch *batch, through int64) error {
+	forums := make(map[string]struct{})
+	for forum := range batch.posts {
+		forums[forum] = struct{}{}
+	}
+	for forum := range batch.threads {
+		forums[forum] = struct{}{}
+	}
+	for forum := range forums {
+		if _, err := batch.tx.Exec(queries.ServiceRepairForum, forum, batch.posts[forum], batch.threads[forum]); err != nil {
+			return err
+		}
+	}
+	for thread, votes := range batch.votes {
+		if _, err := batch.tx.Exec(queries.ServiceRepairThread, thread, votes); err != nil {
+			return err
+		}
+	}
+	for post, score := range batch.scores {
+		if _, err := batch.tx.Exec(queries.TransferPostScore, post, score[0], score[1]); err != nil {
+			return err
+		}
+	}
+
+	if len(batch.users) > 0 {
+		nicknames := make([]string, 0, len(batch.users))
+		forums := make([]string, 0, len(batch.users))
+		for _, user := range batch.users {
+			nicknames = append(nicknames, user[0])
+			forums = append(forums, user[1])
+		}
+		if _, err := batch.tx.Exec(queries.TransferForumUsersInsert, nicknames, forums); err != nil {
+			return err
+		}
+	}
+	for forum := range forums {
+		for _, query := range []string{queries.ServiceRepairForumActivity, queries.TransferForumStats, queries.TransferForumStatsUsers} {
+			if _, err := batch.tx.Exec(query, forum); err != nil {
+				return err
+			}
+		}
+	}
+	// reputation goes to user_forum too, so after its rows are in
+	for thread, votes := range batch.votes {
+		if _, err := batch.tx.Exec(queries.TransferThreadReputation, thread, votes); err != nil {
+			return err
+		}
+	}
+	for post, score := range batch.scores {
+		if _, err := batch.tx.Exec(queries.TransferPostReputation, post, score[0]); err != nil {
+			return err
+		}
+	}
+
+	if len(batch.ids) > 0 {
+		_, err := batch.tx.CopyFrom(pgx.Identifier{"import_ids"}, []string{"import", "kind", "old_id", "new_id"}, pgx.CopyFromRows(batch.ids))
+		if err != nil {
+			return err
+		}
+	}
+	if !importer.options.Remap {
+		if _, err := batch.tx.Exec(queries.TransferThreadsSerial); err != nil {
+			return err
+		}
+		if _, err := batch.tx.Exec(queries.TransferPostsSerial); err != nil {
+			return err
+		}
+	}
+	_, err := batch.tx.Exec(queries.TransferImportCheckpoint, importer.options.Name, through)
+	return err
+}
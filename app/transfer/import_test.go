@@ -0,0 +1,212 @@
+package transfer
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// testDatabase is the environment variable naming the Postgres database the
+// import tests load the schema into and wipe, e.g.
+// "host=127.0.0.1 user=forum password=forum dbname=forum_test sslmode=disable".
+const testDatabase = "FORUM_TEST_DB"
+
+func openTestDB(t *testing.T) *pgx.ConnPool {
+	dsn := os.Getenv(testDatabase)
+	if dsn == "" {
+		t.Skip(testDatabase + " is not set")
+	}
+	conn, err := pgx.ParseConnectionString(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := pgx.NewConnPool(pgx.ConnPoolConfig{ConnConfig: conn, MaxConnections: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	schema, err := ioutil.ReadFile(filepath.Join("..", "..", "db", "db.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(string(schema)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(queries.ServiceClear); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// makeExport returns the lines of an export of a forum owned by owner with a
+// thread of id 1, posts 1 to 3 each replying to the one before, and a vote of
+// voter for the thread.
+func makeExport(t *testing.T, forum, owner, voter string, voice int32) []string {
+	created := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	records := []models.TransferRecord{
+		{Type: RecordUser, User: &models.User{Nickname: "alice", Fullname: "Alice", Email: "alice@example.com"}},
+		{Type: RecordUser, User: &models.User{Nickname: "bob", Fullname: "Bob", Email: "bob@example.com"}},
+		{Type: RecordForum, Forum: &models.Forum{Title: forum, User: owner, Slug: forum}},
+		{Type: RecordThread, Thread: &models.Thread{Id: 1, Title: "Thread", Author: owner, Forum: forum, Message: "Message", Slug: forum + "-thread", Created: created}},
+	}
+	for id := int64(1); id <= 3; id++ {
+		records = append(records, models.TransferRecord{Type: RecordPost, Post: &models.Post{
+			Id: id, Parent: id - 1, Author: voter, Message: "Post", Forum: forum, Thread: 1, Created: created.Format(time.RFC3339Nano)}})
+	}
+	records = append(records, models.TransferRecord{Type: RecordVote, Vote: &models.TransferVote{Nickname: voter, Thread: 1, Voice: voice}})
+
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		line, err := record.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+func TestImportRemapAndResume(t *testing.T) {
+	db := openTestDB(t)
+
+	// forum a keeps the exported ids, so those of forum b are taken
+	first := makeExport(t, "a", "alice", "bob", 1)
+	progress, err := Import(db, strings.NewReader(strings.Join(first, "\n")), ImportOptions{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Progress{Records: 8, Users: 2, Forums: 1, Threads: 1, Posts: 3, Votes: 1}); progress != want {
+		t.Fatalf("import a: progress = %+v, want %+v", progress, want)
+	}
+
+	// the first run of b fails at its last post, after two batches
+	second := makeExport(t, "b", "bob", "alice", -1)
+	broken := append([]string(nil), second...)
+	broken[6] = `{"type":"post","post":`
+	options := ImportOptions{Name: "b", Remap: true, BatchSize: 3}
+	if _, err = Import(db, strings.NewReader(strings.Join(broken, "\n")), options); err == nil || !strings.HasPrefix(err.Error(), "record 7:") {
+		t.Fatalf("import of a broken export = %v, want an error at record 7", err)
+	}
+	var committed int64
+	if err = db.QueryRow("select records from imports where name = 'b';").Scan(&committed); err != nil {
+		t.Fatal(err)
+	}
+	if committed != 6 {
+		t.Fatalf("the failed import committed %d records, want 6", committed)
+	}
+
+	if _, err = Import(db, strings.NewReader(strings.Join(second, "\n")), ImportOptions{Name: "b"}); err == nil {
+		t.Fatal("resuming a remapped import without remap succeeded")
+	}
+	progress, err = Import(db, strings.NewReader(strings.Join(second, "\n")), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Progress{Records: 8, Posts: 1, Votes: 1}); progress != want {
+		t.Fatalf("resumed import b: progress = %+v, want %+v", progress, want)
+	}
+
+	// the resumed run maps the parent of the last post through the ids
+	// recorded by the failed one
+	var thread int64
+	if err = db.QueryRow("select id from threads where forum = 'b';").Scan(&thread); err != nil {
+		t.Fatal(err)
+	}
+	if thread == 1 {
+		t.Fatal("thread of b kept the id of the thread of a")
+	}
+	rows, err := db.Query("select id, coalesce(parent, 0), path from posts where thread = $1 order by id;", thread)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids, paths [][]int64
+	for rows.Next() {
+		var id, parent int64
+		var path []int64
+		if err = rows.Scan(&id, &parent, &path); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, []int64{id, parent})
+		paths = append(paths, path)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("thread of b has %d posts, want 3", len(ids))
+	}
+	for i := range ids {
+		if i > 0 && ids[i][1] != ids[i-1][0] {
+			t.Errorf("post %d replies to %d, want %d", ids[i][0], ids[i][1], ids[i-1][0])
+		}
+		want := make([]int64, 0, i+1)
+		for _, id := range ids[:i+1] {
+			want = append(want, id[0])
+		}
+		if !reflect.DeepEqual(paths[i], want) {
+			t.Errorf("post %d has path %v, want %v", ids[i][0], paths[i], want)
+		}
+	}
+
+	for _, counter := range []struct {
+		query string
+		arg   interface{}
+		want  int64
+	}{
+		{"select posts from forums where slug = $1;", "a", 3},
+		{"select threads from forums where slug = $1;", "b", 1},
+		{"select posts from forums where slug = $1;", "b", 3},
+		{"select votes from threads where id = $1;", int64(1), 1},
+		{"select votes from threads where id = $1;", thread, -1},
+		{"select count(*) from user_forum where forum = $1;", "b", 2},
+	} {
+		var got int64
+		if err = db.QueryRow(counter.query, counter.arg).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != counter.want {
+			t.Errorf("%s %v = %d, want %d", counter.query, counter.arg, got, counter.want)
+		}
+	}
+
+	// a finished import is not loaded again
+	progress, err = Import(db, strings.NewReader(strings.Join(second, "\n")), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Progress{Records: 8}); progress != want {
+		t.Fatalf("finished import b: progress = %+v, want %+v", progress, want)
+	}
+}
+
+func TestImportStopsAtTakenEmail(t *testing.T) {
+	db := openTestDB(t)
+
+	carol, err := (&models.TransferRecord{Type: RecordUser, User: &models.User{Nickname: "carol", Fullname: "Carol", Email: "ALICE@example.com"}}).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := makeExport(t, "a", "alice", "carol", 1)
+	lines = append(lines[:2:2], append([]string{string(carol)}, lines[2:]...)...)
+
+	_, err = Import(db, strings.NewReader(strings.Join(lines, "\n")), ImportOptions{Name: "a"})
+	if want := "record 3: user carol: email ALICE@example.com belongs to user alice"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("Import() = %v, want %q", err, want)
+	}
+	var users int64
+	if err = db.QueryRow("select count(*) from users;").Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 {
+		t.Errorf("the failed batch left %d users", users)
+	}
+}
@@ -0,0 +1,180 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"db_forum/app/models"
+	"db_forum/pkg/queries"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// Record types, in the order they are exported: every record refers only to
+// records before it.
+const (
+	RecordUser   = "user"
+	RecordForum  = "forum"
+	RecordThread = "thread"
+	RecordPost   = "post"
+	RecordVote   = "vote"
+)
+
+const exportProgressEvery = 10000
+
+// Progress counts the records handled so far. On a resumed import Records
+// includes the records committed by the previous runs, the other counters
+// only those of this run.
+type Progress struct {
+	Records int64
+	Users   int64
+	Forums  int64
+	Threads int64
+	Posts   int64
+	Votes   int64
+}
+
+func (progress *Progress) add(recordType string, count int64) {
+	switch recordType {
+	case RecordUser:
+		progress.Users += count
+	case RecordForum:
+		progress.Forums += count
+	case RecordThread:
+		progress.Threads += count
+	case RecordPost:
+		progress.Posts += count
+	case RecordVote:
+		progress.Votes += count
+	}
+}
+
+type ExportOptions struct {
+	// Forum limits the export to one forum, the users it refers to and the
+	// votes of its threads. Everything is exported if empty.
+	Forum    string
+	Progress func(Progress)
+}
+
+// Export writes the database to w as newline delimited JSON, one
+// TransferRecord per line, from a single snapshot.
+func Export(db *pgx.ConnPool, w io.Writer, options ExportOptions) (progress Progress, err error) {
+	tx, err := db.BeginEx(context.Background(), &pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var args []interface{}
+	users, forums, threads, posts, votes := queries.TransferUsers, queries.TransferForums, queries.TransferThreads, queries.TransferPosts, queries.TransferVotes
+	postVotes := queries.TransferPostVotes
+	if options.Forum != "" {
+		args = append(args, options.Forum)
+		users, forums, threads, posts, votes = queries.TransferForumUsers, queries.TransferForum, queries.TransferForumThreads, queries.TransferForumPosts, queries.TransferForumVotes
+		postVotes = queries.TransferForumPostVotes
+	}
+
+	writer := bufio.NewWriter(w)
+	write := func(record *models.TransferRecord) error {
+		line, err := record.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		progress.Records++
+		progress.add(record.Type, 1)
+		if options.Progress != nil && progress.Records%exportProgressEvery == 0 {
+			options.Progress(progress)
+		}
+		return nil
+	}
+
+	err = exportRows(tx, users, args, func(rows *pgx.Rows) error {
+		user := new(models.User)
+		if err := rows.Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email); err != nil {
+			return err
+		}
+		return write(&models.TransferRecord{Type: RecordUser, User: user})
+	})
+	if err != nil {
+		return
+	}
+	err = exportRows(tx, forums, args, func(rows *pgx.Rows) error {
+		forum := new(models.Forum)
+		if err := rows.Scan(&forum.Title, &forum.User, &forum.Slug); err != nil {
+			return err
+		}
+		return write(&models.TransferRecord{Type: RecordForum, Forum: forum})
+	})
+	if err != nil {
+		return
+	}
+	err = exportRows(tx, threads, args, func(rows *pgx.Rows) error {
+		thread := new(models.Thread)
+		if err := rows.Scan(&thread.Id, &thread.Title, &thread.Author, &thread.Forum, &thread.Message, &thread.Slug, &thread.Created); err != nil {
+			return err
+		}
+		return write(&models.TransferRecord{Type: RecordThread, Thread: thread})
+	})
+	if err != nil {
+		return
+	}
+	err = exportRows(tx, posts, args, func(rows *pgx.Rows) error {
+		post := new(models.Post)
+		created := time.Time{}
+		if err := rows.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &created); err != nil {
+			return err
+		}
+		// the API rounds to seconds, the export keeps the stored precision
+		post.Created = created.Format(time.RFC3339Nano)
+		return write(&models.TransferRecord{Type: RecordPost, Post: post})
+	})
+	if err != nil {
+		return
+	}
+	err = exportRows(tx, votes, args, func(rows *pgx.Rows) error {
+		vote := new(models.TransferVote)
+		if err := rows.Scan(&vote.Nickname, &vote.Thread, &vote.Voice); err != nil {
+			return err
+		}
+		return write(&models.TransferRecord{Type: RecordVote, Vote: vote})
+	})
+	if err != nil {
+		return
+	}
+	err = exportRows(tx, postVotes, args, func(rows *pgx.Rows) error {
+		vote := new(models.TransferVote)
+		if err := rows.Scan(&vote.Nickname, &vote.Thread, &vote.Post, &vote.Voice); err != nil {
+			return err
+		}
+		return write(&models.TransferRecord{Type: RecordVote, Vote: vote})
+	})
+	if err != nil {
+		return
+	}
+	if err = writer.Flush(); err != nil {
+		return
+	}
+	if options.Progress != nil {
+		options.Progress(progress)
+	}
+	return
+}
+
+func exportRows(tx *pgx.Tx, query string, args []interface{}, scan func(rows *pgx.Rows) error) error {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,90 @@
+package transfer
+
+import (
+	"bytes"
+	"db_forum/app/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	created := time.Date(2023, 11, 14, 22, 13, 20, 123456000, time.UTC)
+	tests := []struct {
+		name   string
+		record models.TransferRecord
+		line   string
+	}{
+		{"user", models.TransferRecord{Type: RecordUser, User: &models.User{Nickname: "alice", Fullname: "Alice", About: "line\nbreak", Email: "alice@example.com"}},
+			`{"type":"user","user":{"nickname":"alice","fullname":"Alice","about":"line\nbreak","email":"alice@example.com","reputation":0}}`},
+		{"forum", models.TransferRecord{Type: RecordForum, Forum: &models.Forum{Title: "Forum", User: "alice", Slug: "forum"}},
+			`{"type":"forum","forum":{"title":"Forum","user":"alice","slug":"forum","posts":0,"threads":0}}`},
+		{"thread", models.TransferRecord{Type: RecordThread, Thread: &models.Thread{Id: 3, Title: "Thread", Author: "alice", Forum: "forum", Message: "Hi", Slug: "hi", Created: created}},
+			`{"type":"thread","thread":{"id":3,"title":"Thread","author":"alice","forum":"forum","message":"Hi","votes":0,"slug":"hi","created":"2023-11-14T22:13:20.123456Z"}}`},
+		{"post", models.TransferRecord{Type: RecordPost, Post: &models.Post{Id: 8, Parent: 5, Author: "bob", Message: "Reply", Forum: "forum", Thread: 3, Created: "2023-11-14T22:13:20.123456Z"}},
+			`{"type":"post","post":{"id":8,"parent":5,"author":"bob","message":"Reply","isEdited":false,"forum":"forum","thread":3,"created":"2023-11-14T22:13:20.123456Z","score":0}}`},
+		{"thread vote", models.TransferRecord{Type: RecordVote, Vote: &models.TransferVote{Nickname: "bob", Thread: 3, Voice: -1}},
+			`{"type":"vote","vote":{"nickname":"bob","thread":3,"voice":-1}}`},
+		{"post vote", models.TransferRecord{Type: RecordVote, Vote: &models.TransferVote{Nickname: "bob", Thread: 3, Post: 8, Voice: 1}},
+			`{"type":"vote","vote":{"nickname":"bob","thread":3,"post":8,"voice":1}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := test.record.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(line) != test.line {
+				t.Errorf("MarshalJSON() = %s, want %s", line, test.line)
+			}
+			if bytes.ContainsRune(line, '\n') {
+				t.Errorf("the record spans several lines: %s", line)
+			}
+
+			decoded := models.TransferRecord{}
+			if err = decoded.UnmarshalJSON(line); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, test.record) {
+				t.Errorf("UnmarshalJSON() = %+v, want %+v", decoded, test.record)
+			}
+		})
+	}
+}
+
+func TestRecordUnmarshalErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`{"type":"user"`,
+		`{"type":"vote","vote":{"nickname":"bob","thread":"three","voice":1}}`,
+		`[{"type":"user"}]`,
+	}
+	for _, line := range tests {
+		record := models.TransferRecord{}
+		if err := record.UnmarshalJSON([]byte(line)); err == nil {
+			t.Errorf("UnmarshalJSON(%q) = %+v, want an error", line, record)
+		}
+	}
+}
+
+func TestProgressAdd(t *testing.T) {
+	progress := Progress{}
+	for _, step := range []struct {
+		recordType string
+		count      int64
+	}{
+		{RecordUser, 2},
+		{RecordForum, 1},
+		{RecordThread, 3},
+		{RecordPost, 10},
+		{RecordVote, 4},
+		{RecordPost, 5},
+		{"unknown", 7},
+	} {
+		progress.add(step.recordType, step.count)
+	}
+	want := Progress{Users: 2, Forums: 1, Threads: 3, Posts: 15, Votes: 4}
+	if progress != want {
+		t.Errorf("progress = %+v, want %+v", progress, want)
+	}
+}
@@ -4,16 +4,26 @@ import (
 	"db_forum/app/models"
 	"db_forum/app/repositories"
 	"db_forum/pkg"
+	"time"
 )
 
 type ForumUsecase interface {
 	CreateForum(forum *models.Forum) (err error)
 	GetInfoAboutForum(slug string) (forum *models.Forum, err error)
 	CreateForumsThread(thread *models.Thread) (err error)
-	GetForumUsers(slug string, limit int, since string, desc bool) (users *models.Users, err error)
+	GetForumUsers(slug, sort string, limit int, since string, desc bool) (users *models.ForumUsers, err error)
+	GetForumLeaderboard(slug string, limit int) (leaderboard *models.Leaderboard, err error)
 	GetForumThreads(slug string, limit int, since string, desc bool) (threads *models.Threads, err error)
+	GetForumStats(slug, bucket string, from, to time.Time) (stats *models.ForumStats, err error)
 }
 
+// a stats request without from covers the last statsDefaultBuckets buckets
+// and may cover at most statsMaxBuckets
+const (
+	statsDefaultBuckets = 30
+	statsMaxBuckets     = 1000
+)
+
 type ForumUseCaseImpl struct {
 	repoForum  repositories.ForumRepository
 	repoThread repositories.ThreadRepository
@@ -71,25 +81,34 @@ func (forumUsecase *ForumUseCaseImpl) CreateForumsThread(thread *models.Thread)
 	return err
 }
 
-func (forumUsecase *ForumUseCaseImpl) GetForumUsers(slug string, limit int, since string, desc bool) (*models.Users, error) {
+func (forumUsecase *ForumUseCaseImpl) GetForumUsers(slug, sort string, limit int, since string, desc bool) (*models.ForumUsers, error) {
 	_, err := forumUsecase.repoForum.GetInfoAboutForum(slug)
 	if err != nil {
 		return nil, pkg.ErrForumNotExist
 	}
 
-	usersSlice, err := forumUsecase.repoForum.GetForumUsers(slug, limit, since, desc)
+	usersSlice, err := forumUsecase.repoForum.GetForumUsers(slug, sort, limit, since, desc)
 	if err != nil {
 		return nil, err
 	}
-	users := new(models.Users)
+	users := new(models.ForumUsers)
 	if len(*usersSlice) == 0 {
-		*users = []models.User{}
+		*users = []models.ForumUser{}
 	} else {
 		*users = *usersSlice
 	}
 	return users, err
 }
 
+func (forumUsecase *ForumUseCaseImpl) GetForumLeaderboard(slug string, limit int) (*models.Leaderboard, error) {
+	_, err := forumUsecase.repoForum.GetInfoAboutForum(slug)
+	if err != nil {
+		return nil, pkg.ErrForumNotExist
+	}
+
+	return forumUsecase.repoForum.GetForumLeaderboard(slug, limit)
+}
+
 func (forumUsecase *ForumUseCaseImpl) GetForumThreads(slug string, limit int, since string, desc bool) (*models.Threads, error) {
 	forum, err := forumUsecase.repoForum.GetInfoAboutForum(slug)
 	if err != nil {
@@ -108,3 +127,49 @@ func (forumUsecase *ForumUseCaseImpl) GetForumThreads(slug string, limit int, si
 	}
 	return threads, err
 }
+
+// GetForumStats returns the activity of the forum in every hour or day from
+// from until to, the ones without activity included. from is rounded down to
+// the start of its bucket, to defaults to now.
+func (forumUsecase *ForumUseCaseImpl) GetForumStats(slug, bucket string, from, to time.Time) (*models.ForumStats, error) {
+	var size time.Duration
+	switch bucket {
+	case "hour":
+		size = time.Hour
+	case "day":
+		size = 24 * time.Hour
+	default:
+		return nil, pkg.ErrBadRequest
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-statsDefaultBuckets * size)
+	}
+	from = from.Truncate(size)
+	if !from.Before(to) || to.Sub(from) > statsMaxBuckets*size {
+		return nil, pkg.ErrBadRequest
+	}
+
+	forum, err := forumUsecase.repoForum.GetInfoAboutForum(slug)
+	if err != nil {
+		return nil, pkg.ErrForumNotExist
+	}
+	stored, err := forumUsecase.repoForum.GetForumStats(forum.Slug, bucket, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int64]models.ForumStatsBucket, len(*stored))
+	for _, stat := range *stored {
+		buckets[stat.Start.Unix()] = stat
+	}
+	stats := make(models.ForumStats, 0, to.Sub(from)/size+1)
+	for start := from; start.Before(to); start = start.Add(size) {
+		stat := buckets[start.Unix()]
+		stat.Start = start.UTC()
+		stats = append(stats, stat)
+	}
+	return &stats, nil
+}
@@ -10,6 +10,7 @@ import (
 type PostUsecase interface {
 	GetInfoAboutPost(id int64, related string) (*models.PostFull, error)
 	UpdatePost(post *models.Post) (err error)
+	VoteForPost(id int64, vote *models.Vote) (*models.Post, error)
 }
 
 type PostUsecaseImpl struct {
@@ -17,11 +18,12 @@ type PostUsecaseImpl struct {
 	repoThread repositories.ThreadRepository
 	repoUser   repositories.UserRepository
 	repoPost   repositories.PostRepository
+	repoVote   repositories.VoteRepository
 }
 
 func MakePostUseCase(forum repositories.ForumRepository, thread repositories.ThreadRepository,
-	user repositories.UserRepository, post repositories.PostRepository) PostUsecase {
-	return &PostUsecaseImpl{repoForum: forum, repoThread: thread, repoUser: user, repoPost: post}
+	user repositories.UserRepository, post repositories.PostRepository, vote repositories.VoteRepository) PostUsecase {
+	return &PostUsecaseImpl{repoForum: forum, repoThread: thread, repoUser: user, repoPost: post, repoVote: vote}
 }
 
 func (postUsecase *PostUsecaseImpl) GetInfoAboutPost(id int64, related string) (*models.PostFull, error) {
@@ -73,7 +75,12 @@ func (postUsecase *PostUsecaseImpl) UpdatePost(post *models.Post) error {
 		return pkg.ErrThreadNotFound
 	}
 
+	if post.Version != 0 && post.Version != currentPost.Version {
+		return pkg.ErrPreconditionFailed
+	}
+
 	if post.Message != "" {
+		currentPost.Version = post.Version
 		if currentPost.Message != post.Message {
 			currentPost.IsEdited = true
 		}
@@ -86,3 +93,16 @@ func (postUsecase *PostUsecaseImpl) UpdatePost(post *models.Post) error {
 	*post = *currentPost
 	return nil
 }
+
+func (postUsecase *PostUsecaseImpl) VoteForPost(id int64, vote *models.Vote) (*models.Post, error) {
+	post, err := postUsecase.repoPost.GetPost(id)
+	if err != nil {
+		return nil, pkg.ErrPostNotFound
+	}
+
+	err = postUsecase.repoVote.VoteForPost(post, vote)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
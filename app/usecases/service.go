@@ -8,6 +8,7 @@ import (
 type ServiceUsecase interface {
 	ClearService() error
 	GetService() (*models.Status, error)
+	Reconcile(repair bool) (*models.Reconciliation, error)
 }
 
 type ServiceUsecaseImpl struct {
@@ -25,3 +26,7 @@ func (serviceUsecase *ServiceUsecaseImpl) ClearService() error {
 func (serviceUsecase *ServiceUsecaseImpl) GetService() (*models.Status, error) {
 	return serviceUsecase.repoService.GetService()
 }
+
+func (serviceUsecase *ServiceUsecaseImpl) Reconcile(repair bool) (*models.Reconciliation, error) {
+	return serviceUsecase.repoService.Reconcile(repair)
+}
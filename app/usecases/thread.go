@@ -13,6 +13,8 @@ type ThreadUsecase interface {
 	UpdateThread(slugOrID string, thread *models.Thread) error
 	GetThreadPosts(slugOrID string, limit, since int, sort string, desc bool) (*models.Posts, error)
 	VoteForThread(slugOrID string, vote *models.Vote) (*models.Thread, error)
+	RetractVote(slugOrID string, nickname string) (*models.Thread, error)
+	GetThreadVoters(slugOrID string, limit int, since string, desc bool) (*models.ThreadVoters, error)
 }
 
 type ThreadUsecaseImpl struct {
@@ -88,6 +90,10 @@ func (threadUsecase *ThreadUsecaseImpl) UpdateThread(slugOrID string, thread *mo
 	if err != nil {
 		return pkg.ErrThreadNotFound
 	}
+	if thread.Version != 0 && thread.Version != currentThread.Version {
+		return pkg.ErrPreconditionFailed
+	}
+	currentThread.Version = thread.Version
 	if thread.Title != "" {
 		currentThread.Title = thread.Title
 	}
@@ -122,6 +128,8 @@ func (threadUsecase *ThreadUsecaseImpl) GetThreadPosts(slugOrID string, limit, s
 		postsSlice, err = threadUsecase.repoThread.GetThreadPostsTree(thread.Id, limit, since, desc)
 	case "parent_tree":
 		postsSlice, err = threadUsecase.repoThread.GetThreadPostsParentTree(thread.Id, limit, since, desc)
+	case "top", "controversial":
+		postsSlice, err = threadUsecase.repoThread.GetThreadPostsRanked(thread.Id, sort, limit, since, desc)
 	default:
 		postsSlice, err = threadUsecase.repoThread.GetThreadPostsFlat(thread.Id, limit, since, desc)
 	}
@@ -146,11 +154,49 @@ func (threadUsecase *ThreadUsecaseImpl) VoteForThread(slugOrID string, vote *mod
 	} else {
 		thread, err = threadUsecase.repoThread.GetById(int64(id))
 	}
+	if err != nil {
+		return nil, pkg.ErrThreadNotFound
+	}
 
-	err = threadUsecase.repoVote.VoteForThread(thread.Id, vote)
+	err = threadUsecase.repoVote.VoteForThread(thread, vote)
 	if err != nil {
-		return nil, pkg.ErrUserNotFound
+		return nil, err
 	}
-	thread.Votes, err = threadUsecase.repoThread.GetThreadVotes(thread.Id)
-	return thread, err
+	return thread, nil
+}
+
+func (threadUsecase *ThreadUsecaseImpl) RetractVote(slugOrID string, nickname string) (*models.Thread, error) {
+	var thread *models.Thread
+	var err error
+	id, errConv := strconv.Atoi(slugOrID)
+	if errConv != nil {
+		thread, err = threadUsecase.repoThread.GetBySlug(slugOrID)
+	} else {
+		thread, err = threadUsecase.repoThread.GetById(int64(id))
+	}
+	if err != nil {
+		return nil, pkg.ErrThreadNotFound
+	}
+
+	err = threadUsecase.repoVote.DeleteVote(thread, nickname)
+	if err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+func (threadUsecase *ThreadUsecaseImpl) GetThreadVoters(slugOrID string, limit int, since string, desc bool) (*models.ThreadVoters, error) {
+	var thread *models.Thread
+	var err error
+	id, errConv := strconv.Atoi(slugOrID)
+	if errConv != nil {
+		thread, err = threadUsecase.repoThread.GetBySlug(slugOrID)
+	} else {
+		thread, err = threadUsecase.repoThread.GetById(int64(id))
+	}
+	if err != nil {
+		return nil, pkg.ErrThreadNotFound
+	}
+
+	return threadUsecase.repoVote.GetThreadVoters(thread.Id, limit, since, desc)
 }
@@ -4,14 +4,26 @@ import (
 	"db_forum/app/models"
 	"db_forum/app/repositories"
 	"db_forum/pkg"
+	"strings"
+	"time"
 )
 
 type UserUsecase interface {
 	CreateNewUser(user *models.User) (*models.Users, error)
 	GetInfoAboutUser(nickname string) (*models.User, error)
 	UpdateUser(user *models.User) error
+	GetUserPosts(nickname string, limit, since int, desc bool) (*models.Posts, error)
+	GetUserThreads(nickname string, limit int, since string, desc bool) (*models.Threads, error)
+	GetUserForums(nickname string, limit int, since string, desc bool) (*models.UserForums, error)
+	ExportUser(nickname string) (*models.UserExport, error)
+	DeleteUser(nickname string) error
+	RenameUser(user *models.User, nickname string) error
+	GetRenamedUser(nickname string) (string, error)
 }
 
+// the old nickname of a renamed user redirects to the new one this long
+const renameRedirectTTL = 30 * 24 * time.Hour
+
 type UserUsecaseImpl struct {
 	repoUser repositories.UserRepository
 }
@@ -22,6 +34,9 @@ func MakeUserUseCase(user repositories.UserRepository) UserUsecase {
 
 func (userUsecase *UserUsecaseImpl) CreateNewUser(user *models.User) (*models.Users, error) {
 	var users *models.Users
+	if models.ClaimsTombstone(user.Nickname, user.Email) {
+		return users, pkg.ErrUserIsTombstone
+	}
 	similarUsers, err := userUsecase.repoUser.GetSimilarUsers(user)
 	if err != nil {
 		return users, pkg.ErrUserAlreadyExist
@@ -47,6 +62,9 @@ func (userUsecase *UserUsecaseImpl) UpdateUser(user *models.User) error {
 	if oldUser.Nickname == "" {
 		return pkg.ErrUserNotFound
 	}
+	if user.Version != 0 && user.Version != oldUser.Version {
+		return pkg.ErrPreconditionFailed
+	}
 	if oldUser.Fullname != user.Fullname && user.Fullname == "" {
 		user.Fullname = oldUser.Fullname
 	}
@@ -56,9 +74,116 @@ func (userUsecase *UserUsecaseImpl) UpdateUser(user *models.User) error {
 	if oldUser.Email != user.Email && user.Email == "" {
 		user.Email = oldUser.Email
 	}
+	if !strings.EqualFold(oldUser.Email, user.Email) && models.ClaimsTombstone("", user.Email) {
+		return pkg.ErrUserIsTombstone
+	}
 	err = userUsecase.repoUser.UpdateUser(user)
+	if err == pkg.ErrPreconditionFailed {
+		return err
+	}
 	if err != nil {
 		return pkg.ErrUserDataConflict
 	}
 	return nil
 }
+
+func (userUsecase *UserUsecaseImpl) GetUserPosts(nickname string, limit, since int, desc bool) (*models.Posts, error) {
+	_, err := userUsecase.repoUser.GetInfoAboutUser(nickname)
+	if err != nil {
+		return nil, pkg.ErrUserNotFound
+	}
+
+	postsSlice, err := userUsecase.repoUser.GetUserPosts(nickname, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	posts := new(models.Posts)
+	if len(*postsSlice) == 0 {
+		*posts = []models.Post{}
+	} else {
+		*posts = *postsSlice
+	}
+	return posts, nil
+}
+
+func (userUsecase *UserUsecaseImpl) GetUserThreads(nickname string, limit int, since string, desc bool) (*models.Threads, error) {
+	_, err := userUsecase.repoUser.GetInfoAboutUser(nickname)
+	if err != nil {
+		return nil, pkg.ErrUserNotFound
+	}
+
+	threadsSlice, err := userUsecase.repoUser.GetUserThreads(nickname, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	threads := new(models.Threads)
+	if len(*threadsSlice) == 0 {
+		*threads = []models.Thread{}
+	} else {
+		*threads = *threadsSlice
+	}
+	return threads, nil
+}
+
+func (userUsecase *UserUsecaseImpl) GetUserForums(nickname string, limit int, since string, desc bool) (*models.UserForums, error) {
+	_, err := userUsecase.repoUser.GetInfoAboutUser(nickname)
+	if err != nil {
+		return nil, pkg.ErrUserNotFound
+	}
+
+	forumsSlice, err := userUsecase.repoUser.GetUserForums(nickname, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	forums := new(models.UserForums)
+	*forums = *forumsSlice
+	return forums, nil
+}
+
+func (userUsecase *UserUsecaseImpl) ExportUser(nickname string) (*models.UserExport, error) {
+	export, err := userUsecase.repoUser.ExportUser(nickname)
+	if err != nil {
+		return nil, pkg.ErrUserNotFound
+	}
+	return export, nil
+}
+
+func (userUsecase *UserUsecaseImpl) DeleteUser(nickname string) error {
+	if strings.EqualFold(nickname, models.DeletedUser.Nickname) {
+		return pkg.ErrUserIsTombstone
+	}
+	user, err := userUsecase.repoUser.GetInfoAboutUser(nickname)
+	if err != nil {
+		return pkg.ErrUserNotFound
+	}
+	return userUsecase.repoUser.DeleteUser(user.Nickname)
+}
+
+func (userUsecase *UserUsecaseImpl) RenameUser(user *models.User, nickname string) error {
+	if strings.EqualFold(user.Nickname, models.DeletedUser.Nickname) || strings.EqualFold(nickname, models.DeletedUser.Nickname) {
+		return pkg.ErrUserIsTombstone
+	}
+	oldUser, err := userUsecase.repoUser.GetInfoAboutUser(user.Nickname)
+	if err != nil {
+		return pkg.ErrUserNotFound
+	}
+	if user.Version != 0 && user.Version != oldUser.Version {
+		return pkg.ErrPreconditionFailed
+	}
+	if other, err := userUsecase.repoUser.GetInfoAboutUser(nickname); err == nil && !strings.EqualFold(other.Nickname, oldUser.Nickname) {
+		return pkg.ErrUserAlreadyExist
+	}
+
+	user.Nickname = oldUser.Nickname
+	return userUsecase.repoUser.RenameUser(user, nickname, renameRedirectTTL)
+}
+
+// GetRenamedUser returns the current nickname of a user that was renamed from
+// nickname recently enough to redirect.
+func (userUsecase *UserUsecaseImpl) GetRenamedUser(nickname string) (string, error) {
+	target, err := userUsecase.repoUser.GetUserRedirect(nickname, renameRedirectTTL)
+	if err != nil {
+		return "", pkg.ErrUserNotFound
+	}
+	return target, nil
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/pkg"
+	"encoding/hex"
+)
+
+type WebhookUsecase interface {
+	CreateWebhook(webhook *models.Webhook) error
+	GetForumWebhooks(slug string) (*models.Webhooks, error)
+	DeleteWebhook(slug string, id int64) error
+	GetWebhookDeliveries(slug string, id int64, limit int, since int64, desc bool) (*models.WebhookDeliveries, error)
+}
+
+type WebhookUsecaseImpl struct {
+	repoForum   repositories.ForumRepository
+	repoWebhook repositories.WebhookRepository
+}
+
+func MakeWebhookUseCase(forum repositories.ForumRepository, webhook repositories.WebhookRepository) WebhookUsecase {
+	return &WebhookUsecaseImpl{repoForum: forum, repoWebhook: webhook}
+}
+
+func (webhookUsecase *WebhookUsecaseImpl) CreateWebhook(webhook *models.Webhook) error {
+	forum, err := webhookUsecase.repoForum.GetInfoAboutForum(webhook.Forum)
+	if err != nil {
+		return pkg.ErrForumNotExist
+	}
+	webhook.Forum = forum.Slug
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	return webhookUsecase.repoWebhook.CreateWebhook(webhook)
+}
+
+func (webhookUsecase *WebhookUsecaseImpl) GetForumWebhooks(slug string) (*models.Webhooks, error) {
+	forum, err := webhookUsecase.repoForum.GetInfoAboutForum(slug)
+	if err != nil {
+		return nil, pkg.ErrForumNotExist
+	}
+
+	webhooksSlice, err := webhookUsecase.repoWebhook.GetForumWebhooks(forum.Slug)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := new(models.Webhooks)
+	*webhooks = *webhooksSlice
+	return webhooks, nil
+}
+
+func (webhookUsecase *WebhookUsecaseImpl) DeleteWebhook(slug string, id int64) error {
+	forum, err := webhookUsecase.repoForum.GetInfoAboutForum(slug)
+	if err != nil {
+		return pkg.ErrForumNotExist
+	}
+
+	deleted, err := webhookUsecase.repoWebhook.DeleteWebhook(forum.Slug, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return pkg.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (webhookUsecase *WebhookUsecaseImpl) GetWebhookDeliveries(slug string, id int64, limit int, since int64, desc bool) (*models.WebhookDeliveries, error) {
+	forum, err := webhookUsecase.repoForum.GetInfoAboutForum(slug)
+	if err != nil {
+		return nil, pkg.ErrForumNotExist
+	}
+
+	_, err = webhookUsecase.repoWebhook.GetWebhook(forum.Slug, id)
+	if err != nil {
+		return nil, pkg.ErrWebhookNotFound
+	}
+
+	deliveriesSlice, err := webhookUsecase.repoWebhook.GetDeliveries(id, limit, since, desc)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := new(models.WebhookDeliveries)
+	*deliveries = *deliveriesSlice
+	return deliveries, nil
+}
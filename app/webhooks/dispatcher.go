@@ -0,0 +1,134 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Forum-Event"
+	DeliveryHeader  = "X-Forum-Delivery"
+	TimestampHeader = "X-Forum-Timestamp"
+	SignatureHeader = "X-Forum-Signature"
+
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+
+	MaxAttempts = 10
+	batchSize   = 50
+	lease       = time.Minute
+	baseBackoff = 10 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// Dispatcher delivers the webhook_deliveries queue, several instances may run
+// it at once since deliveries are claimed with skip locked.
+type Dispatcher struct {
+	repoWebhook repositories.WebhookRepository
+	client      *http.Client
+}
+
+func MakeDispatcher(webhook repositories.WebhookRepository, client *http.Client) *Dispatcher {
+	return &Dispatcher{repoWebhook: webhook, client: client}
+}
+
+func (dispatcher *Dispatcher) Run(interval time.Duration) {
+	for {
+		claimed, err := dispatcher.DeliverDue()
+		if err != nil {
+			fmt.Println(err)
+		}
+		if claimed < batchSize {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were claimed.
+// A delivery whose result cannot be saved is logged and left to its lease, the
+// rest of the batch is still sent.
+func (dispatcher *Dispatcher) DeliverDue() (int, error) {
+	deliveries, err := dispatcher.repoWebhook.ClaimDeliveries(batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range *deliveries {
+		err = dispatcher.deliver(&(*deliveries)[i])
+		if err != nil {
+			fmt.Printf("webhook delivery %d: %s\n", (*deliveries)[i].Id, err)
+		}
+	}
+	return len(*deliveries), nil
+}
+
+func (dispatcher *Dispatcher) deliver(delivery *models.WebhookDelivery) error {
+	responseCode, err := dispatcher.send(delivery)
+	if err == nil {
+		return dispatcher.repoWebhook.MarkDelivered(delivery.Id, responseCode)
+	}
+
+	status := StatusPending
+	if delivery.Attempts >= MaxAttempts {
+		status = StatusFailed
+	}
+	return dispatcher.repoWebhook.MarkFailed(delivery.Id, status, responseCode, err.Error(), Backoff(int(delivery.Attempts)))
+}
+
+func (dispatcher *Dispatcher) send(delivery *models.WebhookDelivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set(EventHeader, delivery.Event)
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.Id, 10))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("receiver responded with %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign returns the X-Forum-Signature value: an HMAC-SHA256 of
+// "<timestamp>.<payload>" keyed with the webhook secret.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is the delay before the next attempt: 10s, 20s, 40s... capped at 6h.
+func Backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRepository keeps deliveries in memory; every pending delivery is due.
+type fakeRepository struct {
+	repositories.WebhookRepository
+	mutex      sync.Mutex
+	deliveries []*models.WebhookDelivery
+	markErrors map[int64]error
+}
+
+func (repository *fakeRepository) ClaimDeliveries(limit int, lease time.Duration) (*[]models.WebhookDelivery, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	claimed := make([]models.WebhookDelivery, 0)
+	for _, delivery := range repository.deliveries {
+		if delivery.Status == StatusPending && len(claimed) < limit {
+			delivery.Attempts++
+			claimed = append(claimed, *delivery)
+		}
+	}
+	return &claimed, nil
+}
+
+func (repository *fakeRepository) MarkDelivered(id int64, responseCode int) error {
+	return repository.mark(id, "delivered", responseCode, "")
+}
+
+func (repository *fakeRepository) MarkFailed(id int64, status string, responseCode int, lastError string, retryIn time.Duration) error {
+	return repository.mark(id, status, responseCode, lastError)
+}
+
+func (repository *fakeRepository) mark(id int64, status string, responseCode int, lastError string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if err := repository.markErrors[id]; err != nil {
+		return err
+	}
+	for _, delivery := range repository.deliveries {
+		if delivery.Id == id {
+			delivery.Status = status
+			delivery.ResponseCode = int32(responseCode)
+			delivery.LastError = lastError
+		}
+	}
+	return nil
+}
+
+func makeDelivery(id int64, url string) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		Id:      id,
+		Event:   "thread.created",
+		Payload: []byte(`{"id":1}`),
+		Status:  StatusPending,
+		Url:     url,
+		Secret:  "secret",
+	}
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	var mutex sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			t.Errorf("bad %s header: %v", TimestampHeader, err)
+		}
+		if signature := r.Header.Get(SignatureHeader); signature != Sign("secret", timestamp, body) {
+			t.Errorf("signature %q does not match the body", signature)
+		}
+		if event := r.Header.Get(EventHeader); event != "thread.created" {
+			t.Errorf("event header = %q", event)
+		}
+		if delivery := r.Header.Get(DeliveryHeader); delivery != "1" {
+			t.Errorf("delivery header = %q", delivery)
+		}
+
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := makeDelivery(1, server.URL)
+	repository := &fakeRepository{deliveries: []*models.WebhookDelivery{delivery}}
+	dispatcher := MakeDispatcher(repository, server.Client())
+
+	if _, err := dispatcher.DeliverDue(); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != StatusPending || delivery.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("after a 500: status %q, response %d", delivery.Status, delivery.ResponseCode)
+	}
+
+	if _, err := dispatcher.DeliverDue(); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != "delivered" || delivery.ResponseCode != http.StatusNoContent || delivery.Attempts != 2 {
+		t.Fatalf("after the retry: status %q, response %d, attempts %d", delivery.Status, delivery.ResponseCode, delivery.Attempts)
+	}
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	var mutex sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		calls++
+		mutex.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	delivery := makeDelivery(1, server.URL)
+	repository := &fakeRepository{deliveries: []*models.WebhookDelivery{delivery}}
+	dispatcher := MakeDispatcher(repository, server.Client())
+
+	for i := 0; i < MaxAttempts+3; i++ {
+		if _, err := dispatcher.DeliverDue(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if delivery.Status != StatusFailed {
+		t.Fatalf("status = %q, want %q", delivery.Status, StatusFailed)
+	}
+	if calls != MaxAttempts || delivery.Attempts != MaxAttempts {
+		t.Fatalf("receiver called %d times, %d attempts, want %d", calls, delivery.Attempts, MaxAttempts)
+	}
+}
+
+func TestDispatcherContinuesAfterMarkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	first, second := makeDelivery(1, server.URL), makeDelivery(2, server.URL)
+	repository := &fakeRepository{
+		deliveries: []*models.WebhookDelivery{first, second},
+		markErrors: map[int64]error{1: errors.New("connection reset")},
+	}
+	dispatcher := MakeDispatcher(repository, server.Client())
+
+	claimed, err := dispatcher.DeliverDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != 2 {
+		t.Fatalf("claimed = %d, want 2", claimed)
+	}
+	if first.Status != StatusPending || second.Status != "delivered" {
+		t.Fatalf("statuses = %q, %q", first.Status, second.Status)
+	}
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		payload   string
+		want      string
+	}{
+		{"empty", "", 0, "", "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+		{"event", "secret", 1700000000, `{"id":1}`, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Sign(test.secret, test.timestamp, []byte(test.payload)); got != test.want {
+				t.Errorf("Sign() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{10, 5120 * time.Second},
+		{12, 20480 * time.Second},
+		{13, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, test := range tests {
+		if got := Backoff(test.attempts); got != test.want {
+			t.Errorf("Backoff(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package webhooks
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+)
+
+// Sink turns outbox events into webhook deliveries of the event's forum.
+type Sink struct {
+	repoWebhook repositories.WebhookRepository
+}
+
+func MakeSink(webhook repositories.WebhookRepository) *Sink {
+	return &Sink{repoWebhook: webhook}
+}
+
+func (sink *Sink) Name() string {
+	return "webhooks"
+}
+
+func (sink *Sink) Publish(event *models.OutboxEvent) error {
+	if event.Forum == "" {
+		return nil
+	}
+	return sink.repoWebhook.EnqueueDeliveries(event)
+}
@@ -0,0 +1,245 @@
+// Package client is a Go client of the forum API.
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy decides how often a request failing with a network error or a
+// retryable status (429 and 5xx but 501) is sent again.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt too, values below 1 mean 1.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for every next one.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var (
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+	NoRetry            = RetryPolicy{MaxAttempts: 1}
+)
+
+func (policy RetryPolicy) delay(retry int) time.Duration {
+	delay := policy.Backoff
+	for i := 0; i < retry && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+type Option func(client *Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(client *Client) {
+		client.retry = policy
+	}
+}
+
+// MakeClient returns a client of the API served at baseURL, e.g.
+// "http://127.0.0.1:5000".
+func MakeClient(baseURL string, options ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		retry:      DefaultRetryPolicy,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+type request struct {
+	method string
+	route  string
+	path   string
+	query  url.Values
+	body   easyjson.Marshaler
+	header http.Header
+	// create marks requests sent with an Idempotency-Key, so retrying them
+	// never creates twice.
+	create bool
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// send performs the request with retries. Responses with an error status are
+// returned together with an *Error, conflicts carry the existing entities.
+func (client *Client) send(request request, result easyjson.Unmarshaler) (*response, error) {
+	var body []byte
+	if request.body != nil {
+		var err error
+		body, err = easyjson.Marshal(request.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := request.header
+	if header == nil {
+		header = make(http.Header)
+	}
+	if request.create {
+		key, err := makeIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		header.Set(idempotencyKeyHeader, key)
+	}
+
+	target := client.baseURL + pkg.RootRoute + request.route + request.path
+	if len(request.query) > 0 {
+		target += "?" + request.query.Encode()
+	}
+
+	attempts := client.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var response *response
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(client.retry.delay(attempt - 1))
+		}
+		response, err = client.roundTrip(request.method, target, header, body)
+		if err == nil && !retryable(response.status) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if response.status >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: response.status}
+		if isErrorBody(response.body) {
+			errorModel := models.Error{}
+			if easyjson.Unmarshal(response.body, &errorModel) == nil {
+				apiErr.Message = errorModel.Message
+				apiErr.Fields = errorModel.Fields
+			}
+		} else if response.status == http.StatusConflict && result != nil {
+			_ = easyjson.Unmarshal(response.body, result)
+		}
+		return response, apiErr
+	}
+
+	if result != nil && response.status != http.StatusNoContent {
+		if err = easyjson.Unmarshal(response.body, result); err != nil {
+			return response, err
+		}
+	}
+	return response, nil
+}
+
+func (client *Client) roundTrip(method, target string, header http.Header, body []byte) (*response, error) {
+	httpRequest, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		httpRequest.Header[name] = values
+	}
+	if body != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := client.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: httpResponse.StatusCode, header: httpResponse.Header, body: responseBody}, nil
+}
+
+// isErrorBody tells a models.Error apart from the existing entities sent
+// with some conflicts, a thread has a message too.
+func isErrorBody(body []byte) bool {
+	fields := make(map[string]json.RawMessage)
+	if json.Unmarshal(body, &fields) != nil {
+		return false
+	}
+	for field := range fields {
+		if field != "message" && field != "fields" {
+			return false
+		}
+	}
+	return true
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests ||
+		(status >= http.StatusInternalServerError && status != http.StatusNotImplemented)
+}
+
+func makeIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// version reads the version of an entity from the ETag of its response.
+func version(response *response) int64 {
+	etag, err := strconv.Unquote(strings.TrimPrefix(response.header.Get(pkg.ETagHeader), "W/"))
+	if err != nil {
+		return 0
+	}
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// ifMatch conditions an update on the version the caller has read, if any.
+func ifMatch(version int64) http.Header {
+	header := make(http.Header)
+	if version != 0 {
+		header.Set(pkg.IfMatchHeader, pkg.CreateETag(version))
+	}
+	return header
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"fmt"
+	"net/http"
+)
+
+// Error is an error response of the API. The sentinels below match any error
+// with their status code:
+//
+//	if errors.Is(err, client.ErrNotFound) { ... }
+type Error struct {
+	StatusCode int
+	Message    string
+	Fields     []models.FieldError
+}
+
+var (
+	ErrBadRequest         = &Error{StatusCode: http.StatusBadRequest}
+	ErrNotFound           = &Error{StatusCode: http.StatusNotFound}
+	ErrConflict           = &Error{StatusCode: http.StatusConflict}
+	ErrPreconditionFailed = &Error{StatusCode: http.StatusPreconditionFailed}
+	ErrInternal           = &Error{StatusCode: http.StatusInternalServerError}
+)
+
+func (err *Error) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("forum api: %d %s", err.StatusCode, http.StatusText(err.StatusCode))
+	}
+	return fmt.Sprintf("forum api: %d %s", err.StatusCode, err.Message)
+}
+
+func (err *Error) Is(target error) bool {
+	sentinel, ok := target.(*Error)
+	return ok && sentinel.Message == "" && sentinel.StatusCode == err.StatusCode
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListOptions page the users and threads of a forum and the threads and forums
+// of a user. Since is a nickname for users, a slug for forums and an RFC 3339
+// time for threads. Sort orders the users of a forum: "nickname" (the
+// default), "activity", "posts" or "joined"; the other lists ignore it.
+type ListOptions struct {
+	Sort     string
+	Since    string
+	Desc     bool
+	PageSize int
+}
+
+// CreateForum creates the forum and fills it in from the response. If the
+// slug is taken, forum is filled in with the existing forum and the error
+// matches ErrConflict.
+func (client *Client) CreateForum(forum *models.Forum) error {
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ForumRoute, path: "/create", body: forum, create: true}, forum)
+	return err
+}
+
+func (client *Client) GetForum(slug string) (*models.Forum, error) {
+	forum := new(models.Forum)
+	response, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: "/" + url.PathEscape(slug) + "/details"}, forum)
+	if err != nil {
+		return nil, err
+	}
+	forum.Version = version(response)
+	return forum, nil
+}
+
+// CreateThread creates the thread in the forum. If its slug is taken, thread
+// is filled in with the existing thread and the error matches ErrConflict.
+func (client *Client) CreateThread(forum string, thread *models.Thread) error {
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ForumRoute, path: "/" + url.PathEscape(forum) + "/create", body: thread, create: true}, thread)
+	return err
+}
+
+// ForumUsers iterates over the participants of the forum with their activity
+// in it, ordered by options.Sort.
+func (client *Client) ForumUsers(forum string, options ListOptions) *ForumUserIterator {
+	iterator := new(ForumUserIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	iterator.fetch = func() (int, bool, error) {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "desc": {strconv.FormatBool(options.Desc)}}
+		if options.Sort != "" {
+			query.Set("sort", options.Sort)
+		}
+		if since != "" {
+			query.Set("since", since)
+		}
+		iterator.users = nil
+		_, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: "/" + url.PathEscape(forum) + "/users", query: query}, &iterator.users)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(iterator.users) > 0 {
+			since = iterator.users[len(iterator.users)-1].Nickname
+		}
+		return len(iterator.users), len(iterator.users) == limit, nil
+	}
+	return iterator
+}
+
+// ForumLeaderboard returns the limit participants of the forum with the most
+// reputation earned in it.
+func (client *Client) ForumLeaderboard(forum string, limit int) (models.Leaderboard, error) {
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	var leaderboard models.Leaderboard
+	_, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: "/" + url.PathEscape(forum) + "/leaderboard", query: query}, &leaderboard)
+	if err != nil {
+		return nil, err
+	}
+	return leaderboard, nil
+}
+
+// ForumStats returns the activity of the forum in every bucket ("hour" or
+// "day") from from until to. Zero times and an empty bucket leave the
+// defaults of the server: the last 30 days until now.
+func (client *Client) ForumStats(forum, bucket string, from, to time.Time) (models.ForumStats, error) {
+	query := url.Values{}
+	if bucket != "" {
+		query.Set("bucket", bucket)
+	}
+	if !from.IsZero() {
+		query.Set("from", from.Format(time.RFC3339Nano))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339Nano))
+	}
+	var stats models.ForumStats
+	_, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: "/" + url.PathEscape(forum) + "/stats", query: query}, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ForumThreads iterates over the threads of the forum ordered by creation
+// time. The since filter of the API is inclusive, so threads of the previous
+// page created at the boundary time are skipped.
+func (client *Client) ForumThreads(forum string, options ListOptions) *ThreadIterator {
+	return client.threads(pkg.ForumRoute, "/"+url.PathEscape(forum)+"/threads", options)
+}
+
+func (client *Client) threads(route, path string, options ListOptions) *ThreadIterator {
+	iterator := new(ThreadIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	seen := make(map[int64]bool)
+	iterator.fetch = func() (int, bool, error) {
+		for {
+			query := url.Values{"limit": {strconv.Itoa(limit)}, "desc": {strconv.FormatBool(options.Desc)}}
+			if since != "" {
+				query.Set("since", since)
+			}
+			var threads models.Threads
+			_, err := client.send(request{method: http.MethodGet, route: route, path: path, query: query}, &threads)
+			if err != nil {
+				return 0, false, err
+			}
+
+			iterator.threads = iterator.threads[:0]
+			for _, thread := range threads {
+				if !seen[thread.Id] {
+					iterator.threads = append(iterator.threads, thread)
+				}
+			}
+			more := len(threads) == limit
+			if len(iterator.threads) == 0 && more {
+				// the whole page was created at the boundary time
+				limit *= 2
+				continue
+			}
+
+			if len(threads) > 0 {
+				last := threads[len(threads)-1].Created
+				if last.Format(time.RFC3339Nano) != since {
+					seen = make(map[int64]bool)
+				}
+				for _, thread := range threads {
+					if thread.Created.Equal(last) {
+						seen[thread.Id] = true
+					}
+				}
+				since = last.Format(time.RFC3339Nano)
+			}
+			return len(iterator.threads), more, nil
+		}
+	}
+	return iterator
+}
@@ -0,0 +1,90 @@
+package client
+
+import "db_forum/app/models"
+
+// pager walks a paginated endpoint one page at a time. fetch loads the next
+// page into the iterator embedding the pager and reports its size and
+// whether more pages may follow.
+type pager struct {
+	fetch func() (size int, more bool, err error)
+
+	index int
+	size  int
+	done  bool
+	err   error
+}
+
+// Next advances to the next item, fetching a page when needed. It returns
+// false once the items are exhausted or a request failed, see Err.
+func (pager *pager) Next() bool {
+	pager.index++
+	for pager.index >= pager.size {
+		if pager.done || pager.err != nil {
+			return false
+		}
+		var more bool
+		pager.size, more, pager.err = pager.fetch()
+		pager.index = 0
+		pager.done = !more
+		if pager.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (pager *pager) Err() error {
+	return pager.err
+}
+
+func pageSize(size int) int {
+	if size <= 0 {
+		return 100
+	}
+	return size
+}
+
+type ForumUserIterator struct {
+	pager
+	users models.ForumUsers
+}
+
+func (iterator *ForumUserIterator) User() models.ForumUser {
+	return iterator.users[iterator.index]
+}
+
+type ThreadIterator struct {
+	pager
+	threads models.Threads
+}
+
+func (iterator *ThreadIterator) Thread() models.Thread {
+	return iterator.threads[iterator.index]
+}
+
+type PostIterator struct {
+	pager
+	posts models.Posts
+}
+
+func (iterator *PostIterator) Post() models.Post {
+	return iterator.posts[iterator.index]
+}
+
+type DeliveryIterator struct {
+	pager
+	deliveries models.WebhookDeliveries
+}
+
+func (iterator *DeliveryIterator) Delivery() models.WebhookDelivery {
+	return iterator.deliveries[iterator.index]
+}
+
+type UserForumIterator struct {
+	pager
+	forums models.UserForums
+}
+
+func (iterator *UserForumIterator) Forum() models.UserForum {
+	return iterator.forums[iterator.index]
+}
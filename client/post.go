@@ -0,0 +1,52 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GetPost returns the post with the related entities asked for: "user",
+// "thread" and "forum".
+func (client *Client) GetPost(id int64, related ...string) (*models.PostFull, error) {
+	var query url.Values
+	if len(related) > 0 {
+		query = url.Values{"related": {strings.Join(related, ",")}}
+	}
+	postFull := new(models.PostFull)
+	response, err := client.send(request{method: http.MethodGet, route: pkg.PostRoute, path: "/" + strconv.FormatInt(id, 10) + "/details", query: query}, postFull)
+	if err != nil {
+		return nil, err
+	}
+	if len(related) == 0 && postFull.Post != nil {
+		postFull.Post.Version = version(response)
+	}
+	return postFull, nil
+}
+
+// UpdatePost updates the message of the post, conditioned on its Version like
+// UpdateThread.
+func (client *Client) UpdatePost(post *models.Post) error {
+	update := &models.PostUpdate{Message: post.Message}
+	response, err := client.send(request{method: http.MethodPost, route: pkg.PostRoute, path: "/" + strconv.FormatInt(post.Id, 10) + "/details", body: update, header: ifMatch(post.Version)}, post)
+	if err != nil {
+		return err
+	}
+	post.Version = version(response)
+	return nil
+}
+
+// VotePost sets the voice of vote.Nickname for the post and returns the post
+// with its updated score.
+func (client *Client) VotePost(id int64, vote *models.Vote) (*models.Post, error) {
+	post := new(models.Post)
+	response, err := client.send(request{method: http.MethodPost, route: pkg.PostRoute, path: "/" + strconv.FormatInt(id, 10) + "/vote", body: vote}, post)
+	if err != nil {
+		return nil, err
+	}
+	post.Version = version(response)
+	return post, nil
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Clear deletes all the data of the forum.
+func (client *Client) Clear() error {
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ServiceRoute, path: "/clear"}, nil)
+	return err
+}
+
+func (client *Client) Status() (*models.Status, error) {
+	status := new(models.Status)
+	_, err := client.send(request{method: http.MethodGet, route: pkg.ServiceRoute, path: "/status"}, status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Reconcile reports the counters that disagree with the rows they count, and
+// repairs them if repair is set.
+func (client *Client) Reconcile(repair bool) (*models.Reconciliation, error) {
+	reconciliation := new(models.Reconciliation)
+	query := url.Values{"repair": {strconv.FormatBool(repair)}}
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ServiceRoute, path: "/reconcile", query: query}, reconciliation)
+	if err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
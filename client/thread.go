@@ -0,0 +1,133 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PostListOptions page the posts of a thread. Sort is "flat" (the default),
+// "tree", "parent_tree", "top" or "controversial"; for the last three PageSize
+// counts root posts. Since is the id of a post to start after.
+type PostListOptions struct {
+	Sort     string
+	Since    int64
+	Desc     bool
+	PageSize int
+}
+
+func threadPath(slugOrId string) string {
+	return "/" + url.PathEscape(slugOrId)
+}
+
+// CreatePosts adds the posts to the thread and returns them as created.
+func (client *Client) CreatePosts(slugOrId string, posts models.Posts) (models.Posts, error) {
+	var created models.Posts
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/create", body: posts, create: true}, &created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (client *Client) GetThread(slugOrId string) (*models.Thread, error) {
+	thread := new(models.Thread)
+	response, err := client.send(request{method: http.MethodGet, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/details"}, thread)
+	if err != nil {
+		return nil, err
+	}
+	thread.Version = version(response)
+	return thread, nil
+}
+
+// UpdateThread updates the title and message of the thread. A thread with a
+// Version, as returned by GetThread, is only updated if nobody changed it
+// since, otherwise the error matches ErrPreconditionFailed.
+func (client *Client) UpdateThread(slugOrId string, thread *models.Thread) error {
+	update := &models.ThreadUpdate{Title: thread.Title, Message: thread.Message}
+	response, err := client.send(request{method: http.MethodPost, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/details", body: update, header: ifMatch(thread.Version)}, thread)
+	if err != nil {
+		return err
+	}
+	thread.Version = version(response)
+	return nil
+}
+
+func (client *Client) ThreadPosts(slugOrId string, options PostListOptions) *PostIterator {
+	iterator := new(PostIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	sort := options.Sort
+	if sort == "" {
+		sort = "flat"
+	}
+	iterator.fetch = func() (int, bool, error) {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "sort": {sort}, "desc": {strconv.FormatBool(options.Desc)}}
+		if since > 0 {
+			query.Set("since", strconv.FormatInt(since, 10))
+		}
+		iterator.posts = nil
+		_, err := client.send(request{method: http.MethodGet, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/posts", query: query}, &iterator.posts)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(iterator.posts) == 0 {
+			return 0, false, nil
+		}
+		since = iterator.posts[len(iterator.posts)-1].Id
+
+		more := len(iterator.posts) == limit
+		if sort == "parent_tree" || sort == "top" || sort == "controversial" {
+			roots := 0
+			for _, post := range iterator.posts {
+				if post.Parent == 0 {
+					roots++
+				}
+			}
+			more = roots == limit
+		}
+		return len(iterator.posts), more, nil
+	}
+	return iterator
+}
+
+// Vote sets the voice of vote.Nickname in the thread and returns the thread
+// with its updated votes.
+func (client *Client) Vote(slugOrId string, vote *models.Vote) (*models.Thread, error) {
+	thread := new(models.Thread)
+	response, err := client.send(request{method: http.MethodPost, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/vote", body: vote}, thread)
+	if err != nil {
+		return nil, err
+	}
+	thread.Version = version(response)
+	return thread, nil
+}
+
+// RetractVote removes the vote of nickname in the thread and returns the
+// thread with its updated votes.
+func (client *Client) RetractVote(slugOrId, nickname string) (*models.Thread, error) {
+	thread := new(models.Thread)
+	query := url.Values{"nickname": {nickname}}
+	_, err := client.send(request{method: http.MethodDelete, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/vote", query: query}, thread)
+	if err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// ThreadVoters returns a page of the voters of the thread, Since being a
+// nickname, with the counts over all of them.
+func (client *Client) ThreadVoters(slugOrId string, options ListOptions) (*models.ThreadVoters, error) {
+	query := url.Values{"limit": {strconv.Itoa(pageSize(options.PageSize))}, "desc": {strconv.FormatBool(options.Desc)}}
+	if options.Since != "" {
+		query.Set("since", options.Since)
+	}
+	voters := new(models.ThreadVoters)
+	_, err := client.send(request{method: http.MethodGet, route: pkg.ThreadRoute, path: threadPath(slugOrId) + "/votes", query: query}, voters)
+	if err != nil {
+		return nil, err
+	}
+	return voters, nil
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func userPath(nickname string) string {
+	return "/" + url.PathEscape(nickname)
+}
+
+// CreateUser creates the user. If the nickname or email is taken, the users
+// holding them are returned and the error matches ErrConflict.
+func (client *Client) CreateUser(user *models.User) (models.Users, error) {
+	var conflicting models.Users
+	response, err := client.send(request{method: http.MethodPost, route: pkg.UserRoute, path: userPath(user.Nickname) + "/create", body: user, create: true}, nil)
+	if err != nil {
+		if response != nil && response.status == http.StatusConflict {
+			_ = conflicting.UnmarshalJSON(response.body)
+		}
+		return conflicting, err
+	}
+	return nil, user.UnmarshalJSON(response.body)
+}
+
+func (client *Client) GetUser(nickname string) (*models.User, error) {
+	user := new(models.User)
+	response, err := client.send(request{method: http.MethodGet, route: pkg.UserRoute, path: userPath(nickname) + "/profile"}, user)
+	if err != nil {
+		return nil, err
+	}
+	user.Version = version(response)
+	return user, nil
+}
+
+// UpdateUser updates the profile of user.Nickname, conditioned on its Version
+// like UpdateThread. Empty fields are left unchanged.
+func (client *Client) UpdateUser(user *models.User) error {
+	update := &models.UserUpdate{Fullname: user.Fullname, About: user.About, Email: user.Email}
+	response, err := client.send(request{method: http.MethodPost, route: pkg.UserRoute, path: userPath(user.Nickname) + "/profile", body: update, header: ifMatch(user.Version)}, user)
+	if err != nil {
+		return err
+	}
+	user.Version = version(response)
+	return nil
+}
+
+// RenameUser changes the nickname of user.Nickname to nickname, conditioned
+// on its Version like UpdateUser, and fills user with the renamed profile.
+// For a while GetUser with the old nickname still finds the user.
+func (client *Client) RenameUser(user *models.User, nickname string) error {
+	rename := &models.UserRename{Nickname: nickname}
+	response, err := client.send(request{method: http.MethodPost, route: pkg.UserRoute, path: userPath(user.Nickname) + "/rename", body: rename, header: ifMatch(user.Version)}, user)
+	if err != nil {
+		return err
+	}
+	user.Version = version(response)
+	return nil
+}
+
+// ExportUser returns the zip archive with everything the user authored.
+func (client *Client) ExportUser(nickname string) ([]byte, error) {
+	response, err := client.send(request{method: http.MethodGet, route: pkg.UserRoute, path: userPath(nickname) + "/export"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.body, nil
+}
+
+// DeleteUser deletes the user, their forums, threads and posts are handed
+// over to the tombstone account models.DeletedUser.
+func (client *Client) DeleteUser(nickname string) error {
+	_, err := client.send(request{method: http.MethodDelete, route: pkg.UserRoute, path: userPath(nickname) + "/profile"}, nil)
+	return err
+}
+
+// UserPosts iterates over the posts of the user by id, Since being a post id.
+// Sort is not used.
+func (client *Client) UserPosts(nickname string, options PostListOptions) *PostIterator {
+	iterator := new(PostIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	iterator.fetch = func() (int, bool, error) {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "desc": {strconv.FormatBool(options.Desc)}}
+		if since > 0 {
+			query.Set("since", strconv.FormatInt(since, 10))
+		}
+		iterator.posts = nil
+		_, err := client.send(request{method: http.MethodGet, route: pkg.UserRoute, path: userPath(nickname) + "/posts", query: query}, &iterator.posts)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(iterator.posts) > 0 {
+			since = iterator.posts[len(iterator.posts)-1].Id
+		}
+		return len(iterator.posts), len(iterator.posts) == limit, nil
+	}
+	return iterator
+}
+
+// UserThreads iterates over the threads of the user ordered by creation time,
+// like ForumThreads.
+func (client *Client) UserThreads(nickname string, options ListOptions) *ThreadIterator {
+	return client.threads(pkg.UserRoute, userPath(nickname)+"/threads", options)
+}
+
+// UserForums iterates over the forums the user took part in by slug, with the
+// number of their posts in each.
+func (client *Client) UserForums(nickname string, options ListOptions) *UserForumIterator {
+	iterator := new(UserForumIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	iterator.fetch = func() (int, bool, error) {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "desc": {strconv.FormatBool(options.Desc)}}
+		if since != "" {
+			query.Set("since", since)
+		}
+		iterator.forums = nil
+		_, err := client.send(request{method: http.MethodGet, route: pkg.UserRoute, path: userPath(nickname) + "/forums", query: query}, &iterator.forums)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(iterator.forums) > 0 {
+			since = iterator.forums[len(iterator.forums)-1].Slug
+		}
+		return len(iterator.forums), len(iterator.forums) == limit, nil
+	}
+	return iterator
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"db_forum/app/models"
+	"db_forum/pkg"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DeliveryListOptions page the deliveries of a webhook. Since is the id of a
+// delivery to start after.
+type DeliveryListOptions struct {
+	Since    int64
+	Desc     bool
+	PageSize int
+}
+
+func webhookPath(forum string) string {
+	return "/" + url.PathEscape(forum) + "/webhooks"
+}
+
+// CreateWebhook registers the webhook on the forum. The response, including
+// the signing secret, is only returned once and is filled into webhook.
+func (client *Client) CreateWebhook(forum string, webhook *models.Webhook) error {
+	_, err := client.send(request{method: http.MethodPost, route: pkg.ForumRoute, path: webhookPath(forum), body: webhook, create: true}, webhook)
+	return err
+}
+
+func (client *Client) GetWebhooks(forum string) (models.Webhooks, error) {
+	var webhooks models.Webhooks
+	_, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: webhookPath(forum)}, &webhooks)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (client *Client) DeleteWebhook(forum string, id int64) error {
+	_, err := client.send(request{method: http.MethodDelete, route: pkg.ForumRoute, path: webhookPath(forum) + "/" + strconv.FormatInt(id, 10)}, nil)
+	return err
+}
+
+func (client *Client) WebhookDeliveries(forum string, id int64, options DeliveryListOptions) *DeliveryIterator {
+	iterator := new(DeliveryIterator)
+	limit := pageSize(options.PageSize)
+	since := options.Since
+	iterator.fetch = func() (int, bool, error) {
+		query := url.Values{"limit": {strconv.Itoa(limit)}, "desc": {strconv.FormatBool(options.Desc)}}
+		if since > 0 {
+			query.Set("since", strconv.FormatInt(since, 10))
+		}
+		iterator.deliveries = nil
+		_, err := client.send(request{method: http.MethodGet, route: pkg.ForumRoute, path: webhookPath(forum) + "/" + strconv.FormatInt(id, 10) + "/deliveries", query: query}, &iterator.deliveries)
+		if err != nil {
+			return 0, false, err
+		}
+		if len(iterator.deliveries) > 0 {
+			since = iterator.deliveries[len(iterator.deliveries)-1].Id
+		}
+		return len(iterator.deliveries), len(iterator.deliveries) == limit, nil
+	}
+	return iterator
+}
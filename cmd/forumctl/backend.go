@@ -0,0 +1,106 @@
+package main
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/repositories"
+	"db_forum/app/usecases"
+	"db_forum/client"
+	"db_forum/pkg/validation"
+
+	"github.com/jackc/pgx"
+)
+
+// backend is what forumctl can do over the API as well as on the database.
+type backend interface {
+	Status() (*models.Status, error)
+	Reconcile(repair bool) (*models.Reconciliation, error)
+	CreateUser(user *models.User) error
+	GetUser(nickname string) (*models.User, error)
+	CreateForum(forum *models.Forum) error
+	GetForum(slug string) (*models.Forum, error)
+	CreateThread(thread *models.Thread) error
+	GetThread(slugOrId string) (*models.Thread, error)
+}
+
+type apiBackend struct {
+	*client.Client
+}
+
+func (api apiBackend) CreateUser(user *models.User) error {
+	_, err := api.Client.CreateUser(user)
+	return err
+}
+
+func (api apiBackend) CreateThread(thread *models.Thread) error {
+	return api.Client.CreateThread(thread.Forum, thread)
+}
+
+// dbBackend runs the same usecases as the server, so the database sees the
+// same writes as through the API.
+type dbBackend struct {
+	pool    *pgx.ConnPool
+	admin   repositories.AdminRepository
+	forums  usecases.ForumUsecase
+	service usecases.ServiceUsecase
+	threads usecases.ThreadUsecase
+	users   usecases.UserUsecase
+}
+
+func makeDBBackend(pool *pgx.ConnPool) *dbBackend {
+	forumRepository := repositories.MakeForumRepository(pool)
+	postRepository := repositories.MakePostRepository(pool)
+	threadRepository := repositories.MakeThreadRepository(pool)
+	userRepository := repositories.MakeUserRepository(pool)
+	voteRepository := repositories.MakeVoteRepository(pool)
+
+	return &dbBackend{
+		pool:    pool,
+		admin:   repositories.MakeAdminRepository(pool),
+		forums:  usecases.MakeForumUseCase(forumRepository, threadRepository, userRepository),
+		service: usecases.MakeServiceUseCase(repositories.MakeServiceRepository(pool)),
+		threads: usecases.MakeThreadUseCase(voteRepository, threadRepository, userRepository, postRepository),
+		users:   usecases.MakeUserUseCase(userRepository),
+	}
+}
+
+func (database *dbBackend) Status() (*models.Status, error) {
+	return database.service.GetService()
+}
+
+func (database *dbBackend) Reconcile(repair bool) (*models.Reconciliation, error) {
+	return database.service.Reconcile(repair)
+}
+
+func (database *dbBackend) CreateUser(user *models.User) error {
+	if err := validation.Struct(user); err != nil {
+		return err
+	}
+	_, err := database.users.CreateNewUser(user)
+	return err
+}
+
+func (database *dbBackend) GetUser(nickname string) (*models.User, error) {
+	return database.users.GetInfoAboutUser(nickname)
+}
+
+func (database *dbBackend) CreateForum(forum *models.Forum) error {
+	if err := validation.Struct(forum); err != nil {
+		return err
+	}
+	return database.forums.CreateForum(forum)
+}
+
+func (database *dbBackend) GetForum(slug string) (*models.Forum, error) {
+	return database.forums.GetInfoAboutForum(slug)
+}
+
+func (database *dbBackend) CreateThread(thread *models.Thread) error {
+	if err := validation.Struct(thread); err != nil {
+		return err
+	}
+	return database.forums.CreateForumsThread(thread)
+}
+
+func (database *dbBackend) GetThread(slugOrId string) (*models.Thread, error) {
+	return database.threads.GetInfoAboutThread(slugOrId)
+}
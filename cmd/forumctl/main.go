@@ -0,0 +1,370 @@
+// Command forumctl administers a forum, over its API or directly on its
+// postgres database.
+//
+//	forumctl [-api URL | -db DSN] command [arguments]
+//
+// Users, forums and threads can be created and inspected both ways, as well
+// as the counters recounted. Deleting them, merging users, export, import and
+// migrations need -db.
+package main
+
+import (
+	"db_forum/app/models"
+	"db_forum/app/transfer"
+	"db_forum/client"
+	"db_forum/pkg"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/mailru/easyjson"
+)
+
+const defaultDSN = "host=127.0.0.1 user=forum password=forum dbname=forum port=5432 sslmode=disable"
+
+const usage = `usage: forumctl [-api URL | -db DSN] command [arguments]
+
+commands:
+  status                        counts of users, forums, threads and posts
+  user create -nickname N -email E [-fullname F] [-about A]
+  user get NICKNAME
+  user delete NICKNAME          (-db) only users without forums, threads and posts
+  user merge [-dry-run] SOURCE TARGET
+                                (-db) move everything of SOURCE to TARGET and delete SOURCE, the
+                                vote of TARGET wins where both voted; -dry-run only counts the rows
+  forum create -slug S -title T -user U
+  forum get SLUG
+  forum delete SLUG             (-db) with its threads, posts, votes and webhooks
+  thread create -forum F -title T -author A -message M [-slug S]
+  thread get SLUG_OR_ID
+  thread delete SLUG_OR_ID      (-db) with its posts and votes
+  recount [-dry-run]            check the post, thread, vote and participant counters and repair them
+  export [-o FILE] [-forum S]   (-db) write the database or a forum as newline delimited JSON
+  import [-i FILE] [-name N] [-remap] [-batch N]
+                                (-db) load an export with COPY; rerun with the same -name to
+                                resume, -remap gives threads and posts new ids
+  migrate                       (-db) create or update the schema
+`
+
+func main() {
+	api := flag.String("api", "", "base URL of the forum, e.g. http://127.0.0.1:5000")
+	dsn := flag.String("db", defaultDSN, "postgres connection string, used when -api is not set")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctl := &forumctl{}
+	if *api != "" {
+		ctl.backend = apiBackend{client.MakeClient(*api)}
+	} else {
+		conn, err := pgx.ParseConnectionString(*dsn)
+		if err != nil {
+			fail(err)
+		}
+		pool, err := pgx.NewConnPool(pgx.ConnPoolConfig{ConnConfig: conn, MaxConnections: 4})
+		if err != nil {
+			fail(err)
+		}
+		defer pool.Close()
+
+		ctl.database = makeDBBackend(pool)
+		ctl.backend = ctl.database
+	}
+
+	if err := ctl.run(flag.Arg(0), flag.Args()[1:]); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "forumctl:", err)
+	os.Exit(1)
+}
+
+type forumctl struct {
+	backend backend
+	// database is nil when forumctl talks to the API
+	database *dbBackend
+}
+
+func (ctl *forumctl) run(command string, args []string) error {
+	switch command {
+	case "status":
+		status, err := ctl.backend.Status()
+		if err != nil {
+			return err
+		}
+		return show(status)
+	case "user", "forum", "thread":
+		if len(args) == 0 {
+			return fmt.Errorf("%s: missing action: create, get or delete", command)
+		}
+		return ctl.entity(command, args[0], args[1:])
+	case "recount":
+		return ctl.recount(args)
+	case "export":
+		return ctl.export(args)
+	case "import":
+		return ctl.load(args)
+	case "migrate":
+		if ctl.database == nil {
+			return errNeedsDB(command)
+		}
+		return ctl.database.admin.Migrate()
+	}
+	return fmt.Errorf("unknown command %q, run forumctl -h for the list", command)
+}
+
+func (ctl *forumctl) entity(kind, action string, args []string) error {
+	if action == "create" {
+		return ctl.create(kind, args)
+	}
+	if kind == "user" && action == "merge" {
+		return ctl.merge(args)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("%s %s takes exactly one argument", kind, action)
+	}
+	key := args[0]
+
+	switch action {
+	case "get":
+		var entity easyjson.Marshaler
+		var err error
+		switch kind {
+		case "user":
+			entity, err = ctl.backend.GetUser(key)
+		case "forum":
+			entity, err = ctl.backend.GetForum(key)
+		case "thread":
+			entity, err = ctl.backend.GetThread(key)
+		}
+		if err != nil {
+			return explain(err, kind, key)
+		}
+		return show(entity)
+	case "delete":
+		if ctl.database == nil {
+			return errNeedsDB(kind + " delete")
+		}
+		var err error
+		switch kind {
+		case "user":
+			err = ctl.database.admin.DeleteUser(key)
+		case "forum":
+			err = ctl.database.admin.DeleteForum(key)
+		case "thread":
+			var thread *models.Thread
+			if thread, err = ctl.backend.GetThread(key); err == nil {
+				err = ctl.database.admin.DeleteThread(thread.Id)
+			}
+		}
+		return explain(err, kind, key)
+	}
+	return fmt.Errorf("%s: unknown action %q", kind, action)
+}
+
+func (ctl *forumctl) create(kind string, args []string) error {
+	flags := flag.NewFlagSet(kind+" create", flag.ExitOnError)
+	var entity easyjson.Marshaler
+	var create func() error
+	var key *string
+
+	switch kind {
+	case "user":
+		user := new(models.User)
+		flags.StringVar(&user.Nickname, "nickname", "", "nickname")
+		flags.StringVar(&user.Email, "email", "", "email")
+		flags.StringVar(&user.Fullname, "fullname", "", "full name")
+		flags.StringVar(&user.About, "about", "", "about")
+		entity, key = user, &user.Nickname
+		create = func() error { return ctl.backend.CreateUser(user) }
+	case "forum":
+		forum := new(models.Forum)
+		flags.StringVar(&forum.Slug, "slug", "", "slug")
+		flags.StringVar(&forum.Title, "title", "", "title")
+		flags.StringVar(&forum.User, "user", "", "nickname of the owner")
+		entity, key = forum, &forum.Slug
+		create = func() error { return ctl.backend.CreateForum(forum) }
+	case "thread":
+		thread := &models.Thread{Created: time.Now()}
+		flags.StringVar(&thread.Forum, "forum", "", "slug of the forum")
+		flags.StringVar(&thread.Title, "title", "", "title")
+		flags.StringVar(&thread.Author, "author", "", "nickname of the author")
+		flags.StringVar(&thread.Message, "message", "", "message")
+		flags.StringVar(&thread.Slug, "slug", "", "slug")
+		entity, key = thread, &thread.Slug
+		create = func() error { return ctl.backend.CreateThread(thread) }
+	}
+	_ = flags.Parse(args)
+
+	if err := create(); err != nil {
+		return explain(err, kind, *key)
+	}
+	return show(entity)
+}
+
+func (ctl *forumctl) merge(args []string) error {
+	flags := flag.NewFlagSet("user merge", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the rows that would move")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		return fmt.Errorf("user merge takes a source and a target nickname")
+	}
+	if ctl.database == nil {
+		return errNeedsDB("user merge")
+	}
+
+	var users [2]*models.User
+	for i, nickname := range flags.Args() {
+		user, err := ctl.backend.GetUser(nickname)
+		if err != nil {
+			return explain(err, "user", nickname)
+		}
+		users[i] = user
+	}
+	source, target := users[0].Nickname, users[1].Nickname
+	switch {
+	case strings.EqualFold(source, target):
+		return fmt.Errorf("user %q can't be merged into itself", source)
+	case strings.EqualFold(source, models.DeletedUser.Nickname):
+		return pkg.ErrUserIsTombstone
+	}
+
+	merge, err := ctl.database.admin.MergeUsers(source, target, *dryRun)
+	if err != nil {
+		return explain(err, "user", source)
+	}
+	return show(merge)
+}
+
+func (ctl *forumctl) recount(args []string) error {
+	flags := flag.NewFlagSet("recount", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only report the counters that are off")
+	_ = flags.Parse(args)
+
+	reconciliation, err := ctl.backend.Reconcile(!*dryRun)
+	if err != nil {
+		return err
+	}
+	return show(reconciliation)
+}
+
+func (ctl *forumctl) export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "file to write, standard output if empty")
+	forum := flags.String("forum", "", "export only this forum")
+	_ = flags.Parse(args)
+	if ctl.database == nil {
+		return errNeedsDB("export")
+	}
+	if *forum != "" {
+		if _, err := ctl.backend.GetForum(*forum); err != nil {
+			return explain(err, "forum", *forum)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	_, err := transfer.Export(ctl.database.pool, w, transfer.ExportOptions{Forum: *forum, Progress: printProgress("exported")})
+	return err
+}
+
+func (ctl *forumctl) load(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	input := flags.String("i", "", "file to read, standard input if empty")
+	name := flags.String("name", "", "name of the import, rerun a failed import with the same name to resume it (default: the file name)")
+	remap := flags.Bool("remap", false, "give threads and posts new ids")
+	batchSize := flags.Int("batch", 5000, "records committed at once")
+	_ = flags.Parse(args)
+	if ctl.database == nil {
+		return errNeedsDB("import")
+	}
+
+	r := io.Reader(os.Stdin)
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+		if *name == "" {
+			*name = filepath.Base(*input)
+		}
+	}
+	if *name == "" {
+		return errors.New("import: -name is needed to read from standard input")
+	}
+
+	options := transfer.ImportOptions{Name: *name, Remap: *remap, BatchSize: *batchSize, Progress: printProgress("imported")}
+	_, err := transfer.Import(ctl.database.pool, r, options)
+	return err
+}
+
+func printProgress(done string) func(transfer.Progress) {
+	return func(progress transfer.Progress) {
+		fmt.Fprintf(os.Stderr, "%s %d records: %d users, %d forums, %d threads, %d posts, %d votes\n",
+			done, progress.Records, progress.Users, progress.Forums, progress.Threads, progress.Posts, progress.Votes)
+	}
+}
+
+func show(entity easyjson.Marshaler) error {
+	output, err := easyjson.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(output))
+	return err
+}
+
+func errNeedsDB(command string) error {
+	return fmt.Errorf("%s works on the database only, run it without -api", command)
+}
+
+// explain turns the errors of both backends into messages about the entity:
+// the API and the usecases report most errors as "Can't find user with id ",
+// only the status code tells them apart.
+func explain(err error, kind, key string) error {
+	if err == nil {
+		return nil
+	}
+	code := pkg.ConvertErrorToCode(err)
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) {
+		code = apiErr.StatusCode
+	}
+	if err == pgx.ErrNoRows {
+		code = http.StatusNotFound
+	}
+
+	name := kind + " " + strconv.Quote(key)
+	switch {
+	case err == pkg.ErrUserHasContent:
+		return fmt.Errorf("%s: %w", name, err)
+	case code == http.StatusNotFound:
+		return fmt.Errorf("%s or what it refers to was not found", name)
+	case code == http.StatusConflict:
+		return fmt.Errorf("%s already exists", name)
+	}
+	return err
+}
@@ -0,0 +1,10 @@
+package db
+
+import _ "embed"
+
+// Schema is the postgres schema. It can be applied to an existing database
+// again: tables and indexes are created if missing, functions and triggers
+// are replaced.
+//
+//go:embed db.sql
+var Schema string
@@ -5,7 +5,9 @@ create unlogged table if not exists users
     nickname citext collate "C" not null primary key,
     fullname citext             not null,
     about    text,
-    email    citext             not null unique
+    email    citext             not null unique,
+    reputation int                       default 0,
+    version  int                         default 1
 );
 
 create unlogged table if not exists forums
@@ -14,7 +16,8 @@ create unlogged table if not exists forums
     user_   citext not null references users (nickname) on update cascade on delete cascade,
     slug    citext not null primary key,
     posts   int default 0,
-    threads int default 0
+    threads int default 0,
+    version int default 1
 );
 
 create unlogged table if not exists threads
@@ -26,57 +29,171 @@ create unlogged table if not exists threads
     message text   not null,
     votes   int                      default 0,
     slug    citext,
-    created timestamp with time zone default now()
+    created timestamp with time zone default now(),
+    version int                      default 1
 );
 
 create unlogged table if not exists posts
 (
     id        bigserial not null primary key,
     parent    int references posts (id),
-    author    citext not null references users (nickname),
+    author    citext not null references users (nickname) on update cascade,
     message   text   not null,
     is_edited bool                     default false,
     forum     citext not null references forums (slug),
     thread    int    not null references threads (id),
     created   timestamp with time zone default now(),
-    path      bigint[]                 default array []::integer[]
+    path      bigint[]                 default array []::integer[],
+    score     int                      default 0,
+    voters    int                      default 0,
+    version   int                      default 1
 );
 
+-- версии для ETag и If-Match появились позже: добавляем колонки в уже созданные таблицы
+alter table users add column if not exists version int default 1;
+alter table forums add column if not exists version int default 1;
+alter table threads add column if not exists version int default 1;
+alter table posts add column if not exists version int default 1;
+
+-- рейтинг сообщений появился позже: добавляем колонки в уже созданную таблицу
+alter table posts add column if not exists score int default 0;
+alter table posts add column if not exists voters int default 0;
+
 create unlogged table if not exists votes
 (
-    nickname citext not null references users (nickname),
+    nickname citext not null references users (nickname) on update cascade,
     thread   int    not null references threads (id),
     voice    int    not null,
     constraint user_thread_key unique (nickname, thread)
 );
 
+create unlogged table if not exists post_votes
+(
+    nickname citext not null references users (nickname) on update cascade,
+    post     bigint not null references posts (id),
+    voice    int    not null,
+    constraint user_post_key unique (post, nickname)
+);
+
 create unlogged table if not exists user_forum
 (
-    nickname citext collate "C" not null references users (nickname),
-    forum    citext             not null references forums (slug),
+    nickname   citext collate "C" not null references users (nickname) on update cascade,
+    forum      citext             not null references forums (slug),
+    reputation int                         default 0,
     constraint user_forum_key unique (nickname, forum)
 );
 
+-- репутация: сумма голосов за ветки и сообщения пользователя, всего и по форумам
+alter table users add column if not exists reputation int default 0;
+alter table user_forum add column if not exists reputation int default 0;
+
+-- активность участника форума: его ветки и сообщения в форуме, первое и последнее из них
+alter table user_forum add column if not exists threads int default 0;
+alter table user_forum add column if not exists posts int default 0;
+alter table user_forum add column if not exists joined timestamp with time zone;
+alter table user_forum add column if not exists last_active timestamp with time zone;
+
+do
+$$
+begin
+    if exists(select 1 from user_forum where joined is null) then
+        update user_forum uf
+        set threads     = a.threads,
+            posts       = a.posts,
+            joined      = a.joined,
+            last_active = a.last_active
+        from (select author, forum, count(*) filter (where thread) as threads, count(*) filter (where not thread) as posts,
+                     min(created) as joined, max(created) as last_active
+              from (select author, forum, created, true as thread from threads
+                    union all
+                    select author, forum, created, false from posts) as activity
+              group by author, forum) as a
+        where uf.nickname = a.author
+          and uf.forum = a.forum;
+    end if;
+end;
+$$;
+
+-- переименование пользователя: все ссылки на users.nickname обновляются каскадом,
+-- в уже созданных таблицах внешние ключи пересоздаются
+do
+$$
+declare
+    key_ record;
+begin
+    for key_ in select c.conrelid::regclass as table_, c.conname, a.attname
+                from pg_constraint c
+                         join pg_attribute a on a.attrelid = c.conrelid and a.attnum = c.conkey[1]
+                where c.contype = 'f'
+                  and c.confrelid = 'users'::regclass
+                  and c.confupdtype <> 'c'
+        loop
+            execute format('alter table %s drop constraint %I, add constraint %I foreign key (%I) references users (nickname) on update cascade',
+                           key_.table_, key_.conname, key_.conname, key_.attname);
+        end loop;
+end;
+$$;
+
+-- прежние nickname переименованных пользователей: профиль по ним ещё какое-то время перенаправляется
+create unlogged table if not exists user_redirects
+(
+    nickname citext collate "C" not null primary key,
+    target   citext collate "C" not null references users (nickname) on update cascade on delete cascade,
+    created  timestamp with time zone default now()
+);
+
+-- активность форумов по часам (UTC): новые ветки, сообщения и голоса и кто их оставил,
+-- сутки собираются из часов при запросе
+create unlogged table if not exists forum_stats
+(
+    forum   citext                   not null references forums (slug),
+    hour    timestamp with time zone not null,
+    threads int default 0,
+    posts   int default 0,
+    votes   int default 0,
+    constraint forum_stats_key primary key (forum, hour)
+);
+
+create unlogged table if not exists forum_stats_users
+(
+    forum    citext                   not null references forums (slug),
+    hour     timestamp with time zone not null,
+    nickname citext collate "C"       not null references users (nickname) on update cascade,
+    constraint forum_stats_users_key primary key (forum, hour, nickname)
+);
+
 -- Триггеры и процедуры
+-- схема применяется повторно (Dockerfile, forumctl migrate), поэтому триггеры пересоздаются
+-- при загрузке пачкой (forum.bulk_posts = on) пути, счётчики, user_forum, репутацию и статистику считает приложение,
+-- при импорте (forum.import = on) ещё и не пишутся события; внешние ключи проверяются всегда
 create or replace function create_user()
     returns trigger as
 $$
 begin
-    insert into user_forum (nickname, forum) values (new.author, new.forum) on conflict do nothing;
+    insert into user_forum (nickname, forum, threads, posts, joined, last_active)
+    values (new.author, new.forum, (tg_table_name = 'threads')::int, (tg_table_name = 'posts')::int, new.created, new.created)
+    on conflict (nickname, forum) do update set threads     = user_forum.threads + excluded.threads,
+                                                posts       = user_forum.posts + excluded.posts,
+                                                joined      = least(user_forum.joined, excluded.joined),
+                                                last_active = greatest(user_forum.last_active, excluded.last_active);
     return new;
 end;
 $$ language plpgsql;
 
+drop trigger if exists create_new_thread on threads;
 create trigger create_new_thread
     after insert
     on threads
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_user();
 
+drop trigger if exists create_new_post on posts;
 create trigger create_new_post
     after insert
     ON posts
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_user();
 
 create or replace function create_post_before()
@@ -88,40 +205,46 @@ begin
 end;
 $$ language plpgsql;
 
+drop trigger if exists create_post_before on posts;
 create trigger create_post_before
     before insert
     on posts
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_post_before();
 
 create or replace function create_post_after()
     returns trigger as
 $$
 begin
-    update forums set posts = forums.posts + 1 where slug = new.forum;
+    update forums set posts = forums.posts + 1, version = forums.version + 1 where slug = new.forum;
     return new;
 end;
 $$ language plpgsql;
 
+drop trigger if exists create_post_after on posts;
 create trigger create_post_after
     after insert
     on posts
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_post_after();
 
 create or replace function create_votes()
     returns trigger as
 $$
 begin
-    update threads set votes = votes + new.voice where id = new.thread;
+    update threads set votes = votes + new.voice, version = version + 1 where id = new.thread;
     return new;
 end;
 $$ language plpgsql;
 
+drop trigger if exists create_votes on votes;
 create trigger create_votes
     after insert
     on votes
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_votes();
 
 
@@ -129,35 +252,527 @@ create or replace function update_votes()
     returns trigger as
 $$
 begin
-    update threads set votes = votes - old.voice + new.voice where id = new.thread;
+    update threads set votes = votes - old.voice + new.voice, version = version + 1 where id = new.thread;
     return NULL;
 end;
 $$ language plpgsql;
 
+-- переименование голосующего меняет только nickname
+drop trigger if exists update_votes on votes;
 create trigger update_votes
     after update
     on votes
     for each row
+    when (old.voice is distinct from new.voice)
 execute procedure update_votes();
 
+-- score - сумма голосов за сообщение, voters - число проголосовавших
+create or replace function create_post_vote()
+    returns trigger as
+$$
+begin
+    update posts set score = score + new.voice, voters = voters + 1, version = version + 1 where id = new.post;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists create_post_vote on post_votes;
+create trigger create_post_vote
+    after insert
+    on post_votes
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure create_post_vote();
+
+create or replace function update_post_vote()
+    returns trigger as
+$$
+begin
+    update posts set score = score - old.voice + new.voice, version = version + 1 where id = new.post;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists update_post_vote on post_votes;
+create trigger update_post_vote
+    after update
+    on post_votes
+    for each row
+    when (old.voice is distinct from new.voice)
+execute procedure update_post_vote();
+
+create or replace function delete_votes()
+    returns trigger as
+$$
+begin
+    update threads set votes = votes - old.voice, version = version + 1 where id = old.thread;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists delete_votes on votes;
+create trigger delete_votes
+    after delete
+    on votes
+    for each row
+execute procedure delete_votes();
+
+-- в отличие от votes, post_votes удаляются только при удалении пользователя, ветки или форума
+create or replace function delete_post_vote()
+    returns trigger as
+$$
+begin
+    update posts set score = score - old.voice, voters = voters - 1, version = version + 1 where id = old.post;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists delete_post_vote on post_votes;
+create trigger delete_post_vote
+    after delete
+    on post_votes
+    for each row
+execute procedure delete_post_vote();
+
+-- Репутация автора ветки или сообщения меняется на разницу голосов
+create or replace function add_reputation(nickname_ citext, forum_ citext, delta_ int)
+    returns void as
+$$
+begin
+    if delta_ = 0 then
+        return;
+    end if;
+    update users set reputation = reputation + delta_, version = version + 1 where nickname = nickname_;
+    update user_forum set reputation = reputation + delta_ where nickname = nickname_ and forum = forum_;
+end;
+$$ language plpgsql;
+
+create or replace function vote_reputation()
+    returns trigger as
+$$
+declare
+    thread_ threads;
+begin
+    if tg_op = 'DELETE' then
+        select * into thread_ from threads where id = old.thread;
+        perform add_reputation(thread_.author, thread_.forum, -old.voice);
+    elsif tg_op = 'UPDATE' then
+        select * into thread_ from threads where id = new.thread;
+        perform add_reputation(thread_.author, thread_.forum, new.voice - old.voice);
+    else
+        select * into thread_ from threads where id = new.thread;
+        perform add_reputation(thread_.author, thread_.forum, new.voice);
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists vote_reputation on votes;
+create trigger vote_reputation
+    after insert or update or delete
+    on votes
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure vote_reputation();
+
+create or replace function post_vote_reputation()
+    returns trigger as
+$$
+declare
+    post_ posts;
+begin
+    if tg_op = 'DELETE' then
+        select * into post_ from posts where id = old.post;
+        perform add_reputation(post_.author, post_.forum, -old.voice);
+    elsif tg_op = 'UPDATE' then
+        select * into post_ from posts where id = new.post;
+        perform add_reputation(post_.author, post_.forum, new.voice - old.voice);
+    else
+        select * into post_ from posts where id = new.post;
+        perform add_reputation(post_.author, post_.forum, new.voice);
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists post_vote_reputation on post_votes;
+create trigger post_vote_reputation
+    after insert or update or delete
+    on post_votes
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure post_vote_reputation();
+
+create or replace function count_activity(forum_ citext, nickname_ citext, created_ timestamp with time zone,
+                                          threads_ int, posts_ int, votes_ int)
+    returns void as
+$$
+begin
+    insert into forum_stats (forum, hour, threads, posts, votes)
+    values (forum_, date_trunc('hour', created_, 'UTC'), threads_, posts_, votes_)
+    on conflict (forum, hour) do update set threads = forum_stats.threads + excluded.threads,
+                                            posts   = forum_stats.posts + excluded.posts,
+                                            votes   = forum_stats.votes + excluded.votes;
+    insert into forum_stats_users (forum, hour, nickname)
+    values (forum_, date_trunc('hour', created_, 'UTC'), nickname_)
+    on conflict do nothing;
+end;
+$$ language plpgsql;
+
+create or replace function count_content()
+    returns trigger as
+$$
+begin
+    perform count_activity(new.forum, new.author, new.created, (tg_table_name = 'threads')::int, (tg_table_name = 'posts')::int, 0);
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists count_thread on threads;
+create trigger count_thread
+    after insert
+    on threads
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure count_content();
+
+drop trigger if exists count_post on posts;
+create trigger count_post
+    after insert
+    on posts
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure count_content();
+
+-- голос считается, когда его отдают или меняют, по времени голосования: у голосов нет created
+create or replace function count_vote()
+    returns trigger as
+$$
+begin
+    if tg_op = 'UPDATE' and old.voice = new.voice then
+        return null;
+    end if;
+    if tg_table_name = 'votes' then
+        perform count_activity(forum, new.nickname, now(), 0, 0, 1) from threads where id = new.thread;
+    else
+        perform count_activity(forum, new.nickname, now(), 0, 0, 1) from posts where id = new.post;
+    end if;
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists count_vote on votes;
+create trigger count_vote
+    after insert or update of voice
+    on votes
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure count_vote();
+
+drop trigger if exists count_post_vote on post_votes;
+create trigger count_post_vote
+    after insert or update of voice
+    on post_votes
+    for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
+execute procedure count_vote();
+
+-- первый запуск со статистикой: ветки и сообщения по их created, прошлые голоса восстановить не из чего
+do
+$$
+begin
+    if not exists(select 1 from forum_stats) then
+        insert into forum_stats (forum, hour, threads, posts)
+        select forum, date_trunc('hour', created, 'UTC'), count(*) filter (where thread), count(*) filter (where not thread)
+        from (select forum, created, true as thread from threads
+              union all
+              select forum, created, false from posts) as activity
+        group by 1, 2;
+        insert into forum_stats_users (forum, hour, nickname)
+        select forum, date_trunc('hour', created, 'UTC'), author from threads
+        union
+        select forum, date_trunc('hour', created, 'UTC'), author from posts;
+    end if;
+end;
+$$;
+
 create or replace function create_thread()
     returns trigger as
 $$
 begin
-    update forums set threads = forums.threads + 1 where slug = new.forum;
+    update forums set threads = forums.threads + 1, version = forums.version + 1 where slug = new.forum;
     RETURN new;
 end;
 $$ language plpgsql;
 
+drop trigger if exists create_thread on threads;
 create trigger create_thread
     after insert
     on threads
     for each row
+    when (current_setting('forum.bulk_posts', true) is distinct from 'on')
 execute procedure create_thread();
 
+-- События треда для подписчиков (LISTEN thread_events)
+create or replace function notify_post_event()
+    returns trigger as
+$$
+begin
+    perform pg_notify('thread_events',
+                      json_build_object('type', case when tg_op = 'INSERT' then 'post' else 'edit' end,
+                                        'thread', new.thread, 'id', new.id)::text);
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists notify_post_created on posts;
+create trigger notify_post_created
+    after insert
+    on posts
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure notify_post_event();
+
+drop trigger if exists notify_post_edited on posts;
+create trigger notify_post_edited
+    after update of message
+    on posts
+    for each row
+    when (old.message is distinct from new.message)
+execute procedure notify_post_event();
+
+create or replace function notify_thread_votes()
+    returns trigger as
+$$
+begin
+    perform pg_notify('thread_events',
+                      json_build_object('type', 'vote', 'thread', new.id, 'votes', new.votes)::text);
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists notify_thread_votes on threads;
+create trigger notify_thread_votes
+    after update of votes
+    on threads
+    for each row
+    when (old.votes is distinct from new.votes and current_setting('forum.import', true) is distinct from 'on')
+execute procedure notify_thread_votes();
+
+-- Вебхуки: очередь доставки должна переживать рестарт, поэтому таблицы обычные, не unlogged
+-- (и без внешнего ключа на unlogged forums)
+create table if not exists webhooks
+(
+    id      serial not null primary key,
+    forum   citext not null,
+    url     text   not null,
+    secret  text   not null,
+    events  text[] not null,
+    created timestamp with time zone default now()
+);
+
+create table if not exists webhook_deliveries
+(
+    id            bigserial not null primary key,
+    webhook       int       not null references webhooks (id) on delete cascade,
+    outbox        bigint,
+    event         text      not null,
+    payload       text      not null,
+    status        text      not null     default 'pending',
+    attempts      int       not null     default 0,
+    response_code int,
+    last_error    text,
+    created       timestamp with time zone default now(),
+    next_attempt  timestamp with time zone default now(),
+    delivered     timestamp with time zone
+);
+
+-- Outbox доменных событий: пишется триггерами в той же транзакции, что и изменение
+create table if not exists outbox
+(
+    id           bigserial not null primary key,
+    type         text      not null,
+    forum        citext,
+    payload      text      not null,
+    attempts     int       not null default 0,
+    last_error   text,
+    created      timestamp with time zone default now(),
+    next_attempt timestamp with time zone default now(),
+    published    timestamp with time zone
+);
+
+create or replace function outbox_event(type_ text, forum_ citext, payload_ json)
+    returns void as
+$$
+begin
+    insert into outbox (type, forum, payload) values (type_, forum_, payload_::text);
+end;
+$$ language plpgsql;
+
+create or replace function thread_json(thread_ threads)
+    returns json as
+$$
+select json_build_object('id', thread_.id, 'title', thread_.title, 'author', thread_.author,
+                         'forum', thread_.forum, 'message', thread_.message, 'votes', thread_.votes,
+                         'slug', thread_.slug, 'created', thread_.created);
+$$ language sql;
+
+create or replace function post_json(post_ posts)
+    returns json as
+$$
+select json_build_object('id', post_.id, 'parent', coalesce(post_.parent, 0), 'author', post_.author,
+                         'message', post_.message, 'isEdited', post_.is_edited, 'forum', post_.forum,
+                         'thread', post_.thread, 'created', post_.created);
+$$ language sql;
+
+create or replace function user_json(user_ users)
+    returns json as
+$$
+select json_build_object('nickname', user_.nickname, 'fullname', user_.fullname, 'about', user_.about,
+                         'email', user_.email);
+$$ language sql;
+
+create or replace function outbox_forum()
+    returns trigger as
+$$
+begin
+    perform outbox_event('forum.created', new.slug,
+                         json_build_object('title', new.title, 'user', new.user_, 'slug', new.slug));
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists outbox_forum on forums;
+create trigger outbox_forum
+    after insert
+    on forums
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure outbox_forum();
+
+create or replace function outbox_thread()
+    returns trigger as
+$$
+begin
+    perform outbox_event(case when tg_op = 'INSERT' then 'thread.created' else 'thread.updated' end,
+                         new.forum, thread_json(new));
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists outbox_thread_created on threads;
+create trigger outbox_thread_created
+    after insert
+    on threads
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure outbox_thread();
+
+drop trigger if exists outbox_thread_updated on threads;
+create trigger outbox_thread_updated
+    after update of title, message
+    on threads
+    for each row
+execute procedure outbox_thread();
+
+create or replace function outbox_post()
+    returns trigger as
+$$
+begin
+    perform outbox_event(case when tg_op = 'INSERT' then 'post.created' else 'post.updated' end,
+                         new.forum, post_json(new));
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists outbox_post_created on posts;
+create trigger outbox_post_created
+    after insert
+    on posts
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure outbox_post();
+
+drop trigger if exists outbox_post_updated on posts;
+create trigger outbox_post_updated
+    after update of message
+    on posts
+    for each row
+    when (old.message is distinct from new.message)
+execute procedure outbox_post();
+
+-- отозванный голос приходит с voice = 0
+create or replace function outbox_vote()
+    returns trigger as
+$$
+declare
+    thread_ threads;
+    vote_   votes;
+begin
+    if tg_op = 'DELETE' then
+        vote_ = old;
+        vote_.voice = 0;
+    elsif tg_op = 'UPDATE' and old.voice = new.voice then
+        return null;
+    else
+        vote_ = new;
+    end if;
+    select * into thread_ from threads where id = vote_.thread;
+    perform outbox_event('vote.changed', thread_.forum,
+                         json_build_object('thread', vote_.thread, 'nickname', vote_.nickname,
+                                           'voice', vote_.voice, 'votes', thread_.votes));
+    return null;
+end;
+$$ language plpgsql;
+
+-- имя после update_votes и delete_votes: триггеры срабатывают в алфавитном порядке, votes уже пересчитаны
+drop trigger if exists vote_outbox on votes;
+create trigger vote_outbox
+    after insert or update or delete
+    on votes
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure outbox_vote();
+
+create or replace function outbox_user()
+    returns trigger as
+$$
+begin
+    perform outbox_event(case when tg_op = 'INSERT' then 'user.created' else 'user.updated' end,
+                         null, user_json(new));
+    return null;
+end;
+$$ language plpgsql;
+
+-- репутация меняется с каждым голосом, событием она не считается
+drop trigger if exists outbox_user on users;
+create trigger outbox_user
+    after insert or update of nickname, fullname, about, email
+    on users
+    for each row
+    when (current_setting('forum.import', true) is distinct from 'on')
+execute procedure outbox_user();
+
+create or replace function enqueue_webhooks(outbox_ bigint, forum_ citext, event_ text, data_ json)
+    returns void as
+$$
+begin
+    insert into webhook_deliveries (webhook, outbox, event, payload)
+    select id, outbox_, event_, json_build_object('event', event_, 'forum', forum_, 'data', data_)::text
+    from webhooks
+    where forum = forum_
+      and event_ = any (events)
+    on conflict (webhook, outbox) do nothing;
+end;
+$$ language plpgsql;
+
 CREATE INDEX IF NOT EXISTS users_idx on users (nickname, email) include (about, fullname);
 create index if not exists users_nickname_hash on users using hash (nickname);
 create index if not exists user_forum_all on user_forum (forum, nickname);
+create index if not exists user_forum_reputation on user_forum (forum, reputation desc, nickname);
+create index if not exists user_forum_activity on user_forum (forum, last_active, nickname);
+create index if not exists user_forum_posts on user_forum (forum, posts, nickname);
+create index if not exists user_forum_joined on user_forum (forum, joined, nickname);
 
 create index if not exists forums_slug on forums using hash (slug);
 
@@ -165,6 +780,7 @@ create index if not exists threads_created on threads using hash (created);
 create index if not exists threads_slug on threads using hash (slug);
 create index if not exists threads_forum ON threads using hash (forum);
 create index if not exists threads_forum_created on threads (forum, created);
+create index if not exists threads_author_created on threads (author, created);
 create index if not exists threads_id ON threads USING hash (id);
 
 create index if not exists posts_id on posts using hash (id);
@@ -173,5 +789,44 @@ create index if not exists posts_threads_parent on posts ((path[1]), path);
 create index if not exists posts_threads_past on posts (thread, path);
 create index if not exists posts_threads_id ON posts (thread, id);
 create index if not exists posts_threads_path ON posts (thread, (path[1]));
+create index if not exists posts_author_id on posts (author, id);
+
+create unique index if not exists votes_nickname on votes (thread, nickname);
+
+create index if not exists webhooks_forum on webhooks (forum);
+create index if not exists webhook_deliveries_pending on webhook_deliveries (next_attempt) where status = 'pending';
+create index if not exists webhook_deliveries_webhook on webhook_deliveries (webhook, id);
+create unique index if not exists webhook_deliveries_outbox on webhook_deliveries (webhook, outbox);
+
+create index if not exists outbox_pending on outbox (next_attempt) where published is null;
+create index if not exists outbox_published on outbox (published) where published is not null;
+-- Импорт NDJSON: сколько записей уже загружено (для продолжения после сбоя) и новые id при перенумерации
+create table if not exists imports
+(
+    name     text    not null primary key,
+    remap    boolean not null,
+    records  bigint  not null         default 0,
+    created  timestamp with time zone default now(),
+    finished timestamp with time zone
+);
+
+create table if not exists import_ids
+(
+    import text   not null references imports (name) on delete cascade,
+    kind   text   not null,
+    old_id bigint not null,
+    new_id bigint not null,
+    primary key (import, kind, old_id)
+);
+
+create unlogged table if not exists idempotency_keys
+(
+    key          text not null primary key,
+    request_hash text not null,
+    status       int                      default 0,
+    content_type text                     default '',
+    body         bytea,
+    created      timestamp with time zone default now()
+);
 
-create unique index if not exists votes_nickname on votes (thread, nickname);
\ No newline at end of file
+create index if not exists idempotency_keys_created on idempotency_keys (created);
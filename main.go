@@ -2,17 +2,50 @@ package main
 
 import (
 	"db_forum/app/handlers"
+	"db_forum/app/middleware"
+	"db_forum/app/outbox"
+	"db_forum/app/reconcile"
 	"db_forum/app/repositories"
+	"db_forum/app/repositories/cache"
+	"db_forum/app/repositories/memory"
+	"db_forum/app/repositories/sqlite"
+	"db_forum/app/stream"
 	"db_forum/app/usecases"
+	"db_forum/app/webhooks"
 	"db_forum/pkg"
+	"db_forum/pkg/openapi"
+	"flag"
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx"
+	"net/http"
 	"strings"
+	"time"
+)
+
+const (
+	idempotencyTTL             = 24 * time.Hour
+	idempotencyLease           = time.Minute
+	idempotencyMaxBody         = 1 << 20
+	idempotencyCleanupInterval = time.Hour
+	webhookPollInterval        = time.Second
+	outboxPollInterval         = 200 * time.Millisecond
+	webhookTimeout             = 10 * time.Second
 )
 
 func main() {
+	storage := flag.String("storage", "postgres", "storage backend: postgres, sqlite or memory")
+	sqlitePath := flag.String("sqlite", "forum.db", "database file of the sqlite storage")
+	reconcileInterval := flag.Duration("reconcile-interval", 0, "check the denormalised counters this often, 0 disables")
+	reconcileRepair := flag.Bool("reconcile-repair", false, "repair the counters found by the scheduled check")
+	cacheBackend := flag.String("cache", "none", "cache of forum, thread and user lookups: none, lru or redis")
+	cacheSize := flag.Int("cache-size", 10000, "entries of the lru cache")
+	cacheTTL := flag.Duration("cache-ttl", 30*time.Second, "how long a cached entry is used, bounds how stale writes of other instances are seen")
+	redisAddress := flag.String("redis", "127.0.0.1:6379", "address of the redis cache")
+	validateOpenAPI := flag.Bool("validate-openapi", false, "check requests and responses against the OpenAPI document (development)")
+	flag.Parse()
+
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
@@ -20,68 +53,201 @@ func main() {
 	config.AllowOrigins = []string{"http://127.0.0.1:5000"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	config.AllowCredentials = true
+	config.AddAllowHeaders(middleware.IdempotencyKeyHeader, pkg.IfMatchHeader, pkg.IfNoneMatchHeader, handlers.LastEventIdHeader)
+	config.AddExposeHeaders(middleware.IdempotentReplayedHeader, pkg.ETagHeader)
 
-	conn, err := pgx.ParseConnectionString(fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", "127.0.0.1", "forum", "forum", "forum", "5432"))
+	var (
+		forumRepository       repositories.ForumRepository
+		postRepository        repositories.PostRepository
+		serviceRepository     repositories.ServiceRepository
+		threadRepository      repositories.ThreadRepository
+		userRepository        repositories.UserRepository
+		voteRepository        repositories.VoteRepository
+		idempotencyRepository repositories.IdempotencyRepository
+		eventRepository       repositories.EventRepository
+		webhookRepository     repositories.WebhookRepository
+		outboxRepository      repositories.OutboxRepository
+	)
 
-	db, err := pgx.NewConnPool(pgx.ConnPoolConfig{
-		ConnConfig:     conn,
-		MaxConnections: 100,
-		AfterConnect:   nil,
-		AcquireTimeout: 0,
-	})
+	// создание репозиториев
+	switch *storage {
+	case "postgres":
+		conn, err := pgx.ParseConnectionString(fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", "127.0.0.1", "forum", "forum", "forum", "5432"))
 
-	defer db.Close()
+		db, err := pgx.NewConnPool(pgx.ConnPoolConfig{
+			ConnConfig:     conn,
+			MaxConnections: 100,
+			AfterConnect:   nil,
+			AcquireTimeout: 0,
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
 
-	// создание репозиториев
-	forumRepository := repositories.MakeForumRepository(db)
-	postRepository := repositories.MakePostRepository(db)
-	serviceRepository := repositories.MakeServiceRepository(db)
-	threadRepository := repositories.MakeThreadRepository(db)
-	userRepository := repositories.MakeUserRepository(db)
-	voteRepository := repositories.MakeVoteRepository(db)
+		defer db.Close()
+
+		forumRepository = repositories.MakeForumRepository(db)
+		postRepository = repositories.MakePostRepository(db)
+		serviceRepository = repositories.MakeServiceRepository(db)
+		threadRepository = repositories.MakeThreadRepository(db)
+		userRepository = repositories.MakeUserRepository(db)
+		voteRepository = repositories.MakeVoteRepository(db)
+		idempotencyRepository = repositories.MakeIdempotencyRepository(db)
+		eventRepository = repositories.MakeEventRepository(db)
+		webhookRepository = repositories.MakeWebhookRepository(db)
+		outboxRepository = repositories.MakeOutboxRepository(db)
+	case "sqlite":
+		db, err := sqlite.Open(*sqlitePath)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		defer db.Close()
+
+		forumRepository = sqlite.MakeForumRepository(db)
+		postRepository = sqlite.MakePostRepository(db)
+		serviceRepository = sqlite.MakeServiceRepository(db)
+		threadRepository = sqlite.MakeThreadRepository(db)
+		userRepository = sqlite.MakeUserRepository(db)
+		voteRepository = sqlite.MakeVoteRepository(db)
+		idempotencyRepository = sqlite.MakeIdempotencyRepository(db)
+		eventRepository = sqlite.MakeEventRepository(db)
+	case "memory":
+		store := memory.MakeStore()
+		forumRepository = memory.MakeForumRepository(store)
+		postRepository = memory.MakePostRepository(store)
+		serviceRepository = memory.MakeServiceRepository(store)
+		threadRepository = memory.MakeThreadRepository(store)
+		userRepository = memory.MakeUserRepository(store)
+		voteRepository = memory.MakeVoteRepository(store)
+		idempotencyRepository = memory.MakeIdempotencyRepository(store)
+		eventRepository = memory.MakeEventRepository(store)
+	default:
+		fmt.Println("unknown storage:", *storage)
+		return
+	}
+
+	// кэш поверх репозиториев
+	var entityCache cache.Cache
+	switch *cacheBackend {
+	case "none":
+	case "lru":
+		entityCache = cache.MakeLRU(*cacheSize, *cacheTTL)
+	case "redis":
+		redisCache := cache.MakeRedis(*redisAddress, *cacheTTL)
+		defer redisCache.Close()
+		entityCache = redisCache
+	default:
+		fmt.Println("unknown cache:", *cacheBackend)
+		return
+	}
+	cacheMetrics := cache.MakeMetrics(*cacheBackend)
+	if entityCache != nil {
+		forumRepository = cache.MakeForumRepository(forumRepository, entityCache, cacheMetrics)
+		threadRepository = cache.MakeThreadRepository(threadRepository, entityCache, cacheMetrics)
+		userRepository = cache.MakeUserRepository(userRepository, entityCache, cacheMetrics)
+		voteRepository = cache.MakeVoteRepository(voteRepository, entityCache)
+		serviceRepository = cache.MakeServiceRepository(serviceRepository, entityCache)
+	}
 
 	router.Use(cors.New(config))
+	if *validateOpenAPI {
+		validator, err := middleware.MakeOpenAPIValidator(openapi.Spec)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		router.Use(validator.Handle)
+	}
+
+	idempotency := middleware.MakeIdempotencyMiddleware(idempotencyRepository, idempotencyTTL, idempotencyLease, idempotencyMaxBody)
+	go idempotency.DeleteExpired(idempotencyCleanupInterval)
+
+	if *reconcileInterval > 0 {
+		go reconcile.MakeJob(serviceRepository, *reconcileRepair).Run(*reconcileInterval)
+	}
 
 	forumHandler := handlers.MakeForumHandler(usecases.MakeForumUseCase(forumRepository, threadRepository, userRepository))
-	postHandler := handlers.MakePostHandler(usecases.MakePostUseCase(forumRepository, threadRepository, userRepository, postRepository))
+	postHandler := handlers.MakePostHandler(usecases.MakePostUseCase(forumRepository, threadRepository, userRepository, postRepository, voteRepository))
 	serviceHandler := handlers.MakeServiceHandler(usecases.MakeServiceUseCase(serviceRepository))
-	threadHandler := handlers.MakeThreadHandler(usecases.MakeThreadUseCase(voteRepository, threadRepository, userRepository, postRepository))
+	cacheHandler := handlers.MakeCacheHandler(cacheMetrics)
+	threadUsecase := usecases.MakeThreadUseCase(voteRepository, threadRepository, userRepository, postRepository)
+	threadHandler := handlers.MakeThreadHandler(threadUsecase)
 	userHandler := handlers.MakeUserHandler(usecases.MakeUserUseCase(userRepository))
 
+	hub := stream.MakeHub(eventRepository, postRepository)
+	go hub.Run()
+	streamHandler := handlers.MakeStreamHandler(threadUsecase, hub)
+
+	openAPIHandler := handlers.MakeOpenAPIHandler()
+	router.GET(strings.Join([]string{pkg.RootRoute, pkg.OpenAPIRoute}, ""), openAPIHandler.Spec)
+	router.GET(strings.Join([]string{pkg.RootRoute, pkg.DocsRoute}, ""), openAPIHandler.SwaggerUI)
+
 	forumRoutes := router.Group(strings.Join([]string{pkg.RootRoute, pkg.ForumRoute}, ""))
 	{
-		forumRoutes.POST("/create", forumHandler.CreateForum)
+		forumRoutes.POST("/create", idempotency.Handle, forumHandler.CreateForum)
 		forumRoutes.GET("/:slug/details", forumHandler.GetForum)
-		forumRoutes.POST("/:slug/create", forumHandler.CreateThread)
+		forumRoutes.POST("/:slug/create", idempotency.Handle, forumHandler.CreateThread)
 		forumRoutes.GET("/:slug/users", forumHandler.GetForumUsers)
+		forumRoutes.GET("/:slug/leaderboard", forumHandler.GetForumLeaderboard)
+		forumRoutes.GET("/:slug/stats", forumHandler.GetForumStats)
+		// webhooks rely on the outbox triggers and are only served from postgres
+		if webhookRepository != nil {
+			webhookHandler := handlers.MakeWebhookHandler(usecases.MakeWebhookUseCase(forumRepository, webhookRepository))
+			dispatcher := webhooks.MakeDispatcher(webhookRepository, &http.Client{Timeout: webhookTimeout})
+			go dispatcher.Run(webhookPollInterval)
+
+			outboxDispatcher := outbox.MakeDispatcher(outboxRepository, webhooks.MakeSink(webhookRepository))
+			go outboxDispatcher.Run(outboxPollInterval)
+
+			forumRoutes.POST("/:slug/webhooks", idempotency.Handle, webhookHandler.CreateWebhook)
+			forumRoutes.GET("/:slug/webhooks", webhookHandler.GetWebhooks)
+			forumRoutes.DELETE("/:slug/webhooks/:id", webhookHandler.DeleteWebhook)
+			forumRoutes.GET("/:slug/webhooks/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+		}
 		forumRoutes.GET("/:slug/:threads", forumHandler.GetForumThreads)
 	}
 	postRoutes := router.Group(strings.Join([]string{pkg.RootRoute, pkg.PostRoute}, ""))
 	{
 		postRoutes.GET("/:id/details", postHandler.GetPost)
 		postRoutes.POST("/:id/details", postHandler.UpdatePost)
+		postRoutes.POST("/:id/vote", postHandler.Vote)
 	}
 	serviceRoutes := router.Group(strings.Join([]string{pkg.RootRoute, pkg.ServiceRoute}, ""))
 	{
 		serviceRoutes.POST("/clear", serviceHandler.Clear)
 		serviceRoutes.GET("/status", serviceHandler.GetStatus)
+		serviceRoutes.POST("/reconcile", serviceHandler.Reconcile)
+		serviceRoutes.GET("/cache", cacheHandler.GetStats)
 	}
 	threadRoutes := router.Group(strings.Join([]string{pkg.RootRoute, pkg.ThreadRoute}, ""))
 	{
-		threadRoutes.POST("/:slug_or_id/create", threadHandler.CreatePosts)
+		threadRoutes.POST("/:slug_or_id/create", idempotency.Handle, threadHandler.CreatePosts)
 		threadRoutes.GET("/:slug_or_id/details", threadHandler.GetThread)
 		threadRoutes.POST("/:slug_or_id/details", threadHandler.UpdateThread)
 		threadRoutes.GET("/:slug_or_id/posts", threadHandler.GetThreadPosts)
 		threadRoutes.POST("/:slug_or_id/vote", threadHandler.Vote)
+		threadRoutes.DELETE("/:slug_or_id/vote", threadHandler.RetractVote)
+		threadRoutes.GET("/:slug_or_id/votes", threadHandler.GetThreadVoters)
+		threadRoutes.GET("/:slug_or_id/events", streamHandler.ThreadEvents)
+		threadRoutes.GET("/:slug_or_id/ws", streamHandler.ThreadWebSocket)
 	}
 	userRoutes := router.Group(strings.Join([]string{pkg.RootRoute, pkg.UserRoute}, ""))
 	{
-		userRoutes.POST("/:nickname/create", userHandler.CreateUser)
+		userRoutes.POST("/:nickname/create", idempotency.Handle, userHandler.CreateUser)
 		userRoutes.GET("/:nickname/profile", userHandler.GetUser)
 		userRoutes.POST("/:nickname/profile", userHandler.UpdateUser)
+		userRoutes.DELETE("/:nickname/profile", userHandler.DeleteUser)
+		userRoutes.POST("/:nickname/rename", userHandler.RenameUser)
+		userRoutes.GET("/:nickname/export", userHandler.ExportUser)
+		userRoutes.GET("/:nickname/posts", userHandler.GetUserPosts)
+		userRoutes.GET("/:nickname/threads", userHandler.GetUserThreads)
+		userRoutes.GET("/:nickname/forums", userHandler.GetUserForums)
 	}
 
-	err = router.Run(":5000")
+	err := router.Run(":5000")
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"db_forum/app/models"
+	"db_forum/pkg/validation"
 	"errors"
 	"net/http"
 )
@@ -21,15 +22,30 @@ var (
 	// Thread errors
 	ErrThreadAlreadyExists = errors.New("thread already exist")
 	ErrThreadNotFound      = errors.New("Can't find user with id ")
+	ErrVoteNotFound        = errors.New("Can't find vote")
+
+	// Webhook errors
+	ErrWebhookNotFound = errors.New("Can't find webhook")
 
 	// User errors
 	ErrUserAlreadyExist = errors.New("user already exist")
 	ErrUserNotFound     = errors.New("Can't find user with id ")
 	ErrUserDataConflict = errors.New("Can't find user with id ")
+	ErrUserHasContent   = errors.New("user still owns forums, threads or posts")
+	ErrUserIsTombstone  = errors.New("user deleted is reserved")
+	ErrTombstoneTaken   = errors.New("email of user deleted belongs to another user")
 
 	// Request Errors
-	ErrBadInputData = errors.New("bad input data")
-	ErrBadRequest   = errors.New("bad request")
+	ErrBadInputData    = errors.New("bad input data")
+	ErrBadRequest      = errors.New("bad request")
+	ErrRequestTooLarge = errors.New("request body is too large")
+
+	// Concurrency errors
+	ErrPreconditionFailed = errors.New("resource was modified by another request")
+
+	// Idempotency errors
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with another request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
 
 	// Internal errors
 	ErrNotImplemented = errors.New("not implemented")
@@ -45,17 +61,29 @@ var errorToCode = map[error]int{
 
 	ErrThreadAlreadyExists: http.StatusConflict,
 	ErrThreadNotFound:      http.StatusNotFound,
+	ErrVoteNotFound:        http.StatusNotFound,
 
 	ErrPostNotFound:              http.StatusNotFound,
 	ErrParentPostNotExist:        http.StatusNotFound,
 	ErrParentPostFromOtherThread: http.StatusConflict,
 
+	ErrWebhookNotFound: http.StatusNotFound,
+
 	ErrUserAlreadyExist: http.StatusConflict,
 	ErrUserNotFound:     http.StatusNotFound,
 	ErrUserDataConflict: http.StatusConflict,
+	ErrUserHasContent:   http.StatusConflict,
+	ErrUserIsTombstone:  http.StatusForbidden,
+	ErrTombstoneTaken:   http.StatusConflict,
 
-	ErrBadInputData: http.StatusBadRequest,
-	ErrBadRequest:   http.StatusBadRequest,
+	ErrBadInputData:    http.StatusBadRequest,
+	ErrBadRequest:      http.StatusBadRequest,
+	ErrRequestTooLarge: http.StatusRequestEntityTooLarge,
+
+	ErrPreconditionFailed: http.StatusPreconditionFailed,
+
+	ErrIdempotencyKeyReused:     http.StatusConflict,
+	ErrIdempotencyKeyInProgress: http.StatusConflict,
 
 	ErrNotImplemented: http.StatusNotImplemented,
 	ErrInternal:       http.StatusInternalServerError,
@@ -72,7 +100,13 @@ func ConvertErrorToCode(err error) (code int) {
 func CreateErrorResponse(err error) (statusCode int, contentType string, errorJSON []byte) {
 	statusCode = ConvertErrorToCode(err)
 	contentType = "application/json; charset=utf-8"
-	errorJSON, errMarshal := models.Error{Message: err.Error()}.MarshalJSON()
+	errorModel := models.Error{Message: err.Error()}
+	var validationErrs *validation.Errors
+	if errors.As(err, &validationErrs) {
+		statusCode = http.StatusBadRequest
+		errorModel.Fields = validationErrs.Fields
+	}
+	errorJSON, errMarshal := errorModel.MarshalJSON()
 	if errMarshal != nil {
 		statusCode = ConvertErrorToCode(ErrInternal)
 		errorJSON, _ = models.Error{Message: ErrInternal.Error()}.MarshalJSON()
@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// CreateETag builds a strong entity tag from the versions of every entity
+// a response is made of, e.g. a post with its related thread: "7.3".
+func CreateETag(versions ...int64) string {
+	parts := make([]string, 0, len(versions))
+	for _, version := range versions {
+		parts = append(parts, strconv.FormatInt(version, 10))
+	}
+	return strconv.Quote(strings.Join(parts, "."))
+}
+
+// ParseIfMatch returns the version an update is conditioned on,
+// 0 means the update is unconditional (no header or "*").
+func ParseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+func MatchesIfNoneMatch(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package pkg
+
+import "testing"
+
+func TestCreateETag(t *testing.T) {
+	tests := []struct {
+		versions []int64
+		want     string
+	}{
+		{[]int64{1}, `"1"`},
+		{[]int64{7, 3}, `"7.3"`},
+		{[]int64{12, 1, 40}, `"12.1.40"`},
+	}
+	for _, test := range tests {
+		if got := CreateETag(test.versions...); got != test.want {
+			t.Errorf("CreateETag(%v) = %s, want %s", test.versions, got, test.want)
+		}
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr error
+	}{
+		{"", 0, nil},
+		{"*", 0, nil},
+		{" * ", 0, nil},
+		{`"5"`, 5, nil},
+		{`W/"5"`, 5, nil},
+		{"5", 5, nil},
+		{` "42" `, 42, nil},
+		{`"0"`, 0, ErrPreconditionFailed},
+		{`"-1"`, 0, ErrPreconditionFailed},
+		{`"abc"`, 0, ErrPreconditionFailed},
+		// an ETag of several versions can't condition an update of one entity
+		{`"7.3"`, 0, ErrPreconditionFailed},
+	}
+	for _, test := range tests {
+		got, err := ParseIfMatch(test.header)
+		if got != test.want || err != test.wantErr {
+			t.Errorf("ParseIfMatch(%q) = %d, %v, want %d, %v", test.header, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestMatchesIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		want   bool
+	}{
+		{"", `"1"`, false},
+		{`"1"`, `"1"`, true},
+		{`"2"`, `"1"`, false},
+		{`W/"1"`, `"1"`, true},
+		{"*", `"1"`, true},
+		{`"2", "7.3"`, `"7.3"`, true},
+		{`"2","3"`, `"3"`, true},
+		{`"7.3"`, `"7.4"`, false},
+	}
+	for _, test := range tests {
+		if got := MatchesIfNoneMatch(test.header, test.etag); got != test.want {
+			t.Errorf("MatchesIfNoneMatch(%q, %s) = %t, want %t", test.header, test.etag, got, test.want)
+		}
+	}
+}
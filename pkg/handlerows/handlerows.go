@@ -35,7 +35,7 @@ func Post(result *pgx.Rows) (*[]models.Post, error) {
 		post := models.Post{}
 		postTime := time.Time{}
 
-		err = result.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &postTime)
+		err = result.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &postTime, &post.Score)
 		if err != nil {
 			return nil, err
 		}
@@ -51,7 +51,21 @@ func User(result *pgx.Rows) (*[]models.User, error) {
 	var err error
 	for result.Next() {
 		user := models.User{}
-		err = result.Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email)
+		err = result.Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return &users, nil
+}
+
+func ForumUser(result *pgx.Rows) (*[]models.ForumUser, error) {
+	var users []models.ForumUser
+	var err error
+	for result.Next() {
+		user := models.ForumUser{}
+		err = result.Scan(&user.Nickname, &user.Fullname, &user.About, &user.Email, &user.Reputation, &user.Threads, &user.Posts, &user.Joined, &user.LastActive)
 		if err != nil {
 			return nil, err
 		}
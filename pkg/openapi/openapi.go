@@ -0,0 +1,8 @@
+// Package openapi embeds the OpenAPI document describing the API, it is
+// maintained by hand next to the handlers.
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.json
+var Spec []byte
@@ -1,52 +1,266 @@
 package queries
 
 var (
-	ForumCreate              = `insert into "forums" ("title", "user_", "slug") values ($1, $2, $3);`
-	ForumGetBySlug           = `select "title", "user_", "slug", "posts", "threads" from "forums" where "slug" = $1`
-	ForumGetUsers            = "select users.nickname, users.fullname, users.about, users.email from users left join user_forum on users.nickname = user_forum.nickname where user_forum.forum = $1 order by users.nickname limit $2;"
-	ForumGetUsersDesc        = "select users.nickname, users.fullname, users.about, users.email from users left join user_forum on users.nickname = user_forum.nickname where user_forum.forum = $1 order by users.nickname desc limit $2;"
-	ForumGetUsersSince       = "select users.nickname, users.fullname, users.about, users.email from users left join user_forum on users.nickname = user_forum.nickname where user_forum.forum = $1 and users.nickname > $2 order by users.nickname limit $3;"
-	ForumGetUsersSinceDesc   = "select users.nickname, users.fullname, users.about, users.email from users left join user_forum on users.nickname = user_forum.nickname where user_forum.forum = $1 and users.nickname < $2 order by users.nickname desc limit $3;"
-	ForumGetThreads          = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 order by created asc limit $2;"
-	ForumGetThreadsDesc      = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 order by created desc limit $2;"
-	ForumGetThreadsSince     = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 and created >= $2 order by created asc limit $3;"
-	ForumGetThreadsSinceDesc = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 and created <= $2 order by created desc limit $3;"
-
-	PostGet    = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts where id = $1"
-	PostUpdate = "update posts set message = $1, is_edited = $2 where id = $3;"
-	PostPart   = "insert into posts (parent, author, message, forum, thread, created) values "
-
-	ServiceClear = "truncate table forums, posts, threads, user_forum, users, votes;"
+	ForumCreate            = `insert into "forums" ("title", "user_", "slug") values ($1, $2, $3);`
+	ForumGetBySlug         = `select "title", "user_", "slug", "posts", "threads", "version" from "forums" where "slug" = $1`
+	ForumGetUsersBase      = "select u.nickname, u.fullname, u.about, u.email, u.reputation, uf.threads, uf.posts, uf.joined, uf.last_active from user_forum uf join users u on u.nickname = uf.nickname where uf.forum = $1 "
+	ForumGetUsers          = "order by uf.nickname limit $2;"
+	ForumGetUsersDesc      = "order by uf.nickname desc limit $2;"
+	ForumGetUsersSince     = "and uf.nickname > $2 order by uf.nickname limit $3;"
+	ForumGetUsersSinceDesc = "and uf.nickname < $2 order by uf.nickname desc limit $3;"
+	// the other orders page by the position of the since participant
+	ForumGetUsersActivity          = "order by uf.last_active, uf.nickname limit $2;"
+	ForumGetUsersActivityDesc      = "order by uf.last_active desc, uf.nickname desc limit $2;"
+	ForumGetUsersActivitySince     = "and (uf.last_active, uf.nickname) > ((select last_active from user_forum where forum = $1 and nickname = $2), $2) order by uf.last_active, uf.nickname limit $3;"
+	ForumGetUsersActivitySinceDesc = "and (uf.last_active, uf.nickname) < ((select last_active from user_forum where forum = $1 and nickname = $2), $2) order by uf.last_active desc, uf.nickname desc limit $3;"
+	ForumGetUsersPosts             = "order by uf.posts, uf.nickname limit $2;"
+	ForumGetUsersPostsDesc         = "order by uf.posts desc, uf.nickname desc limit $2;"
+	ForumGetUsersPostsSince        = "and (uf.posts, uf.nickname) > ((select posts from user_forum where forum = $1 and nickname = $2), $2) order by uf.posts, uf.nickname limit $3;"
+	ForumGetUsersPostsSinceDesc    = "and (uf.posts, uf.nickname) < ((select posts from user_forum where forum = $1 and nickname = $2), $2) order by uf.posts desc, uf.nickname desc limit $3;"
+	ForumGetUsersJoined            = "order by uf.joined, uf.nickname limit $2;"
+	ForumGetUsersJoinedDesc        = "order by uf.joined desc, uf.nickname desc limit $2;"
+	ForumGetUsersJoinedSince       = "and (uf.joined, uf.nickname) > ((select joined from user_forum where forum = $1 and nickname = $2), $2) order by uf.joined, uf.nickname limit $3;"
+	ForumGetUsersJoinedSinceDesc   = "and (uf.joined, uf.nickname) < ((select joined from user_forum where forum = $1 and nickname = $2), $2) order by uf.joined desc, uf.nickname desc limit $3;"
+	ForumGetLeaderboard            = "select users.nickname, users.fullname, user_forum.reputation from user_forum join users on users.nickname = user_forum.nickname where user_forum.forum = $1 order by user_forum.reputation desc, user_forum.nickname limit $2;"
+	ForumGetThreads                = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 order by created asc limit $2;"
+	ForumGetThreadsDesc            = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 order by created desc limit $2;"
+	ForumGetThreadsSince           = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 and created >= $2 order by created asc limit $3;"
+	ForumGetThreadsSinceDesc       = "select id, title, author, forum, message, votes, slug, created from threads where forum = $1 and created <= $2 order by created desc limit $3;"
+	// $2 is hour or day, the hours are summed up into the buckets
+	ForumGetStats = "with counts as (select date_trunc($2, hour, 'UTC') as bucket, sum(threads)::int as threads, sum(posts)::int as posts, sum(votes)::int as votes " +
+		"from forum_stats where forum = $1 and hour >= $3 and hour < $4 group by 1), " +
+		"users as (select date_trunc($2, hour, 'UTC') as bucket, count(distinct nickname)::int as users from forum_stats_users where forum = $1 and hour >= $3 and hour < $4 group by 1) " +
+		"select counts.bucket, counts.threads, counts.posts, coalesce(users.users, 0), counts.votes from counts left join users on users.bucket = counts.bucket order by counts.bucket;"
+
+	PostGet        = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score, version from posts where id = $1"
+	PostUpdate     = "update posts set message = $1, is_edited = $2, version = version + 1 where id = $3 and ($4 = 0 or version = $4) returning version;"
+	PostBulk       = "set local forum.bulk_posts = 'on';"
+	PostNextIds    = "select nextval(pg_get_serial_sequence('posts', 'id')) from generate_series(1, $1);"
+	PostPaths      = "select id, path from posts where id = any($1) and thread = $2;"
+	PostExists     = "select exists(select 1 from posts where id = $1);"
+	PostForumUsers = "insert into user_forum (nickname, forum, posts, joined, last_active) select nickname, $2, posts, $4::timestamptz, $4::timestamptz from unnest($1::text[], $3::int[]) as u (nickname, posts) " +
+		"on conflict (nickname, forum) do update set posts = user_forum.posts + excluded.posts, joined = least(user_forum.joined, excluded.joined), last_active = greatest(user_forum.last_active, excluded.last_active);"
+	PostForumDelta = "update forums set posts = posts + $2, version = version + $2 where slug = $1;"
+	PostForumStats = "select count_activity($2, nickname, $4, 0, posts, 0) from unnest($1::text[], $3::int[]) as u (nickname, posts);"
+	PostVote       = "insert into post_votes (nickname, post, voice) values ($1, $2, $3) on conflict (post, nickname) do update set voice = excluded.voice;"
+	PostScore      = "select score, version from posts where id = $1;"
+
+	ServiceClear = "truncate table forums, posts, post_votes, threads, user_forum, users, user_redirects, votes, forum_stats, forum_stats_users, idempotency_keys, webhooks, webhook_deliveries, outbox, imports, import_ids;"
 	ServiceGet   = "select (select count(*) from users) as users, (select count(*) from forums) as forums, (select count(*) from threads) as threads, (select count(*) from posts) as posts;"
 
-	ThreadCreate  = "insert into threads (title, author, forum, message, slug, created) values ($1, $2, $3, $4, $5, $6) returning id, created;"
-	ThreadGetSlug = "select id, title, author, forum, message, votes, slug, created from threads where slug = $1;"
-	ThreadGetId   = "select id, title, author, forum, message, votes, slug, created from threads where id = $1;"
-	ThreadVotes   = "select votes from threads where id = $1;"
-	ThreadUpdate  = "update threads SET title = $1, message = $2 where id = $3;"
+	ServiceDriftForums = "select f.slug, f.posts, coalesce(p.count, 0), f.threads, coalesce(t.count, 0) from forums f " +
+		"left join (select forum, count(*) from posts group by forum) p on p.forum = f.slug left join (select forum, count(*) from threads group by forum) t on t.forum = f.slug " +
+		"where f.posts <> coalesce(p.count, 0) or f.threads <> coalesce(t.count, 0) order by f.slug;"
+	ServiceDriftThreads = "select t.id, t.votes, coalesce(v.sum, 0) from threads t left join (select thread, sum(voice) from votes group by thread) v on v.thread = t.id " +
+		"where t.votes <> coalesce(v.sum, 0) order by t.id;"
+	ServiceDriftForumUsers = "with actual as (select forum, author as nickname from threads union select forum, author from posts), " +
+		"drift as (select distinct coalesce(a.forum, u.forum) as forum from actual a full join user_forum u on u.forum = a.forum and u.nickname = a.nickname where a.nickname is null or u.nickname is null) " +
+		"select drift.forum, (select count(*) from user_forum where forum = drift.forum), (select count(*) from actual where forum = drift.forum) from drift order by drift.forum;"
+	// activity of the participants of user_forum, timestamps in microseconds
+	ServiceDriftForumActivity = "with actual as (select forum, author, count(*) filter (where thread) as threads, count(*) filter (where not thread) as posts, min(created) as joined, max(created) as last_active " +
+		"from (select forum, author, created, true as thread from threads union all select forum, author, created, false from posts) as activity group by forum, author) " +
+		"select u.forum, u.nickname, u.threads, a.threads, u.posts, a.posts, coalesce((extract(epoch from u.joined) * 1000000)::bigint, 0), (extract(epoch from a.joined) * 1000000)::bigint, " +
+		"coalesce((extract(epoch from u.last_active) * 1000000)::bigint, 0), (extract(epoch from a.last_active) * 1000000)::bigint from user_forum u join actual a on a.forum = u.forum and a.author = u.nickname " +
+		"where u.threads <> a.threads or u.posts <> a.posts or u.joined is distinct from a.joined or u.last_active is distinct from a.last_active order by u.forum, u.nickname;"
+	ServiceRepairForum         = "update forums set posts = posts + $2, threads = threads + $3, version = version + 1 where slug = $1;"
+	ServiceRepairThread        = "update threads set votes = votes + $2, version = version + 1 where id = $1;"
+	ServiceRepairForumUsers    = "insert into user_forum (nickname, forum) select author, forum from threads where forum = $1 union select author, forum from posts where forum = $1 on conflict do nothing;"
+	ServiceRepairForumActivity = "update user_forum uf set threads = a.threads, posts = a.posts, joined = a.joined, last_active = a.last_active " +
+		"from (select author, count(*) filter (where thread) as threads, count(*) filter (where not thread) as posts, min(created) as joined, max(created) as last_active " +
+		"from (select author, created, true as thread from threads where forum = $1 union all select author, created, false from posts where forum = $1) as activity group by author) as a " +
+		"where uf.forum = $1 and uf.nickname = a.author;"
 
-	ThreadFlatBase      = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts where thread = $1 "
+	ThreadCreate  = "insert into threads (title, author, forum, message, slug, created) values ($1, $2, $3, $4, $5, $6) returning id, created, version;"
+	ThreadGetSlug = "select id, title, author, forum, message, votes, slug, created, version from threads where slug = $1;"
+	ThreadGetId   = "select id, title, author, forum, message, votes, slug, created, version from threads where id = $1;"
+	ThreadUpdate  = "update threads SET title = $1, message = $2, version = version + 1 where id = $3 and ($4 = 0 or version = $4) returning version;"
+
+	ThreadFlatBase      = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where thread = $1 "
 	ThreadFlat          = "and id > $2 order by id limit $3;"
 	ThreadFlatDesc      = "and id < $2 order by id desc limit $3;"
 	ThreadFlatSince     = "order by id limit $2;"
 	ThreadFlatSinceDesc = " order by id desc limit $2;"
 
-	ThreadTreeBase      = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts "
+	ThreadTreeBase      = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts "
 	ThreadTree          = "where thread = $1 and path > (select path from posts where id = $2) order by path limit $3;"
 	ThreadTreeDesc      = "where thread = $1 and path < (select path from posts where id = $2) order by path desc limit $3;"
 	ThreadTreeSince     = "where thread = $1 order by path limit $2;"
 	ThreadTreeSinceDesc = "where thread = $1 order by path desc limit $2;"
 
-	ThreadParentBase          = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts where path[1] in "
+	ThreadParentBase          = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where path[1] in "
 	ThreadParentTree          = "(select id from posts where thread = $1 and parent is null and path[1] > (select path[1] from posts where id = $2) order by path[1] limit $3) order by path;"
 	ThreadParentTreeDesc      = "(select id from posts where thread = $1 and parent is null and path[1] < (select path[1] from posts where id = $2) order by path[1] desc limit $3) order by path[1] desc, path [2:];"
 	ThreadParentTreeSince     = "(select id from posts where thread = $1 and parent is null order by path[1] limit $2) order by path;"
 	ThreadParentTreeSinceDesc = "(select id from posts where thread = $1 and parent is null order by path[1] desc limit $2) order by path[1] desc, path[2:]"
 
-	UserCreate     = "insert into users values ($1, $2, $3, $4);"
-	UserUpdate     = "update users set fullname = $1, about = $2, email = $3 where nickname = $4 returning fullname, about, email;"
-	UserGet        = "select nickname, fullname, about, email from users where nickname = $1;"
-	UserGetSimilar = "select nickname, fullname, about, email from users where nickname = $1 or email = $2;"
+	// top and controversial keep the tree of parent_tree and rank the siblings
+	// at every level; roots are numbered by their place, which pages them.
+	ThreadRankedBase        = "with recursive ranked as (select id, parent, row_number() over (partition by parent order by "
+	ThreadRankTop           = "score desc, id"
+	ThreadRankControversial = "least(voters + score, voters - score) desc, voters desc, id"
+	ThreadRankedTree        = ") as place from posts where thread = $1), tree as (select id, place as root, array[place] as key from ranked where parent is null " +
+		"union all select r.id, t.root, t.key || r.place from ranked r join tree t on r.parent = t.id) " +
+		"select p.id, coalesce(p.parent, 0), p.author, p.message, p.is_edited, p.forum, p.thread, p.created, p.score from tree join posts p on p.id = tree.id "
+	ThreadRanked          = "where tree.root > (select root from tree where id = $2) and tree.root <= (select root from tree where id = $2) + $3 order by tree.key;"
+	ThreadRankedDesc      = "where tree.root < (select root from tree where id = $2) and tree.root >= (select root from tree where id = $2) - $3 order by tree.root desc, tree.key;"
+	ThreadRankedSince     = "where tree.root <= $2 order by tree.key;"
+	ThreadRankedSinceDesc = "where tree.root > (select count(*) from ranked where parent is null) - $2 order by tree.root desc, tree.key;"
+
+	UserCreate     = "insert into users (nickname, fullname, about, email) values ($1, $2, $3, $4);"
+	UserUpdate     = "update users set fullname = $1, about = $2, email = $3, version = version + 1 where nickname = $4 and ($5 = 0 or version = $5) returning fullname, about, email, reputation, version;"
+	UserGet        = "select nickname, fullname, about, email, reputation, version from users where nickname = $1;"
+	UserGetSimilar = "select nickname, fullname, about, email, reputation from users where nickname = $1 or email = $2;"
+
+	UserGetPosts            = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = $1 order by id limit $2;"
+	UserGetPostsDesc        = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = $1 order by id desc limit $2;"
+	UserGetPostsSince       = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = $1 and id > $2 order by id limit $3;"
+	UserGetPostsSinceDesc   = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = $1 and id < $2 order by id desc limit $3;"
+	UserGetThreads          = "select id, title, author, forum, message, votes, slug, created from threads where author = $1 order by created asc limit $2;"
+	UserGetThreadsDesc      = "select id, title, author, forum, message, votes, slug, created from threads where author = $1 order by created desc limit $2;"
+	UserGetThreadsSince     = "select id, title, author, forum, message, votes, slug, created from threads where author = $1 and created >= $2 order by created asc limit $3;"
+	UserGetThreadsSinceDesc = "select id, title, author, forum, message, votes, slug, created from threads where author = $1 and created <= $2 order by created desc limit $3;"
+	UserGetForumsBase       = "select f.slug, f.title, f.user_, (select count(*) from posts p where p.author = uf.nickname and p.forum = uf.forum)::int from user_forum uf join forums f on f.slug = uf.forum where uf.nickname = $1 "
+	UserGetForums           = "order by uf.forum limit $2;"
+	UserGetForumsDesc       = "order by uf.forum desc limit $2;"
+	UserGetForumsSince      = "and uf.forum > $2 order by uf.forum limit $3;"
+	UserGetForumsSinceDesc  = "and uf.forum < $2 order by uf.forum desc limit $3;"
+
+	UserExportThreads = "select id, title, author, forum, message, votes, slug, created from threads where author = $1 order by id;"
+	UserExportPosts   = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created, score from posts where author = $1 order by id;"
+	UserExportVotes   = "select nickname, thread, 0::bigint, voice from votes where nickname = $1 union all " +
+		"select v.nickname, p.thread, v.post, v.voice from post_votes v join posts p on p.id = v.post where v.nickname = $1 order by 2, 3;"
+	UserTombstone      = "insert into users (nickname, fullname, about, email) values ($1, $2, '', $3) on conflict (nickname) do nothing;"
+	UserTombstoneTaken = "select exists(select 1 from users where email = $2 and nickname <> $1);"
+	UserLock           = "select nickname from users where nickname = $1 for update;"
+	UserDeleteVotes    = "delete from votes where nickname = $1;"
+	UserDeleteScores   = "delete from post_votes where nickname = $1;"
+	UserMoveForums     = "insert into user_forum (nickname, forum, reputation, threads, posts, joined, last_active) select $2, forum, reputation, threads, posts, joined, last_active from user_forum where nickname = $1 " +
+		"on conflict (nickname, forum) do update set reputation = user_forum.reputation + excluded.reputation, threads = user_forum.threads + excluded.threads, posts = user_forum.posts + excluded.posts, " +
+		"joined = least(user_forum.joined, excluded.joined), last_active = greatest(user_forum.last_active, excluded.last_active);"
+	UserDeleteForums  = "delete from user_forum where nickname = $1;"
+	UserMoveStats     = "insert into forum_stats_users (forum, hour, nickname) select forum, hour, $2 from forum_stats_users where nickname = $1 on conflict do nothing;"
+	UserDeleteStats   = "delete from forum_stats_users where nickname = $1;"
+	UserAddReputation = "update users set reputation = reputation + (select reputation from users where nickname = $1), version = version + 1 where nickname = $2;"
+	UserMoveOwnForums = "update forums set user_ = $2, version = version + 1 where user_ = $1;"
+	UserMoveThreads   = "update threads set author = $2, version = version + 1 where author = $1;"
+	UserMovePosts     = "update posts set author = $2, version = version + 1 where author = $1;"
+	UserDelete        = "delete from users where nickname = $1;"
+
+	UserRename        = "update users set nickname = $2, version = version + 1 where nickname = $1 and ($3 = 0 or version = $3) returning nickname, fullname, about, email, reputation, version;"
+	UserRenameForums  = "update forums set version = version + 1 where user_ = $1;"
+	UserRenameThreads = "update threads set version = version + 1 where author = $1;"
+	UserRenamePosts   = "update posts set version = version + 1 where author = $1;"
+	UserRedirectGet   = "select target from user_redirects where nickname = $1 and created > now() - make_interval(secs => $2);"
+	UserRedirectTaken = "select exists(select 1 from user_redirects where nickname = $1 and target <> $2 and created > now() - make_interval(secs => $3));"
+	UserRedirectPrune = "delete from user_redirects where nickname = $1 or created <= now() - make_interval(secs => $2);"
+	UserRedirectSave  = "insert into user_redirects (nickname, target) values ($1, $2) on conflict (nickname) do update set target = excluded.target, created = now();"
+
+	ThreadEventsChannel = "thread_events"
+
+	IdempotencyGet           = "select key, request_hash, status, content_type, coalesce(body, ''::bytea) from idempotency_keys where key = $1 and created > now() - make_interval(secs => $2);"
+	IdempotencyReserve       = "insert into idempotency_keys (key, request_hash) values ($1, $2) on conflict (key) do update set request_hash = excluded.request_hash, status = 0, content_type = '', body = null, created = now() where idempotency_keys.created <= now() - make_interval(secs => $3) or (idempotency_keys.status = 0 and idempotency_keys.created <= now() - make_interval(secs => $4)) returning key;"
+	IdempotencySave          = "update idempotency_keys set status = $3, content_type = $4, body = $5 where key = $1 and request_hash = $2 and status = 0;"
+	IdempotencyRelease       = "delete from idempotency_keys where key = $1 and request_hash = $2 and status = 0;"
+	IdempotencyDeleteExpired = "delete from idempotency_keys where created <= now() - make_interval(secs => $1);"
+
+	WebhookCreate     = "insert into webhooks (forum, url, secret, events) values ($1, $2, $3, $4) returning id, created;"
+	WebhookGet        = "select id, forum, url, events, created from webhooks where id = $1 and forum = $2;"
+	WebhookGetByForum = "select id, forum, url, events, created from webhooks where forum = $1 order by id;"
+	WebhookDelete     = "delete from webhooks where id = $1 and forum = $2;"
+
+	WebhookDeliveriesBase      = "select id, webhook, event, payload, status, attempts, coalesce(response_code, 0), coalesce(last_error, ''), created, next_attempt, delivered from webhook_deliveries where webhook = $1 "
+	WebhookDeliveries          = "order by id limit $2;"
+	WebhookDeliveriesDesc      = "order by id desc limit $2;"
+	WebhookDeliveriesSince     = "and id > $2 order by id limit $3;"
+	WebhookDeliveriesSinceDesc = "and id < $2 order by id desc limit $3;"
+
+	WebhookDeliveriesClaim = "update webhook_deliveries d set attempts = d.attempts + 1, next_attempt = now() + make_interval(secs => $2) from webhooks w " +
+		"where d.webhook = w.id and d.id in (select id from webhook_deliveries where status = 'pending' and next_attempt <= now() order by next_attempt limit $1 for update skip locked) " +
+		"returning d.id, d.webhook, d.event, d.payload, d.attempts, w.url, w.secret;"
+	WebhookDeliverySucceeded = "update webhook_deliveries set status = 'delivered', response_code = $2, last_error = null, delivered = now() where id = $1;"
+	WebhookDeliveryFailed    = "update webhook_deliveries set status = $2, response_code = nullif($3, 0), last_error = $4, next_attempt = now() + make_interval(secs => $5) where id = $1;"
+
+	WebhookEnqueue = "select enqueue_webhooks($1, $2, $3, $4::json);"
+
+	OutboxClaim           = "update outbox set attempts = attempts + 1, next_attempt = now() + make_interval(secs => $2) where id in (select id from outbox where published is null and next_attempt <= now() order by id limit $1 for update skip locked) returning id, type, coalesce(forum, ''), payload, attempts, created;"
+	OutboxPublished       = "update outbox set published = now(), last_error = null where id = $1;"
+	OutboxFailed          = "update outbox set last_error = $2, next_attempt = now() + make_interval(secs => $3) where id = $1;"
+	OutboxDeletePublished = "delete from outbox where published <= now() - make_interval(secs => $1);"
+
+	Vote            = "insert into votes (nickname, thread, voice) values ($1, $2, $3) on conflict (nickname, thread) do update set voice = excluded.voice;"
+	VoteDelete      = "delete from votes where thread = $1 and nickname = $2;"
+	VoteThreadTotal = "select votes, version from threads where id = $1;"
+	VoteCounts      = "select coalesce(sum(voice), 0)::int, (count(*) filter (where voice > 0))::int, (count(*) filter (where voice < 0))::int from votes where thread = $1;"
+	Voters          = "select nickname, voice from votes where thread = $1 order by nickname limit $2;"
+	VotersDesc      = "select nickname, voice from votes where thread = $1 order by nickname desc limit $2;"
+	VotersSince     = "select nickname, voice from votes where thread = $1 and nickname > $2 order by nickname limit $3;"
+	VotersSinceDesc = "select nickname, voice from votes where thread = $1 and nickname < $2 order by nickname desc limit $3;"
+
+	AdminDeleteThreadVotes     = "delete from votes where thread = $1;"
+	AdminDeleteThreadScores    = "delete from post_votes where post in (select id from posts where thread = $1);"
+	AdminDeleteThreadPosts     = "delete from posts where thread = $1;"
+	AdminDeleteThread          = "delete from threads where id = $1 returning forum;"
+	AdminDeleteForumVotes      = "delete from votes where thread in (select id from threads where forum = $1);"
+	AdminDeleteForumScores     = "delete from post_votes where post in (select id from posts where forum = $1);"
+	AdminDeleteForumPosts      = "delete from posts where forum = $1;"
+	AdminDeleteForumThreads    = "delete from threads where forum = $1;"
+	AdminDeleteForumUsers      = "delete from user_forum where forum = $1;"
+	AdminDeleteForumStats      = "delete from forum_stats where forum = $1;"
+	AdminDeleteForumStatsUsers = "delete from forum_stats_users where forum = $1;"
+	AdminDeleteForumHooks      = "delete from webhooks where forum = $1;"
+	AdminDeleteForum           = "delete from forums where slug = $1;"
+	AdminForumDiscount         = "update forums set threads = threads - $2, posts = posts - $3, version = version + 1 where slug = $1;"
+	AdminForumPruneUsers       = "delete from user_forum uf where forum = $1 and not exists (select 1 from threads where forum = uf.forum and author = uf.nickname) and not exists (select 1 from posts where forum = uf.forum and author = uf.nickname);"
+	AdminUserHasContent        = "select exists(select 1 from forums where user_ = $1) or exists(select 1 from threads where author = $1) or exists(select 1 from posts where author = $1);"
+	AdminDeleteUserVotes       = "delete from votes where nickname = $1;"
+	AdminDeleteUserScores      = "delete from post_votes where nickname = $1;"
+	AdminDeleteUserForums      = "delete from user_forum where nickname = $1;"
+	AdminDeleteUser            = "delete from users where nickname = $1;"
+	AdminMergeLock             = "select count(*) from (select 1 from users where nickname in ($1, $2) order by nickname for update) as locked;"
+	AdminMergePreview          = "select (select count(*) from forums where user_ = $1), (select count(*) from threads where author = $1), (select count(*) from posts where author = $1), " +
+		"(select count(*) from votes v where nickname = $1 and not exists (select 1 from votes where nickname = $2 and thread = v.thread)), " +
+		"(select count(*) from votes v where nickname = $1 and exists (select 1 from votes where nickname = $2 and thread = v.thread)), " +
+		"(select count(*) from post_votes v where nickname = $1 and not exists (select 1 from post_votes where nickname = $2 and post = v.post)), " +
+		"(select count(*) from post_votes v where nickname = $1 and exists (select 1 from post_votes where nickname = $2 and post = v.post)), " +
+		"(select count(*) from user_forum uf where nickname = $1 and not exists (select 1 from user_forum where nickname = $2 and forum = uf.forum));"
+	AdminMergeDropVotes  = "delete from votes v where nickname = $1 and exists (select 1 from votes where nickname = $2 and thread = v.thread);"
+	AdminMergeDropScores = "delete from post_votes v where nickname = $1 and exists (select 1 from post_votes where nickname = $2 and post = v.post);"
+	AdminMergeVotes      = "update votes set nickname = $2 where nickname = $1;"
+	AdminMergeScores     = "update post_votes set nickname = $2 where nickname = $1;"
+	AdminMergeRedirects  = "update user_redirects set target = $2 where target = $1;"
 
-	Vote = "insert into votes (nickname, thread, voice) values ($1, $2, $3) on conflict (nickname, thread) do update set voice = excluded.voice;"
+	TransferUsers      = "select nickname, fullname, about, email from users order by nickname;"
+	TransferForums     = "select title, user_, slug from forums order by slug;"
+	TransferThreads    = "select id, title, author, forum, message, slug, created from threads order by id;"
+	TransferPosts      = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts order by id;"
+	TransferVotes      = "select nickname, thread, voice from votes order by thread, nickname;"
+	TransferPostVotes  = "select v.nickname, p.thread, v.post, v.voice from post_votes v join posts p on p.id = v.post order by v.post, v.nickname;"
+	TransferForumUsers = "select nickname, fullname, about, email from users where nickname in (select user_ from forums where slug = $1 union select author from threads where forum = $1 union select author from posts where forum = $1 " +
+		"union select nickname from votes where thread in (select id from threads where forum = $1) union select nickname from post_votes where post in (select id from posts where forum = $1)) order by nickname;"
+	TransferForum            = "select title, user_, slug from forums where slug = $1;"
+	TransferForumThreads     = "select id, title, author, forum, message, slug, created from threads where forum = $1 order by id;"
+	TransferForumPosts       = "select id, coalesce(parent, 0), author, message, is_edited, forum, thread, created from posts where forum = $1 order by id;"
+	TransferForumVotes       = "select nickname, thread, voice from votes where thread in (select id from threads where forum = $1) order by thread, nickname;"
+	TransferForumPostVotes   = "select v.nickname, p.thread, v.post, v.voice from post_votes v join posts p on p.id = v.post where p.forum = $1 order by v.post, v.nickname;"
+	TransferImportStart      = "insert into imports (name, remap) values ($1, $2) on conflict (name) do update set name = excluded.name returning remap, records, finished is not null;"
+	TransferImportIds        = "select kind, old_id, new_id from import_ids where import = $1;"
+	TransferImportCheckpoint = "update imports set records = $2 where name = $1;"
+	TransferImportFinish     = "update imports set finished = now() where name = $1;"
+	TransferBulk             = "select set_config('forum.bulk_posts', 'on', true), set_config('forum.import', 'on', true);"
+	TransferUserInsert       = "insert into users (nickname, fullname, about, email) values ($1, $2, $3, $4) on conflict do nothing returning nickname;"
+	TransferUserEmailOwner   = "select nickname from users where email = $2 and not exists(select 1 from users where nickname = $1);"
+	TransferForumInsert      = "insert into forums (title, user_, slug) values ($1, $2, $3) on conflict (slug) do nothing;"
+	TransferNextThreadIds    = "select nextval(pg_get_serial_sequence('threads', 'id')) from generate_series(1, $1);"
+	TransferPostPath         = "select path from posts where id = $1;"
+	TransferPostScore        = "update posts set score = score + $2, voters = voters + $3, version = version + 1 where id = $1;"
+	TransferThreadReputation = "select add_reputation(author, forum, $2) from threads where id = $1;"
+	TransferPostReputation   = "select add_reputation(author, forum, $2) from posts where id = $1;"
+	TransferForumUsersInsert = "insert into user_forum (nickname, forum) select nickname, forum from unnest($1::text[], $2::text[]) as u (nickname, forum) on conflict do nothing;"
+	TransferForumStats       = "insert into forum_stats (forum, hour, threads, posts) select $1::citext, hour, count(*) filter (where thread), count(*) filter (where not thread) " +
+		"from (select date_trunc('hour', created, 'UTC') as hour, true as thread from threads where forum = $1 union all select date_trunc('hour', created, 'UTC'), false from posts where forum = $1) as activity " +
+		"group by hour on conflict (forum, hour) do update set threads = excluded.threads, posts = excluded.posts;"
+	TransferForumStatsUsers = "insert into forum_stats_users (forum, hour, nickname) select $1::citext, date_trunc('hour', created, 'UTC'), author from threads where forum = $1 " +
+		"union select $1::citext, date_trunc('hour', created, 'UTC'), author from posts where forum = $1 on conflict do nothing;"
+	TransferThreadsSerial = "select setval(pg_get_serial_sequence('threads', 'id'), coalesce(max(id), 0) + 1, false) from threads;"
+	TransferPostsSerial   = "select setval(pg_get_serial_sequence('posts', 'id'), coalesce(max(id), 0) + 1, false) from posts;"
 )
@@ -7,4 +7,6 @@ var (
 	ThreadRoute  = "/thread"
 	UserRoute    = "/user"
 	ServiceRoute = "/service"
+	OpenAPIRoute = "/openapi.json"
+	DocsRoute    = "/docs"
 )
@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"db_forum/app/models"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Rules are declared on the models with `validate` struct tags,
+// field names in errors are taken from the `json` tags.
+var validate = newValidator()
+
+type Errors struct {
+	Fields []models.FieldError
+}
+
+func (errs *Errors) Error() string {
+	fields := make([]string, 0, len(errs.Fields))
+	for _, fieldErr := range errs.Fields {
+		fields = append(fields, fieldErr.Field)
+	}
+	return fmt.Sprintf("invalid fields: %s", strings.Join(fields, ", "))
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+func Struct(obj interface{}) error {
+	return convert("", validate.Struct(obj))
+}
+
+func Var(field string, value interface{}, rules string) error {
+	return convert(field, validate.Var(value, rules))
+}
+
+// Slice validates every element of a slice of models, prefixing the
+// field names with the element index: "[2].author".
+func Slice(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	errs := new(Errors)
+	for i := 0; i < value.Len(); i++ {
+		err := Struct(value.Index(i).Interface())
+		if elemErrs, ok := err.(*Errors); ok {
+			for _, fieldErr := range elemErrs.Fields {
+				fieldErr.Field = fmt.Sprintf("[%d].%s", i, fieldErr.Field)
+				errs.Fields = append(errs.Fields, fieldErr)
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func convert(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	errs := new(Errors)
+	for _, fieldErr := range validationErrs {
+		name := field
+		if name == "" {
+			name = fieldErr.Field()
+		}
+		errs.Fields = append(errs.Fields, models.FieldError{Field: name, Rule: fieldErr.Tag(), Param: fieldErr.Param()})
+	}
+	return errs
+}
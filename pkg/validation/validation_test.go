@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"db_forum/app/models"
+	"reflect"
+	"testing"
+)
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []models.FieldError
+	}{
+		{"valid user", models.User{Nickname: "alice", Email: "alice@example.com"}, nil},
+		{"user without nickname", models.User{Email: "alice@example.com"},
+			[]models.FieldError{{Field: "nickname", Rule: "required"}}},
+		{"user email without @", models.User{Nickname: "alice", Email: "alice"},
+			[]models.FieldError{{Field: "email", Rule: "contains", Param: "@"}}},
+		{"empty user", models.User{},
+			[]models.FieldError{{Field: "nickname", Rule: "required"}, {Field: "email", Rule: "required"}}},
+		{"update without email", models.UserUpdate{Fullname: "Alice"}, nil},
+		{"update email without @", models.UserUpdate{Email: "alice"},
+			[]models.FieldError{{Field: "email", Rule: "contains", Param: "@"}}},
+		{"forum without slug", models.Forum{Title: "Forum", User: "alice"},
+			[]models.FieldError{{Field: "slug", Rule: "required"}}},
+		{"thread without title and message", models.Thread{Author: "alice"},
+			[]models.FieldError{{Field: "title", Rule: "required"}, {Field: "message", Rule: "required"}}},
+		{"upvote", models.Vote{Nickname: "alice", Voice: 1}, nil},
+		{"downvote", models.Vote{Nickname: "alice", Voice: -1}, nil},
+		{"zero vote", models.Vote{Nickname: "alice", Voice: 0},
+			[]models.FieldError{{Field: "voice", Rule: "oneof", Param: "-1 1"}}},
+		{"webhook", models.Webhook{Url: "https://example.com/hook", Events: []string{"thread.created", "vote.changed"}}, nil},
+		{"webhook with a relative url", models.Webhook{Url: "/hook", Events: []string{"post.created"}},
+			[]models.FieldError{{Field: "url", Rule: "url"}}},
+		{"webhook without events", models.Webhook{Url: "https://example.com/hook", Events: []string{}},
+			[]models.FieldError{{Field: "events", Rule: "min", Param: "1"}}},
+		{"webhook with an unknown event", models.Webhook{Url: "https://example.com/hook", Events: []string{"thread.created", "user.created"}},
+			[]models.FieldError{{Field: "events[1]", Rule: "oneof", Param: "thread.created post.created vote.changed"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Struct(test.obj)
+			if test.want == nil {
+				if err != nil {
+					t.Fatalf("Struct() = %v, want nil", err)
+				}
+				return
+			}
+			errs, ok := err.(*Errors)
+			if !ok {
+				t.Fatalf("Struct() = %v, want *Errors", err)
+			}
+			if !reflect.DeepEqual(errs.Fields, test.want) {
+				t.Errorf("Struct() fields = %+v, want %+v", errs.Fields, test.want)
+			}
+		})
+	}
+}
+
+func TestVar(t *testing.T) {
+	if err := Var("limit", 100, "min=1,max=10000"); err != nil {
+		t.Fatalf("Var() = %v, want nil", err)
+	}
+	err := Var("limit", 0, "min=1,max=10000")
+	errs, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Var() = %v, want *Errors", err)
+	}
+	want := []models.FieldError{{Field: "limit", Rule: "min", Param: "1"}}
+	if !reflect.DeepEqual(errs.Fields, want) {
+		t.Errorf("Var() fields = %+v, want %+v", errs.Fields, want)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	posts := models.Posts{
+		{Author: "alice", Message: "first"},
+		{Author: "bob"},
+		{Message: "third"},
+	}
+	err := Slice(&posts)
+	errs, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Slice() = %v, want *Errors", err)
+	}
+	want := []models.FieldError{{Field: "[1].message", Rule: "required"}, {Field: "[2].author", Rule: "required"}}
+	if !reflect.DeepEqual(errs.Fields, want) {
+		t.Errorf("Slice() fields = %+v, want %+v", errs.Fields, want)
+	}
+	if got := errs.Error(); got != "invalid fields: [1].message, [2].author" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	valid := models.Posts{{Author: "alice", Message: "first"}}
+	if err = Slice(&valid); err != nil {
+		t.Errorf("Slice() = %v, want nil", err)
+	}
+}